@@ -21,11 +21,11 @@ func init() {
 		PreRunE: validateAddress,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if checkNodeInput(node) {
-				balanceRPCReply, err := rpc.Request(rpc.Method.GetBalance, node, []interface{}{addr.address, "latest"})
+				balanceRPCReply, err := nodeHandler().SendRPC(rootCtx, rpc.Method.GetBalance, []interface{}{addr.address, "latest"})
 				if err != nil {
 					return err
 				}
-				nodeRPCReply, err := rpc.Request(rpc.Method.GetShardID, node, []interface{}{})
+				nodeRPCReply, err := nodeHandler().SendRPC(rootCtx, rpc.Method.GetShardID, []interface{}{})
 				if err != nil {
 					return err
 				}
@@ -42,7 +42,7 @@ func init() {
 				fmt.Println(common.JSONPrettyFormat(out.String()))
 				return nil
 			}
-			r, err := sharding.CheckAllShards(node, addr.String(), noPrettyOutput)
+			r, err := sharding.CheckAllShards(rootCtx, node, addr.String(), noPrettyOutput)
 			if err != nil {
 				return err
 			}
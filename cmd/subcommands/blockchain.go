@@ -1,16 +1,27 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/harmony-one/go-sdk/pkg/common"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
 var (
-	addr oneAddress
-	size int64
+	addr        oneAddress
+	size        int64
+	listAll     bool
+	txOrder     string
+	txType      string
+	verifyBlock string
+	trustedNode string
+	allFlagS    = "walk every page, printing one JSON object per line (NDJSON)"
 )
 
 func init() {
@@ -68,12 +79,173 @@ High level information about each transaction for given account
 				Order     string `json:"order"`
 			}
 			noLatest = true
-			params := historyParams{args[0], 0, size, true, "", ""}
+			if listAll {
+				it := client.NewClient(nodeHandler()).IterateTransactionsHistory(client.HistoryArgs{
+					Address: args[0], FullTx: true, TxType: txType, Order: txOrder,
+				}, func(it *client.Iterator) {
+					if cmd.Flags().Changed("max-tx") {
+						it.Limit = int(size)
+					}
+				})
+				return printAll(it.Iterator)
+			}
+			params := historyParams{args[0], 0, size, true, strings.ToUpper(txType), strings.ToUpper(txOrder)}
 			return request(rpc.Method.GetTransactionsHistory, []interface{}{params})
 		},
 	}
 
 	accountHistorySubCmd.Flags().Int64Var(&size, "max-tx", 1000, "max number of transactions to list")
+	accountHistorySubCmd.Flags().BoolVar(&listAll, "all", false, allFlagS)
+	accountHistorySubCmd.Flags().StringVar(&txOrder, "order", "", "<ASC|DESC> oldest or newest transactions first")
+	accountHistorySubCmd.Flags().StringVar(&txType, "tx-type", "", "<ALL|SENT|RECEIVED> side of the transactions to list")
+
+	verifyBalanceSubCmd := &cobra.Command{
+		Use:   "verify-balance <address>",
+		Short: "Check the balance the node gives against a Merkle proof of the state of a block",
+		Long: `
+Fetch the balance of the account along with its eth_getProof Merkle proof, and check the
+proof against the state root of the block. The state root comes from the same node, a
+consistent balance only shows the node answers in line with the block it serves. With
+--trusted-node, the state root of the block is also read from that node and the balance
+is verified when both roots are the same.
+`,
+		Args:    cobra.ExactArgs(1),
+		PreRunE: validateAddress,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parseBlockFlag(verifyBlock)
+			if err != nil {
+				return err
+			}
+			block := client.Latest
+			if number != nil {
+				block = client.BlockArg(*number)
+			}
+			c := client.NewClient(nodeHandler())
+			report := struct {
+				Address     string `json:"address"`
+				Block       uint64 `json:"block"`
+				StateRoot   string `json:"state-root"`
+				Balance     string `json:"balance"`
+				Consistent  bool   `json:"consistent"`
+				TrustedNode string `json:"trusted-node,omitempty"`
+				Verified    *bool  `json:"verified,omitempty"`
+				Reason      string `json:"reason,omitempty"`
+			}{Address: addr.address, TrustedNode: trustedNode}
+			proof, header, err := c.GetVerifiedProof(rootCtx, addr.address, nil, block)
+			if header != nil {
+				report.Block, report.StateRoot = header.Number.ToInt().Uint64(), header.StateRoot.Hex()
+			}
+			if proof != nil {
+				// The balance is asked again as a plain call, the answer wallets rely on
+				balance, balanceErr := c.GetBalance(rootCtx, addr.address, client.BlockArg(report.Block))
+				if balanceErr != nil {
+					return balanceErr
+				}
+				report.Balance = numeric.NewDecFromBigIntWithPrec(balance, 18).String()
+				report.Consistent = balance.Cmp(proof.Balance.ToInt()) == 0
+				if !report.Consistent {
+					err = errors.Errorf("getBalance gives %s, the proof %s", balance, proof.Balance.ToInt())
+				}
+			}
+			if report.Consistent && trustedNode != "" {
+				trusted, trustedErr := client.NewClient(shardHandler(rpc.NewHTTPHandler(trustedNode))).
+					GetBlockByNumber(rootCtx, client.BlockArg(report.Block), false)
+				if trustedErr != nil {
+					return trustedErr
+				}
+				verified := trusted.StateRoot == header.StateRoot
+				report.Verified = &verified
+				if !verified {
+					err = errors.Errorf("the trusted node gives the state root %s", trusted.StateRoot.Hex())
+				}
+			}
+			if err != nil {
+				if header == nil {
+					return err
+				}
+				report.Reason = err.Error()
+			}
+			asJSON, _ := json.Marshal(report)
+			fmt.Println(common.JSONPrettyFormat(string(asJSON)))
+			switch {
+			case !report.Consistent:
+				return errors.New("the balance is not consistent with the state of the block")
+			case report.Verified != nil && !*report.Verified:
+				return errors.New("the balance is not proven")
+			}
+			return nil
+		},
+	}
+	verifyBalanceSubCmd.Flags().StringVar(&verifyBlock, "block", "latest", "<latest|number> block of the state to check")
+	verifyBalanceSubCmd.Flags().StringVar(&trustedNode, "trusted-node", "", "<host> node trusted for the state root of the block")
+
+	verifyHeaderSubCmd := &cobra.Command{
+		Use:   "verify-header",
+		Short: "Check the commit signature of the latest header against the committee of the shard",
+		Long: `
+Check that the committee of the shard signed the parent of the latest header: the keys and
+voting power of the committee are read from a beacon chain node, the signers from the
+bitmap of the header, then the aggregate BLS signature and the quorum are verified.
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shard := client.NewClient(nodeHandler())
+			header, err := shard.GetLatestBlockHeader(rootCtx)
+			if err != nil {
+				return err
+			}
+			beacon := shard
+			if header.ShardID != 0 {
+				routes, err := shard.GetShardingStructure(rootCtx)
+				if err != nil {
+					return err
+				}
+				for _, route := range routes {
+					if route.ShardID == 0 {
+						beacon = client.NewClient(shardHandler(rpc.NewHTTPHandler(route.HTTP)))
+					}
+				}
+				if beacon == shard {
+					return errors.New("no beacon chain endpoint in the sharding structure")
+				}
+			}
+			report := struct {
+				ShardID     uint32 `json:"shard-id"`
+				Block       uint64 `json:"block"`
+				Hash        string `json:"hash,omitempty"`
+				Epoch       uint64 `json:"epoch,omitempty"`
+				ViewID      uint64 `json:"view-id,omitempty"`
+				Policy      string `json:"policy,omitempty"`
+				Committee   int    `json:"committee-size,omitempty"`
+				Signers     int    `json:"signers"`
+				VotingPower string `json:"signers-voting-power,omitempty"`
+				Verified    bool   `json:"verified"`
+				Reason      string `json:"reason,omitempty"`
+			}{ShardID: header.ShardID, Block: header.BlockNumber - 1}
+			verification, err := client.NewHeaderVerifier(beacon, shard).VerifyHeader(rootCtx, header)
+			if verification != nil {
+				report.Hash, report.Epoch, report.ViewID = verification.BlockHash.Hex(), verification.Epoch, verification.ViewID
+				report.Policy, report.Committee = verification.Policy, verification.Committee
+				report.Signers = verification.Signers
+				if !verification.Power.IsNil() {
+					report.VotingPower = verification.Power.String()
+				}
+			}
+			if err != nil {
+				if verification == nil {
+					return err
+				}
+				report.Reason = err.Error()
+			}
+			report.Verified = err == nil
+			asJSON, _ := json.Marshal(report)
+			fmt.Println(common.JSONPrettyFormat(string(asJSON)))
+			if !report.Verified {
+				return errors.New("the block is not proven final")
+			}
+			return nil
+		},
+	}
 
 	subCommands := []*cobra.Command{{
 		Use:   "block-by-number",
@@ -187,10 +359,21 @@ High level information about transaction, like blockNumber, blockHash
 		},
 	},
 		accountHistorySubCmd,
+		newLogsCmd(),
+		verifyBalanceSubCmd,
+		verifyHeaderSubCmd,
 	}
 
 	cmdBlockchain.AddCommand(cmdValidator)
 	cmdBlockchain.AddCommand(cmdDelegation)
+	listings := map[string]bool{
+		"all-information": true, "all-information-by-block-number": true, "by-delegator": true, "by-validator": true,
+	}
+	for _, subCmd := range append(append([]*cobra.Command{}, validatorSubCmds...), delegationSubCmds...) {
+		if listings[subCmd.Name()] {
+			subCmd.Flags().BoolVar(&listAll, "all", false, allFlagS)
+		}
+	}
 	cmdValidator.AddCommand(validatorSubCmds[:]...)
 	cmdDelegation.AddCommand(delegationSubCmds[:]...)
 	cmdBlockchain.AddCommand(subCommands[:]...)
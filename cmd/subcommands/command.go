@@ -54,7 +54,7 @@ func openConsole(net string) error {
 	}
 
 	// get shard id
-	nodeRPCReply, err := rpc.Request(rpc.Method.GetShardID, node, []interface{}{})
+	nodeRPCReply, err := nodeHandler().SendRPC(rootCtx, rpc.Method.GetShardID, []interface{}{})
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +14,9 @@ var (
 		PreRunE: validateAddress,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			noLatest = true
+			if listAll {
+				return printAll(client.NewClient(nodeHandler()).IterateDelegationsByDelegator(addr.address).Iterator)
+			}
 			return request(rpc.Method.GetDelegationsByDelegator, []interface{}{addr.address})
 		},
 	}, {
@@ -22,6 +26,9 @@ var (
 		PreRunE: validateAddress,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			noLatest = true
+			if listAll {
+				return printAll(client.NewClient(nodeHandler()).IterateDelegationsByValidator(addr.address).Iterator)
+			}
 			return request(rpc.Method.GetDelegationsByValidator, []interface{}{addr.address})
 		},
 	}}
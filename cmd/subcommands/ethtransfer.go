@@ -6,8 +6,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/harmony-one/go-sdk/pkg/address"
@@ -17,7 +15,6 @@ import (
 	"github.com/harmony-one/go-sdk/pkg/store"
 	"github.com/harmony-one/go-sdk/pkg/transaction"
 	"github.com/harmony-one/harmony/accounts"
-	"github.com/harmony-one/harmony/core"
 
 	"github.com/spf13/cobra"
 )
@@ -35,8 +32,8 @@ type ethTransferFlags struct {
 	TrueNonce        bool    `json:"true-nonce"`
 }
 
-func ethHandlerForShard(node string) (*rpc.HTTPMessenger, error) {
-	return rpc.NewHTTPHandler(node), nil
+func ethHandlerForShard(node string) (rpc.T, error) {
+	return nodeHandler(), nil
 }
 
 // handlerForTransaction executes a single transaction and fills out the transaction logger accordingly.
@@ -44,7 +41,7 @@ func ethHandlerForShard(node string) (*rpc.HTTPMessenger, error) {
 // Note that the vars need to be set before calling this handler.
 func ethHandlerForTransaction(txLog *transactionLog) error {
 	from := fromAddress.String()
-	var networkHandler *rpc.HTTPMessenger
+	var networkHandler rpc.T
 	if !offlineSign {
 		var err error
 		networkHandler, err = ethHandlerForShard(node)
@@ -54,9 +51,10 @@ func ethHandlerForTransaction(txLog *transactionLog) error {
 	}
 
 	var ctrlr *transaction.EthController
-	if useLedgerWallet {
+	if useLedgerWallet || remoteSigner != "" {
 		account := accounts.Account{Address: address.Parse(from)}
-		ctrlr = transaction.NewEthController(networkHandler, nil, &account, *chainName.chainID, ethOpts)
+		remote := func(c *transaction.EthController) { c.Behavior.Signer = remoteSignerFor(from) }
+		ctrlr = transaction.NewEthController(networkHandler, nil, &account, *chainName.chainID, ethOpts, remote)
 	} else {
 		ks, acct, err := store.UnlockedKeystore(from, passphrase)
 		if handlerForError(txLog, err) != nil {
@@ -65,11 +63,6 @@ func ethHandlerForTransaction(txLog *transactionLog) error {
 		ctrlr = transaction.NewEthController(networkHandler, ks, acct, *chainName.chainID, ethOpts)
 	}
 
-	nonce, err := getNonce(fromAddress.String(), networkHandler)
-	if err != nil {
-		return err
-	}
-
 	amt, err := common.NewDecFromString(amount)
 	if err != nil {
 		amtErr := fmt.Errorf("amount %w", err)
@@ -77,30 +70,10 @@ func ethHandlerForTransaction(txLog *transactionLog) error {
 		return amtErr
 	}
 
-	gPrice, err := common.NewDecFromString(gasPrice)
+	// Left at zero, the gas limit and price are estimated by the controller
+	gLimit, gPrice, err := gasFlags()
 	if err != nil {
-		gasErr := fmt.Errorf("gas-price %w", err)
-		handlerForError(txLog, gasErr)
-		return gasErr
-	}
-
-	var gLimit uint64
-	if gasLimit == "" {
-		gLimit, err = core.IntrinsicGas([]byte(""), false, true, true, false)
-		if handlerForError(txLog, err) != nil {
-			return err
-		}
-	} else {
-		if strings.HasPrefix(gasLimit, "-") {
-			limitErr := fmt.Errorf("gas-limit can not be negative: %s", gasLimit)
-			handlerForError(txLog, limitErr)
-			return limitErr
-		}
-		tempLimit, e := strconv.ParseInt(gasLimit, 10, 64)
-		if handlerForError(txLog, e) != nil {
-			return e
-		}
-		gLimit = uint64(tempLimit)
+		return handlerForError(txLog, err)
 	}
 
 	dataByte, err := transaction.StringToByte(data)
@@ -108,18 +81,28 @@ func ethHandlerForTransaction(txLog *transactionLog) error {
 		return handlerForError(txLog, err)
 	}
 
+	nonce, reserved, err := getNonce(rootCtx, fromAddress.String(), networkHandler)
+	if handlerForError(txLog, err) != nil {
+		return err
+	}
+
 	txLog.TimeSigned = time.Now().UTC().Format(timeFormat) // Approximate time of signature
 	err = ctrlr.ExecuteEthTransaction(
+		rootCtx,
 		nonce, gLimit,
 		toAddress.String(),
 		amt, gPrice,
 		dataByte,
 	)
+	settleNonce(rootCtx, from, nonce, reserved, networkHandler, !dryRun && ctrlr.TransactionHash() != nil)
 
 	if dryRun {
-		txLog.RawTxn = ctrlr.RawTransaction()
-		txLog.Transaction = make(map[string]interface{})
-		_ = json.Unmarshal([]byte(ctrlr.EthTransactionToJSON(false)), &txLog.Transaction)
+		// A failed build leaves no transaction or signature to show
+		if err == nil {
+			txLog.RawTxn = ctrlr.RawTransaction()
+			txLog.Transaction = make(map[string]interface{})
+			_ = json.Unmarshal([]byte(ctrlr.EthTransactionToJSON(false)), &txLog.Transaction)
+		}
 	} else if txHash := ctrlr.TransactionHash(); txHash != nil {
 		txLog.TxHash = *txHash
 	}
@@ -181,7 +164,7 @@ func ethHandlerForBulkTransactions(txLog *transactionLog, index int) error {
 	if txnFlags.GasPrice != nil {
 		gasPrice = *txnFlags.GasPrice
 	} else {
-		gasPrice = "100" // Reset to default for subsequent transactions
+		gasPrice = "" // Reset to default for subsequent transactions
 	}
 	if txnFlags.GasLimit != nil {
 		gasLimit = *txnFlags.GasLimit
@@ -206,6 +189,8 @@ func ethOpts(ctlr *transaction.EthController) {
 	if timeout > 0 {
 		ctlr.Behavior.ConfirmationWaitTime = timeout
 	}
+	ctlr.Behavior.GasEstimator = gasEstimator()
+	ctlr.Behavior.Simulation = transaction.SimulationPolicy(simulationPolicy)
 }
 
 func init() {
@@ -288,7 +273,7 @@ Create an Ethereum compatible transaction, sign it, and send off to the Harmony
 	cmdEthTransfer.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing")
 	cmdEthTransfer.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
 	cmdEthTransfer.Flags().StringVar(&amount, "amount", "0", "amount to send (ONE)")
-	cmdEthTransfer.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay (NANO)")
+	cmdEthTransfer.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	cmdEthTransfer.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	cmdEthTransfer.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for tx")
 	cmdEthTransfer.Flags().StringVar(&data, "data", "", "transaction data")
@@ -315,7 +300,7 @@ Send a offline signed transaction to the Harmony blockchain (on the same shard)
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var txLogs []*transactionLog
 
-			networkHandler := rpc.NewHTTPHandler(node)
+			networkHandler := nodeHandler()
 
 			openFile, err := os.Open(givenFilePath)
 			if err != nil {
@@ -334,7 +319,7 @@ Send a offline signed transaction to the Harmony blockchain (on the same shard)
 				}
 
 				ctrlr := transaction.NewEthController(networkHandler, nil, nil, *chainName.chainID, ethOpts)
-				err := ctrlr.ExecuteRawTransaction(txLog.RawTxn)
+				err := ctrlr.ExecuteRawTransaction(rootCtx, txLog.RawTxn)
 				if handlerForError(txLog, err) != nil {
 					txLog.Errors = append(txLog.Errors, err.Error())
 					continue
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFromBlock    string
+	logsToBlock      string
+	logsAddresses    []string
+	logsTopics       []string
+	logsFollow       bool
+	logsChunkSize    uint64
+	logsPollInterval time.Duration
+)
+
+// logLine is a log as printed by the logs command, with decimal numbers and a one1 address
+type logLine struct {
+	Address          string           `json:"address"`
+	Topics           []ethCommon.Hash `json:"topics"`
+	Data             hexutil.Bytes    `json:"data"`
+	BlockNumber      uint64           `json:"blockNumber"`
+	BlockHash        ethCommon.Hash   `json:"blockHash"`
+	TransactionHash  ethCommon.Hash   `json:"transactionHash"`
+	TransactionIndex uint             `json:"transactionIndex"`
+	LogIndex         uint             `json:"logIndex"`
+	Removed          bool             `json:"removed"`
+}
+
+func printLog(log client.Log) error {
+	line, err := json.Marshal(logLine{
+		Address:          address.ToBech32(log.Address),
+		Topics:           log.Topics,
+		Data:             log.Data,
+		BlockNumber:      uint64(log.BlockNumber),
+		BlockHash:        log.BlockHash,
+		TransactionHash:  log.TransactionHash,
+		TransactionIndex: uint(log.TransactionIndex),
+		LogIndex:         uint(log.LogIndex),
+		Removed:          log.Removed,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(line))
+	return nil
+}
+
+// parseBlockFlag reads a block given as latest, earliest, a decimal or a 0x hex number,
+// nil standing for latest
+func parseBlockFlag(value string) (*uint64, error) {
+	switch value {
+	case "latest", "":
+		return nil, nil
+	case "earliest":
+		number := uint64(0)
+		return &number, nil
+	}
+	number, err := strconv.ParseUint(value, 0, 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid block %s, expected latest, earliest or a number", value)
+	}
+	return &number, nil
+}
+
+// logQuery builds the query of the flags, each --topic giving the comma separated
+// alternatives of its position, * accepting any value
+func logQuery(c *client.Client) (client.LogQuery, error) {
+	query := client.LogQuery{}
+	from, err := parseBlockFlag(logsFromBlock)
+	if err != nil {
+		return query, err
+	}
+	if from == nil {
+		head, err := c.BlockNumber(rootCtx)
+		if err != nil {
+			return query, err
+		}
+		from = &head
+	}
+	query.FromBlock = *from
+	if query.ToBlock, err = parseBlockFlag(logsToBlock); err != nil {
+		return query, err
+	}
+	for _, addr := range logsAddresses {
+		if strings.HasPrefix(addr, "one1") {
+			contract, err := address.Bech32ToAddress(addr)
+			if err != nil {
+				return query, err
+			}
+			query.Addresses = append(query.Addresses, contract)
+		} else if ethCommon.IsHexAddress(addr) {
+			query.Addresses = append(query.Addresses, ethCommon.HexToAddress(addr))
+		} else {
+			return query, errors.Errorf("invalid address %s", addr)
+		}
+	}
+	for _, position := range logsTopics {
+		alternatives := []ethCommon.Hash{}
+		for _, topic := range strings.Split(position, ",") {
+			if topic == "*" || topic == "" {
+				alternatives = nil
+				break
+			}
+			hash, err := hexutil.Decode(topic)
+			if err != nil || len(hash) != ethCommon.HashLength {
+				return query, errors.Errorf("invalid topic %s, expected a 32 bytes 0x hex string", topic)
+			}
+			alternatives = append(alternatives, ethCommon.BytesToHash(hash))
+		}
+		query.Topics = append(query.Topics, alternatives)
+	}
+	return query, nil
+}
+
+func newLogsCmd() *cobra.Command {
+	cmdLogs := &cobra.Command{
+		Use:   "logs",
+		Short: "Print the logs emitted by contracts, one JSON object per line",
+		Long: `
+Print the logs matching the contract addresses and topics, over a range of blocks read in
+chunks, or as they are emitted with --follow. Each --topic gives the accepted values of a
+topic position, comma separated, * accepting any value.
+
+  hmy blockchain logs --from-block 1000 --to-block 5000 --address one1... \
+    --topic 0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef
+`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := client.NewClient(nodeHandler())
+			query, err := logQuery(c)
+			if err != nil {
+				return err
+			}
+			manager := client.NewFilterManager(c, func(m *client.FilterManager) {
+				m.ChunkSize = logsChunkSize
+				m.PollInterval = logsPollInterval
+			})
+			if logsFollow {
+				return manager.Watch(rootCtx, query, printLog)
+			}
+			return manager.GetLogs(rootCtx, query, printLog)
+		},
+	}
+	cmdLogs.Flags().StringVar(&logsFromBlock, "from-block", "latest", "<latest|earliest|number> first block of the range")
+	cmdLogs.Flags().StringVar(&logsToBlock, "to-block", "latest", "<latest|earliest|number> last block of the range")
+	cmdLogs.Flags().StringArrayVar(&logsAddresses, "address", []string{}, "contract emitting the logs, may be repeated")
+	cmdLogs.Flags().StringArrayVar(&logsTopics, "topic", []string{}, "accepted values of the next topic position, may be repeated")
+	cmdLogs.Flags().BoolVar(&logsFollow, "follow", false, "keep printing the logs as they are emitted")
+	cmdLogs.Flags().Uint64Var(&logsChunkSize, "chunk-size", 1024, "number of blocks asked for at a time")
+	cmdLogs.Flags().DurationVar(&logsPollInterval, "poll-interval", 2*time.Second, "delay between two polls with --follow")
+	return cmdLogs
+}
@@ -2,22 +2,28 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/harmony-one/go-sdk/pkg/address"
 	"net/http"
 	"os"
+	"os/signal"
 	"path"
 	"regexp"
 	"strings"
+	"syscall"
 
 	color "github.com/fatih/color"
 	"github.com/harmony-one/go-sdk/pkg/common"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
 	rpcEth "github.com/harmony-one/go-sdk/pkg/rpc/eth"
 	rpcV1 "github.com/harmony-one/go-sdk/pkg/rpc/v1"
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
 	"github.com/harmony-one/go-sdk/pkg/sharding"
 	"github.com/harmony-one/go-sdk/pkg/store"
+	"github.com/harmony-one/go-sdk/pkg/transaction"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
@@ -26,9 +32,12 @@ import (
 var (
 	verbose         bool
 	useLedgerWallet bool
+	remoteSigner    string
 	noLatest        bool
 	noPrettyOutput  bool
 	node            string
+	nodes           []string
+	nodeStrategy    string
 	rpcPrefix       string
 	keyStoreDir     string
 	givenFilePath   string
@@ -37,7 +46,12 @@ var (
 		if !noLatest {
 			params = append(params, "latest")
 		}
-		success, failure := rpc.Request(method, node, params)
+		return printRPC(method, params)
+	}
+	// printRPC sends params as given and prints the reply as the node gave it, the
+	// decimal numbers of hmyv2 included
+	printRPC = func(method string, params []interface{}) error {
+		success, failure := nodeMessenger().SendRPC(rootCtx, method, params)
 		if failure != nil {
 			return failure
 		}
@@ -49,6 +63,9 @@ var (
 		fmt.Println(common.JSONPrettyFormat(string(asJSON)))
 		return nil
 	}
+	// failoverNodes fails over between the repeated --node, built once for the invocation
+	// so that its health and latency checks are shared by every call
+	failoverNodes *rpc.FailoverMessenger
 	// RootCmd is single entry point of the CLI
 	RootCmd = &cobra.Command{
 		Use:          "hmy",
@@ -61,30 +78,34 @@ var (
 			switch rpcPrefix {
 			case "hmy":
 				rpc.Method = rpcV1.Method
+			case "hmyv2":
+				rpc.Method = rpcV2.Method
 			case "eth":
 				rpc.Method = rpcEth.Method
 			default:
 				rpc.Method = rpcV1.Method
 			}
-			if strings.HasPrefix(node, "https://") || strings.HasPrefix(node, "http://") ||
-				strings.HasPrefix(node, "ws://") {
-				//No op, already has protocol, respect protocol default ports.
-			} else if strings.HasPrefix(node, "api") || strings.HasPrefix(node, "ws") {
-				node = "https://" + node
-			} else {
-				switch URLcomponents := strings.Split(node, ":"); len(URLcomponents) {
-				case 1:
-					node = "http://" + node + ":9500"
-				case 2:
-					node = "http://" + node
-				default:
-					node = node
-				}
+			for i := range nodes {
+				nodes[i] = normalizeNode(nodes[i])
+			}
+			if len(nodes) == 0 {
+				nodes = []string{defaultNodeAddr}
+			}
+			node = nodes[0]
+			strategy, err := rpc.StringToStrategy(nodeStrategy)
+			if err != nil {
+				return err
+			}
+			failoverNodes = nil
+			if len(nodes) > 1 {
+				failoverNodes = rpc.NewFailoverHandler(nodes, func(M *rpc.FailoverMessenger) {
+					M.Strategy = strategy
+				})
 			}
 
 			if targetChain == "" {
 				if node == defaultNodeAddr {
-					routes, err := sharding.Structure(node)
+					routes, err := sharding.Structure(rootCtx, node)
 					if err != nil {
 						chainName = chainIDWrapper{chainID: &common.Chain.TestNet}
 					} else {
@@ -121,11 +142,73 @@ CLI interface to the Harmony blockchain
 	}
 )
 
+// normalizeNode adds the protocol and default port missing from a --node value
+func normalizeNode(node string) string {
+	if strings.HasPrefix(node, "https://") || strings.HasPrefix(node, "http://") ||
+		strings.HasPrefix(node, "ws://") {
+		//No op, already has protocol, respect protocol default ports.
+		return node
+	} else if strings.HasPrefix(node, "api") || strings.HasPrefix(node, "ws") {
+		return "https://" + node
+	}
+	switch URLcomponents := strings.Split(node, ":"); len(URLcomponents) {
+	case 1:
+		return "http://" + node + ":9500"
+	case 2:
+		return "http://" + node
+	default:
+		return node
+	}
+}
+
+// nodeHandler is the messenger for the --node endpoints, answering the hmyv2 calls in the
+// hmy shape the commands decode
+func nodeHandler() rpc.T {
+	return shardHandler(nodeMessenger())
+}
+
+// shardHandler normalizes the replies of messenger when --rpc-prefix is hmyv2
+func shardHandler(messenger rpc.T) rpc.T {
+	if rpcPrefix == "hmyv2" {
+		return rpc.NewV2Messenger(messenger)
+	}
+	return messenger
+}
+
+// nodeMessenger is the messenger for the --node endpoints, failing over between them when repeated
+func nodeMessenger() rpc.T {
+	if failoverNodes == nil {
+		return rpc.NewHTTPHandler(node)
+	}
+	return failoverNodes
+}
+
+// printAll streams every item of the listing as a line of JSON (NDJSON), so that a long
+// walk can be piped before it ends
+func printAll(it *client.Iterator) error {
+	for it.Next(rootCtx) {
+		compact := bytes.Buffer{}
+		if err := json.Compact(&compact, it.Raw()); err != nil {
+			return err
+		}
+		fmt.Println(compact.String())
+	}
+	return it.Err()
+}
+
+// rootCtx bounds every RPC call made by a command, Execute cancels it on SIGINT/SIGTERM
+var rootCtx = context.Background()
+
 func init() {
 	vS := "dump out debug information, same as env var HMY_ALL_DEBUG=true"
 	RootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, vS)
-	RootCmd.PersistentFlags().StringVarP(&node, "node", "n", defaultNodeAddr, "<host>")
-	RootCmd.PersistentFlags().StringVarP(&rpcPrefix, "rpc-prefix", "r", defaultRpcPrefix, "<rpc>")
+	RootCmd.PersistentFlags().StringArrayVarP(
+		&nodes, "node", "n", []string{defaultNodeAddr}, "<host>, repeat to fail over between several endpoints of a shard",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&nodeStrategy, "node-strategy", "round-robin", "<round-robin|latency> how calls are spread over repeated --node",
+	)
+	RootCmd.PersistentFlags().StringVarP(&rpcPrefix, "rpc-prefix", "r", defaultRpcPrefix, "<hmy|hmyv2|eth> namespace of the RPC methods")
 	RootCmd.PersistentFlags().BoolVar(
 		&noLatest, "no-latest", false, "Do not add 'latest' to RPC params",
 	)
@@ -158,7 +241,27 @@ func init() {
 		},
 	})
 	RootCmd.PersistentFlags().BoolVarP(&useLedgerWallet, "ledger", "e", false, "Use ledger hardware wallet")
+	RootCmd.PersistentFlags().StringVar(
+		&remoteSigner, "remote-signer", "", "JSON-RPC endpoint of a signing service holding the key of the sender",
+	)
 	RootCmd.PersistentFlags().StringVar(&givenFilePath, "file", "", "Path to file for given command when applicable")
+	RootCmd.PersistentFlags().Float64Var(
+		&gasLimitMultiplier, "gas-limit-multiplier", 1.2, "safety margin applied to the estimated gas limit",
+	)
+	RootCmd.PersistentFlags().Float64Var(
+		&gasPriceMultiplier, "gas-price-multiplier", 1, "factor applied to the estimated gas price",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&gasPricePolicy, "gas-price-policy", string(transaction.NodeGasPrice),
+		"<node|median> estimate the gas price from the node, or the median of the recent blocks",
+	)
+	RootCmd.PersistentFlags().IntVar(
+		&gasPriceBlocks, "gas-price-blocks", 20, "number of blocks the median gas price policy looks at",
+	)
+	RootCmd.PersistentFlags().StringVar(
+		&simulationPolicy, "simulate", string(transaction.SimulateWithData),
+		"<data|always|never> run the transactions carrying data, all or none through a call before sending them",
+	)
 	RootCmd.AddCommand(&cobra.Command{
 		Use:   "docs",
 		Short: fmt.Sprintf("Generate docs to a local %s directory", hmyDocsDir),
@@ -183,6 +286,14 @@ var (
 // Execute kicks off the hmy CLI
 func Execute() {
 	RootCmd.SilenceErrors = true
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// Restore the default behavior once interrupted so that a second signal terminates
+		<-ctx.Done()
+		stop()
+	}()
+	rootCtx = ctx
 	if err := RootCmd.Execute(); err != nil {
 		resp, httpErr := http.Get(versionLink)
 		if httpErr != nil {
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/spf13/cobra"
+)
+
+// parseRPCArg reads a command line argument as JSON, falling back to a plain string
+// so that addresses and hashes need no quoting
+func parseRPCArg(arg string) interface{} {
+	var value interface{}
+	if err := json.Unmarshal([]byte(arg), &value); err != nil {
+		return arg
+	}
+	return value
+}
+
+func init() {
+	cmdRPC := &cobra.Command{
+		Use:   "rpc",
+		Short: "call any RPC method of the node",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			return nil
+		},
+	}
+
+	cmdRPC.AddCommand([]*cobra.Command{{
+		Use:   "call <method> [json params...]",
+		Short: "call a method with the given params, checked against the known methods",
+		Long: `
+Call a method, named as in 'hmy rpc methods' (GetBalance), without its prefix (getBalance)
+or in full (hmy_getBalance). The prefix is the one of --rpc-prefix unless given. Each param
+is read as JSON, or as a string when it is not valid JSON.
+
+  hmy rpc call getBalance one1pdv9lrdwl0rg5vglh4xtyrv3wjk3wsqket7zxy latest
+  hmy rpc call GetStorageAt 0x0B585F8DaEfBC68a311FbD4cB20d9174aD174016 0x0 '"0x10"'
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			field, method, err := rpc.LookupMethod(args[0])
+			if err != nil {
+				return err
+			}
+			params := []interface{}{}
+			for _, arg := range args[1:] {
+				params = append(params, parseRPCArg(arg))
+			}
+			if err := rpc.ValidateParams(field, params); err != nil {
+				return err
+			}
+			return printRPC(method, params)
+		},
+	}, {
+		Use:   "methods",
+		Short: "list the known methods and their params",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, field := range rpc.MethodNames() {
+				_, method, _ := rpc.LookupMethod(field)
+				fmt.Printf("%-40s %-45s %s\n", field, method, rpc.Usage(field))
+			}
+			return nil
+		},
+	}}...)
+
+	RootCmd.AddCommand(cmdRPC)
+}
@@ -5,22 +5,16 @@ import (
 	"math/big"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/rlp"
 	bls_core "github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/go-sdk/pkg/address"
 	"github.com/harmony-one/go-sdk/pkg/common"
 	"github.com/harmony-one/go-sdk/pkg/keys"
-	"github.com/harmony-one/go-sdk/pkg/ledger"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
 	"github.com/harmony-one/go-sdk/pkg/store"
 	"github.com/harmony-one/go-sdk/pkg/transaction"
 	"github.com/harmony-one/harmony/accounts"
-	"github.com/harmony-one/harmony/accounts/keystore"
 	"github.com/harmony-one/harmony/common/denominations"
-	"github.com/harmony-one/harmony/core"
 	"github.com/harmony-one/harmony/crypto/bls"
 	"github.com/harmony-one/harmony/numeric"
 	"github.com/harmony-one/harmony/shard"
@@ -85,118 +79,101 @@ var (
 	errNegativeAmount                  = errors.New("amount can not be negative")
 )
 
-func createStakingTransaction(nonce uint64, f staking.StakeMsgFulfiller) (*staking.StakingTransaction, error) {
-	gPrice, err := common.NewDecFromString(gasPrice)
-	if err != nil {
-		return nil, err
+func stakingOpts(ctlr *transaction.StakingController) {
+	if dryRun {
+		ctlr.Behavior.DryRun = true
 	}
-	gPrice = gPrice.Mul(nanoAsDec)
-	directive, payload := f()
-	data, err := rlp.EncodeToBytes(payload)
-	if err != nil {
-		return nil, err
+	if offlineSign {
+		ctlr.Behavior.OfflineSign = true
 	}
-	var gLimit uint64
-	if gasLimit == "" {
-		isCreateValidator := directive == staking.DirectiveCreateValidator
-		gLimit, err = core.IntrinsicGas(data, false, true, true, isCreateValidator)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		tempLimit, e := strconv.ParseInt(gasLimit, 10, 64)
-		if e != nil {
-			return nil, e
-		}
-		gLimit = uint64(tempLimit)
+	if useLedgerWallet {
+		ctlr.Behavior.SigningImpl = transaction.Ledger
+	}
+	if timeout > 0 {
+		ctlr.Behavior.ConfirmationWaitTime = timeout
 	}
+	ctlr.Behavior.GasEstimator = gasEstimator()
+}
 
-	stakingTx, err := staking.NewStakingTransaction(nonce, gLimit, gPrice.TruncateInt(), f)
-	return stakingTx, err
+// stakingHandler is the messenger of the beacon shard the staking transactions go to,
+// none with --offline-sign, which signs without a node
+func stakingHandler() (rpc.T, error) {
+	if offlineSign {
+		return nil, nil
+	}
+	return handlerForShard(rootCtx, shard.BeaconChainShardID, node)
 }
 
+// handleStakingTransaction signs the staking transaction f makes with the key of
+// signerAddress and sends it, unless --dry-run or --offline-sign is set, or builds it
+// unsigned with --unsigned
 func handleStakingTransaction(
-	stakingTx *staking.StakingTransaction, networkHandler *rpc.HTTPMessenger, signerAddress oneAddress,
+	f staking.StakeMsgFulfiller, networkHandler rpc.T, signerAddress oneAddress,
 ) error {
-	var (
-		ks     *keystore.KeyStore
-		acct   *accounts.Account
-		signed *staking.StakingTransaction
-		err    error
-	)
-
 	from := signerAddress.String()
+	if offlineSign {
+		if trueNonce {
+			return errors.New("cannot use the on-chain nonce when offline sign")
+		}
+		dryRun = true
+	}
 
-	if useLedgerWallet {
-		signerAddr := ""
-		signed, signerAddr, err = ledger.SignStakingTx(stakingTx, chainName.chainID.Value)
+	gLimit, gPrice, err := gasFlags()
+	if err != nil {
+		return err
+	}
+
+	if unsignedEnvelope {
+		return buildStakingEnvelope(f, networkHandler, from, gLimit, gPrice)
+	}
+
+	var ctrlr *transaction.StakingController
+	if useLedgerWallet || remoteSigner != "" {
+		account := accounts.Account{Address: address.Parse(from)}
+		remote := func(c *transaction.StakingController) { c.Behavior.Signer = remoteSignerFor(from) }
+		ctrlr = transaction.NewStakingController(networkHandler, nil, &account, *chainName.chainID, stakingOpts, remote)
+	} else {
+		pp, err := getPassphrase()
 		if err != nil {
 			return err
 		}
-
-		if strings.Compare(signerAddr, from) != 0 {
-			return errors.New("error : delegator address doesn't match with ledger hardware addresss")
-		}
-	} else {
-		ks, acct, err = store.UnlockedKeystore(from, passphrase)
+		passphrase = pp
+		ks, acct, err := store.UnlockedKeystore(from, passphrase)
 		if err != nil {
 			return err
 		}
-		signed, err = ks.SignStakingTx(*acct, stakingTx, chainName.chainID.Value)
+		ctrlr = transaction.NewStakingController(networkHandler, ks, acct, *chainName.chainID, stakingOpts)
 	}
 
+	nonce, reserved, err := getNonce(rootCtx, from, networkHandler)
 	if err != nil {
 		return err
 	}
+	err = ctrlr.ExecuteStakingTransaction(rootCtx, nonce, gLimit, gPrice, f)
+	settleNonce(rootCtx, from, nonce, reserved, networkHandler, !dryRun && ctrlr.TransactionHash() != nil)
 
-	enc, err := rlp.EncodeToBytes(signed)
 	if err != nil {
-		return err
-	}
-
-	hexSignature := hexutil.Encode(enc)
-	reply, err := networkHandler.SendRPC(rpc.Method.SendRawStakingTransaction, []interface{}{hexSignature})
-	if err != nil {
-		return err
-	}
-	r, _ := reply["result"].(string)
-	if timeout > 0 {
-		if err := confirmTx(networkHandler, timeout, r); err != nil {
-			fmt.Println(fmt.Sprintf(`{"transaction-hash":"%s"}`, r))
-			return err
+		if txHash := ctrlr.TransactionHash(); txHash != nil {
+			fmt.Println(fmt.Sprintf(`{"transaction-hash":"%s"}`, *txHash))
 		}
-	} else {
-		fmt.Println(fmt.Sprintf(`{"transaction-receipt":"%s"}`, r))
-	}
-	return nil
-}
-
-func confirmTx(networkHandler *rpc.HTTPMessenger, confirmWaitTime uint32, txHash string) error {
-	start := int(confirmWaitTime)
-	for {
-		r, _ := networkHandler.SendRPC(rpc.Method.GetTransactionReceipt, []interface{}{txHash})
-		if r["result"] != nil {
-			fmt.Println(common.ToJSONUnsafe(r, true))
-			return nil
+		for _, txError := range ctrlr.TransactionErrors() {
+			fmt.Println(txError.Error().Error())
 		}
-		if start < 0 {
-			transactionErrors, _ := transaction.GetError(txHash, networkHandler)
-			for _, txError := range transactionErrors {
-				fmt.Println(txError.Error().Error())
-			}
+		if timeout > 0 && ctrlr.TransactionHash() != nil && len(ctrlr.TransactionErrors()) == 0 {
 			fmt.Println("Try increasing the `timeout` or look for the transaction receipt with `hmy blockchain transaction-receipt <txHash>`")
-			return fmt.Errorf("could not confirm %s even after %d seconds", txHash, confirmWaitTime)
-		}
-		transactionErrors, _ := transaction.GetError(txHash, networkHandler)
-		if len(transactionErrors) > 0 {
-			for _, txError := range transactionErrors {
-				fmt.Println(txError.Error().Error())
-			}
-			return fmt.Errorf("staking transaction error")
 		}
-		time.Sleep(time.Second * 2)
-		start = start - 2
+		return err
+	}
+	switch {
+	case dryRun:
+		fmt.Println(ctrlr.TransactionToJSON(!noPrettyOutput))
+		fmt.Println(fmt.Sprintf(`{"raw-transaction":"%s"}`, ctrlr.RawTransaction()))
+	case timeout > 0:
+		fmt.Println(common.ToJSONUnsafe(ctrlr.Receipt(), true))
+	default:
+		fmt.Println(fmt.Sprintf(`{"transaction-receipt":"%s"}`, *ctrlr.TransactionHash()))
 	}
+	return nil
 }
 
 func delegationAmountSanityCheck(minSelfDelegation *numeric.Dec, maxTotalDelegation *numeric.Dec, amount *numeric.Dec) error {
@@ -320,7 +297,7 @@ Create a new validator"
 `,
 		PreRunE: validateBlsKeyInput,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(0, node)
+			networkHandler, err := stakingHandler()
 			if err != nil {
 				return err
 			}
@@ -411,29 +388,14 @@ Create a new validator"
 				}
 			}
 
-			nonce, err := getNonce(validatorAddress.String(), networkHandler)
-			if err != nil {
-				return err
-			}
-			stakingTx, err := createStakingTransaction(nonce, delegateStakePayloadMaker)
-			if err != nil {
-				return err
-			}
-
-			passphrase, err = getPassphrase()
-			if err != nil {
-				return err
-			}
-
-			err = handleStakingTransaction(stakingTx, networkHandler, validatorAddress)
-			if err != nil {
-				return err
-			}
-			return nil
+			return handleStakingTransaction(delegateStakePayloadMaker, networkHandler, validatorAddress)
 		},
 	}
 
 	subCmdNewValidator.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
+	subCmdNewValidator.Flags().BoolVar(&dryRun, "dry-run", false, "do not send signed transaction")
+	subCmdNewValidator.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing, with no node when --nonce is given")
+	subCmdNewValidator.Flags().BoolVar(&unsignedEnvelope, "unsigned", false, "output an unsigned envelope for hmy tx sign")
 	subCmdNewValidator.Flags().StringVar(&validatorName, "name", "", "validator's name")
 	subCmdNewValidator.Flags().StringVar(&validatorIdentity, "identity", "", "validator's identity")
 	subCmdNewValidator.Flags().StringVar(&validatorWebsite, "website", "", "validator's website")
@@ -455,7 +417,7 @@ Create a new validator"
 	)
 	subCmdNewValidator.Flags().StringVar(&blsPubKeyDir, "bls-pubkeys-dir", "", "directory to bls pubkeys storing pub.key, pub.pass files")
 	subCmdNewValidator.Flags().StringVar(&stakingAmount, "amount", "0.0", "staking amount")
-	subCmdNewValidator.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay")
+	subCmdNewValidator.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	subCmdNewValidator.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	subCmdNewValidator.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for transaction")
 	subCmdNewValidator.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
@@ -483,7 +445,7 @@ Create a new validator"
 		Args:    cobra.ExactArgs(0),
 		PreRunE: validateBlsKeyInput,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(shard.BeaconChainShardID, node)
+			networkHandler, err := stakingHandler()
 			if err != nil {
 				return err
 			}
@@ -596,29 +558,14 @@ Create a new validator"
 				}
 			}
 
-			nonce, err := getNonce(validatorAddress.String(), networkHandler)
-			if err != nil {
-				return err
-			}
-			stakingTx, err := createStakingTransaction(nonce, delegateStakePayloadMaker)
-			if err != nil {
-				return err
-			}
-
-			passphrase, err = getPassphrase()
-			if err != nil {
-				return err
-			}
-
-			err = handleStakingTransaction(stakingTx, networkHandler, validatorAddress)
-			if err != nil {
-				return err
-			}
-			return nil
+			return handleStakingTransaction(delegateStakePayloadMaker, networkHandler, validatorAddress)
 		},
 	}
 
 	subCmdEditValidator.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
+	subCmdEditValidator.Flags().BoolVar(&dryRun, "dry-run", false, "do not send signed transaction")
+	subCmdEditValidator.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing, with no node when --nonce is given")
+	subCmdEditValidator.Flags().BoolVar(&unsignedEnvelope, "unsigned", false, "output an unsigned envelope for hmy tx sign")
 	subCmdEditValidator.Flags().StringVar(&validatorName, "name", "", "validator's name")
 	subCmdEditValidator.Flags().StringVar(&validatorIdentity, "identity", "", "validator's identity")
 	subCmdEditValidator.Flags().StringVar(&validatorWebsite, "website", "", "validator's website")
@@ -633,7 +580,7 @@ Create a new validator"
 	subCmdEditValidator.Flags().StringVar(&slotKeyToRemove, "remove-bls-key", "", "remove BLS pubkey from slot")
 	subCmdEditValidator.Flags().StringVar(&active, "active", "", "validator active true/false")
 
-	subCmdEditValidator.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay")
+	subCmdEditValidator.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	subCmdEditValidator.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	subCmdEditValidator.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for transaction")
 	subCmdEditValidator.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
@@ -651,7 +598,7 @@ Create a new validator"
 Delegating to a validator
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(0, node)
+			networkHandler, err := stakingHandler()
 			if err != nil {
 				return err
 			}
@@ -672,33 +619,18 @@ Delegating to a validator
 				}
 			}
 
-			nonce, err := getNonce(delegatorAddress.String(), networkHandler)
-			if err != nil {
-				return err
-			}
-			stakingTx, err := createStakingTransaction(nonce, delegateStakePayloadMaker)
-			if err != nil {
-				return err
-			}
-
-			passphrase, err = getPassphrase()
-			if err != nil {
-				return err
-			}
-
-			err = handleStakingTransaction(stakingTx, networkHandler, delegatorAddress)
-			if err != nil {
-				return err
-			}
-			return nil
+			return handleStakingTransaction(delegateStakePayloadMaker, networkHandler, delegatorAddress)
 		},
 	}
 
 	subCmdDelegate.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
+	subCmdDelegate.Flags().BoolVar(&dryRun, "dry-run", false, "do not send signed transaction")
+	subCmdDelegate.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing, with no node when --nonce is given")
+	subCmdDelegate.Flags().BoolVar(&unsignedEnvelope, "unsigned", false, "output an unsigned envelope for hmy tx sign")
 	subCmdDelegate.Flags().Var(&delegatorAddress, "delegator-addr", "delegator's address")
 	subCmdDelegate.Flags().Var(&validatorAddress, "validator-addr", "validator's address")
 	subCmdDelegate.Flags().StringVar(&stakingAmount, "amount", "0", "staking amount")
-	subCmdDelegate.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay")
+	subCmdDelegate.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	subCmdDelegate.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	subCmdDelegate.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for transaction")
 	subCmdDelegate.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
@@ -718,7 +650,7 @@ Delegating to a validator
  Removing delegation responsibility
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(0, node)
+			networkHandler, err := stakingHandler()
 			if err != nil {
 				return err
 			}
@@ -739,33 +671,18 @@ Delegating to a validator
 				}
 			}
 
-			nonce, err := getNonce(delegatorAddress.String(), networkHandler)
-			if err != nil {
-				return err
-			}
-			stakingTx, err := createStakingTransaction(nonce, delegateStakePayloadMaker)
-			if err != nil {
-				return err
-			}
-
-			passphrase, err = getPassphrase()
-			if err != nil {
-				return err
-			}
-
-			err = handleStakingTransaction(stakingTx, networkHandler, delegatorAddress)
-			if err != nil {
-				return err
-			}
-			return nil
+			return handleStakingTransaction(delegateStakePayloadMaker, networkHandler, delegatorAddress)
 		},
 	}
 
 	subCmdUnDelegate.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
+	subCmdUnDelegate.Flags().BoolVar(&dryRun, "dry-run", false, "do not send signed transaction")
+	subCmdUnDelegate.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing, with no node when --nonce is given")
+	subCmdUnDelegate.Flags().BoolVar(&unsignedEnvelope, "unsigned", false, "output an unsigned envelope for hmy tx sign")
 	subCmdUnDelegate.Flags().Var(&delegatorAddress, "delegator-addr", "delegator's address")
 	subCmdUnDelegate.Flags().Var(&validatorAddress, "validator-addr", "source validator's address")
 	subCmdUnDelegate.Flags().StringVar(&stakingAmount, "amount", "0", "staking amount")
-	subCmdUnDelegate.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay")
+	subCmdUnDelegate.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	subCmdUnDelegate.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	subCmdUnDelegate.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for transaction")
 	subCmdUnDelegate.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
@@ -785,7 +702,7 @@ Delegating to a validator
 Collect token rewards
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(0, node)
+			networkHandler, err := stakingHandler()
 			if err != nil {
 				return err
 			}
@@ -796,31 +713,16 @@ Collect token rewards
 				}
 			}
 
-			nonce, err := getNonce(delegatorAddress.String(), networkHandler)
-			if err != nil {
-				return err
-			}
-			stakingTx, err := createStakingTransaction(nonce, delegateStakePayloadMaker)
-			if err != nil {
-				return err
-			}
-
-			passphrase, err = getPassphrase()
-			if err != nil {
-				return err
-			}
-
-			err = handleStakingTransaction(stakingTx, networkHandler, delegatorAddress)
-			if err != nil {
-				return err
-			}
-			return nil
+			return handleStakingTransaction(delegateStakePayloadMaker, networkHandler, delegatorAddress)
 		},
 	}
 
 	subCmdCollectRewards.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
+	subCmdCollectRewards.Flags().BoolVar(&dryRun, "dry-run", false, "do not send signed transaction")
+	subCmdCollectRewards.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing, with no node when --nonce is given")
+	subCmdCollectRewards.Flags().BoolVar(&unsignedEnvelope, "unsigned", false, "output an unsigned envelope for hmy tx sign")
 	subCmdCollectRewards.Flags().Var(&delegatorAddress, "delegator-addr", "delegator's address")
-	subCmdCollectRewards.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay")
+	subCmdCollectRewards.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	subCmdCollectRewards.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	subCmdCollectRewards.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for tx")
 	subCmdCollectRewards.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -18,7 +19,6 @@ import (
 	"github.com/harmony-one/go-sdk/pkg/transaction"
 	"github.com/harmony-one/go-sdk/pkg/validation"
 	"github.com/harmony-one/harmony/accounts"
-	"github.com/harmony-one/harmony/core"
 
 	"github.com/spf13/cobra"
 )
@@ -43,6 +43,17 @@ var (
 	timeout           uint32
 	timeFormat        = "2006-01-02 15:04:05.000000"
 	data              string
+	// the gas estimator settings, applied when --gas-limit or --gas-price is omitted
+	gasLimitMultiplier float64
+	gasPriceMultiplier float64
+	gasPricePolicy     string
+	gasPriceBlocks     int
+	// simulationPolicy picks the transactions run through a call before being sent
+	simulationPolicy string
+	// nonces hands out the nonces of the senders, in cooperation with the other hmy processes
+	nonces = transaction.NewNonceManager(func(m *transaction.NonceManager) {
+		m.Dir, _ = transaction.DefaultNonceDir()
+	})
 )
 
 type transactionLog struct {
@@ -52,6 +63,8 @@ type transactionLog struct {
 	RawTxn      string      `json:"raw-transaction,omitempty"`
 	Errors      []string    `json:"errors,omitempty"`
 	TimeSigned  string      `json:"time-signed-utc,omitempty"`
+	// CrossShard is where the transaction stands in its destination shard, with --track-cx
+	CrossShard *transaction.CXStatus `json:"cross-shard,omitempty"`
 }
 
 type transferFlags struct {
@@ -69,18 +82,30 @@ type transferFlags struct {
 	TrueNonce        bool    `json:"true-nonce"`
 }
 
-func handlerForShard(senderShard uint32, node string) (*rpc.HTTPMessenger, error) {
+func handlerForShard(ctx context.Context, senderShard uint32, node string) (rpc.T, error) {
+	if len(nodes) > 1 {
+		// The repeated --node are endpoints of a single shard, which must be the sender's
+		handler := nodeHandler()
+		shardID, err := nodeShardID(ctx, handler)
+		if err != nil {
+			return nil, err
+		}
+		if shardID != senderShard {
+			return nil, fmt.Errorf("the --node endpoints are of shard %d, not of shard %d", shardID, senderShard)
+		}
+		return handler, nil
+	}
 	if checkNodeInput(node) {
-		return rpc.NewHTTPHandler(node), nil
+		return nodeHandler(), nil
 	}
-	s, err := sharding.Structure(node)
+	s, err := sharding.Structure(ctx, node)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, shard := range s {
 		if uint32(shard.ShardID) == senderShard {
-			return rpc.NewHTTPHandler(shard.HTTP), nil
+			return shardHandler(rpc.NewHTTPHandler(shard.HTTP)), nil
 		}
 	}
 
@@ -103,9 +128,9 @@ func handlerForError(txLog *transactionLog, err error) error {
 func handlerForTransaction(txLog *transactionLog) error {
 	from := fromAddress.String()
 
-	var networkHandler *rpc.HTTPMessenger
+	var networkHandler rpc.T
 	if !offlineSign {
-		s, err := sharding.Structure(node)
+		s, err := sharding.Structure(rootCtx, node)
 		if handlerForError(txLog, err) != nil {
 			return err
 		}
@@ -114,16 +139,17 @@ func handlerForTransaction(txLog *transactionLog) error {
 			return err
 		}
 
-		networkHandler, err = handlerForShard(fromShardID, node)
+		networkHandler, err = handlerForShard(rootCtx, fromShardID, node)
 		if handlerForError(txLog, err) != nil {
 			return err
 		}
 	}
 
 	var ctrlr *transaction.Controller
-	if useLedgerWallet {
+	if useLedgerWallet || remoteSigner != "" {
 		account := accounts.Account{Address: address.Parse(from)}
-		ctrlr = transaction.NewController(networkHandler, nil, &account, *chainName.chainID, opts)
+		remote := func(c *transaction.Controller) { c.Behavior.Signer = remoteSignerFor(from) }
+		ctrlr = transaction.NewController(networkHandler, nil, &account, *chainName.chainID, opts, remote)
 	} else {
 		ks, acct, err := store.UnlockedKeystore(from, passphrase)
 		if handlerForError(txLog, err) != nil {
@@ -132,11 +158,6 @@ func handlerForTransaction(txLog *transactionLog) error {
 		ctrlr = transaction.NewController(networkHandler, ks, acct, *chainName.chainID, opts)
 	}
 
-	nonce, err := getNonce(fromAddress.String(), networkHandler)
-	if handlerForError(txLog, err) != nil {
-		return err
-	}
-
 	amt, err := common.NewDecFromString(amount)
 	if err != nil {
 		amtErr := fmt.Errorf("amount %w", err)
@@ -144,30 +165,10 @@ func handlerForTransaction(txLog *transactionLog) error {
 		return amtErr
 	}
 
-	gPrice, err := common.NewDecFromString(gasPrice)
+	// Left at zero, the gas limit and price are estimated by the controller
+	gLimit, gPrice, err := gasFlags()
 	if err != nil {
-		gasErr := fmt.Errorf("gas-price %w", err)
-		handlerForError(txLog, gasErr)
-		return gasErr
-	}
-
-	var gLimit uint64
-	if gasLimit == "" {
-		gLimit, err = core.IntrinsicGas([]byte(""), false, true, true, false)
-		if handlerForError(txLog, err) != nil {
-			return err
-		}
-	} else {
-		if strings.HasPrefix(gasLimit, "-") {
-			limitErr := errors.New(fmt.Sprintf("gas-limit can not be negative: %s", gasLimit))
-			handlerForError(txLog, limitErr)
-			return limitErr
-		}
-		tempLimit, e := strconv.ParseInt(gasLimit, 10, 64)
-		if handlerForError(txLog, e) != nil {
-			return e
-		}
-		gLimit = uint64(tempLimit)
+		return handlerForError(txLog, err)
 	}
 
 	dataByte, err := transaction.StringToByte(data)
@@ -177,23 +178,34 @@ func handlerForTransaction(txLog *transactionLog) error {
 
 	addr := toAddress.String()
 
+	nonce, reserved, err := getNonce(rootCtx, fromAddress.String(), networkHandler)
+	if handlerForError(txLog, err) != nil {
+		return err
+	}
+
 	txLog.TimeSigned = time.Now().UTC().Format(timeFormat) // Approximate time of signature
 	err = ctrlr.ExecuteTransaction(
+		rootCtx,
 		nonce, gLimit,
 		&addr,
 		fromShardID, toShardID,
 		amt, gPrice,
 		dataByte,
 	)
+	settleNonce(rootCtx, from, nonce, reserved, networkHandler, !dryRun && ctrlr.TransactionHash() != nil)
 
 	if dryRun {
-		txLog.RawTxn = ctrlr.RawTransaction()
-		txLog.Transaction = make(map[string]interface{})
-		_ = json.Unmarshal([]byte(ctrlr.TransactionToJSON(false)), &txLog.Transaction)
+		// A failed build leaves no transaction or signature to show
+		if err == nil {
+			txLog.RawTxn = ctrlr.RawTransaction()
+			txLog.Transaction = make(map[string]interface{})
+			_ = json.Unmarshal([]byte(ctrlr.TransactionToJSON(false)), &txLog.Transaction)
+		}
 	} else if txHash := ctrlr.TransactionHash(); txHash != nil {
 		txLog.TxHash = *txHash
 	}
 	txLog.Receipt = ctrlr.Receipt()["result"]
+	txLog.CrossShard = ctrlr.CXStatus()
 	if err != nil {
 		// Report all transaction errors first...
 		for _, txError := range ctrlr.TransactionErrors() {
@@ -261,7 +273,7 @@ func handlerForBulkTransactions(txLog *transactionLog, index int) error {
 	if txnFlags.GasPrice != nil {
 		gasPrice = *txnFlags.GasPrice
 	} else {
-		gasPrice = "100" // Reset to default for subsequent transactions
+		gasPrice = "" // Reset to default for subsequent transactions
 	}
 	if txnFlags.GasLimit != nil {
 		gasLimit = *txnFlags.GasLimit
@@ -273,6 +285,24 @@ func handlerForBulkTransactions(txLog *transactionLog, index int) error {
 	return handlerForTransaction(txLog)
 }
 
+// gasEstimator estimates the gas of the transactions omitting --gas-limit or --gas-price
+func gasEstimator() *transaction.GasEstimator {
+	return transaction.NewGasEstimator(func(e *transaction.GasEstimator) {
+		e.LimitMultiplier = gasLimitMultiplier
+		e.PriceMultiplier = gasPriceMultiplier
+		e.PricePolicy = transaction.GasPricePolicy(gasPricePolicy)
+		e.PriceBlocks = gasPriceBlocks
+	})
+}
+
+// remoteSignerFor is the signer of --remote-signer for the account from, nil when not set
+func remoteSignerFor(from string) transaction.Signer {
+	if remoteSigner == "" {
+		return nil
+	}
+	return transaction.NewRemoteSigner(remoteSigner, address.Parse(from))
+}
+
 func opts(ctlr *transaction.Controller) {
 	if dryRun {
 		ctlr.Behavior.DryRun = true
@@ -286,31 +316,86 @@ func opts(ctlr *transaction.Controller) {
 	if timeout > 0 {
 		ctlr.Behavior.ConfirmationWaitTime = timeout
 	}
+	ctlr.Behavior.GasEstimator = gasEstimator()
+	ctlr.Behavior.Simulation = transaction.SimulationPolicy(simulationPolicy)
+	if trackCX {
+		ctlr.Behavior.CXTracker = cxTracker()
+	}
 }
 
-func getNonce(address string, messenger rpc.T) (uint64, error) {
+// getNonce returns the nonce of the flags, along with the shard it is reserved on when
+// it was handed out by reserveNonce, nil otherwise
+func getNonce(ctx context.Context, address string, messenger rpc.T) (uint64, *uint32, error) {
 	if trueNonce {
 		// cannot define nonce when using true nonce
-		return transaction.GetNextNonce(address, messenger), nil
+		return transaction.GetNextNonce(ctx, address, messenger), nil, nil
 	}
-	return getNonceFromInput(address, inputNonce, messenger)
+	return getNonceFromInput(ctx, address, inputNonce, messenger)
 }
 
-func getNonceFromInput(addr, inputNonce string, messenger rpc.T) (uint64, error) {
+func getNonceFromInput(ctx context.Context, addr, inputNonce string, messenger rpc.T) (uint64, *uint32, error) {
 	if inputNonce != "" {
 		if strings.HasPrefix(inputNonce, "-") {
-			return 0, errors.New(fmt.Sprintf("nonce can not be negative: %s", inputNonce))
+			return 0, nil, errors.New(fmt.Sprintf("nonce can not be negative: %s", inputNonce))
 		}
 		nonce, err := strconv.ParseUint(inputNonce, 10, 64)
 		if err != nil {
-			return 0, err
+			return 0, nil, err
 		} else {
-			return nonce, nil
+			return nonce, nil, nil
 		}
 	} else if offlineSign {
-		return 0, errors.New("nonce value must be specified when offline sign")
+		return 0, nil, errors.New("nonce value must be specified when offline sign")
+	} else {
+		nonce, shardID, err := reserveNonce(ctx, addr, messenger)
+		if err != nil {
+			return 0, nil, err
+		}
+		return nonce, &shardID, nil
+	}
+}
+
+// reserveNonce hands out the next nonce of addr on the shard of the node, returned along
+// with it, warning about the nonces missing from the pool that hold back the
+// transactions of the account
+func reserveNonce(ctx context.Context, addr string, messenger rpc.T) (uint64, uint32, error) {
+	shardID, err := nodeShardID(ctx, messenger)
+	if err != nil {
+		return 0, 0, err
+	}
+	nonce, err := nonces.Reserve(ctx, chainName.chainID.Value, addr, shardID, messenger)
+	if err != nil {
+		return 0, 0, err
+	}
+	if gaps, err := nonces.Gaps(ctx, chainName.chainID.Value, addr, shardID, messenger); err == nil && len(gaps) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: nonces %v of %s are used by no transaction, the later ones wait for them\n", gaps, addr)
+	}
+	return nonce, shardID, nil
+}
+
+// nodeShardID is the shard of the node messenger reaches
+func nodeShardID(ctx context.Context, messenger rpc.T) (uint32, error) {
+	reply, err := messenger.SendRPC(ctx, rpc.Method.GetShardID, []interface{}{})
+	if err != nil {
+		return 0, err
+	}
+	shardID, ok := reply["result"].(float64)
+	if !ok {
+		return 0, errors.New("could not read the shard of the node")
+	}
+	return uint32(shardID), nil
+}
+
+// settleNonce marks a nonce reserved on the shard as used when the node accepted its
+// transaction, and gives it back otherwise. A nonce reserved on no shard is left alone.
+func settleNonce(ctx context.Context, addr string, nonce uint64, reserved *uint32, messenger rpc.T, accepted bool) {
+	if reserved == nil {
+		return
+	}
+	if accepted {
+		_ = nonces.Sent(chainName.chainID.Value, addr, *reserved, nonce)
 	} else {
-		return transaction.GetNextPendingNonce(addr, messenger), nil
+		_ = nonces.Release(ctx, chainName.chainID.Value, addr, *reserved, nonce, messenger)
 	}
 }
 
@@ -320,7 +405,7 @@ type Error struct {
 }
 
 func reportError(method string, txHash string) error {
-	success, failure := rpc.Request(method, node, []interface{}{})
+	success, failure := nodeHandler().SendRPC(rootCtx, method, []interface{}{})
 	if failure != nil {
 		return failure
 	}
@@ -415,7 +500,7 @@ Create a transaction, sign it, and send off to the Harmony blockchain
 	cmdTransfer.Flags().BoolVar(&offlineSign, "offline-sign", false, "output offline signing")
 	cmdTransfer.Flags().BoolVar(&trueNonce, "true-nonce", false, "send transaction with on-chain nonce")
 	cmdTransfer.Flags().StringVar(&amount, "amount", "0", "amount to send (ONE)")
-	cmdTransfer.Flags().StringVar(&gasPrice, "gas-price", "100", "gas price to pay (NANO)")
+	cmdTransfer.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
 	cmdTransfer.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit")
 	cmdTransfer.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for tx")
 	cmdTransfer.Flags().Uint32Var(&fromShardID, "from-shard", 0, "source shard id")
@@ -425,6 +510,8 @@ Create a transaction, sign it, and send off to the Harmony blockchain
 	cmdTransfer.Flags().Uint32Var(&timeout, "timeout", defaultTimeout, "set timeout in seconds. Set to 0 to not wait for confirm")
 	cmdTransfer.Flags().BoolVar(&userProvidesPassphrase, "passphrase", false, ppPrompt)
 	cmdTransfer.Flags().StringVar(&passphraseFilePath, "passphrase-file", "", "path to a file containing the passphrase")
+	cmdTransfer.Flags().BoolVar(&trackCX, "track-cx", false, "follow a cross shard transfer until its destination shard credits it")
+	cxFlags(cmdTransfer)
 
 	RootCmd.AddCommand(cmdTransfer)
 
@@ -436,12 +523,12 @@ Create a transaction, sign it, and send off to the Harmony blockchain
 Get Nonce From a Account
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			networkHandler, err := handlerForShard(fromShardID, node)
+			networkHandler, err := handlerForShard(rootCtx, fromShardID, node)
 			if err != nil {
 				return err
 			}
 
-			nonce := transaction.GetNextPendingNonce(fromAddress.address, networkHandler)
+			nonce := transaction.GetNextPendingNonce(rootCtx, fromAddress.address, networkHandler)
 			fmt.Printf("nonce is \"%d\"", nonce)
 			return err
 		},
@@ -467,7 +554,7 @@ Send a offline signed transaction to the Harmony blockchain
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var txLogs []*transactionLog
 
-			networkHandler, err := handlerForShard(fromShardID, node)
+			networkHandler, err := handlerForShard(rootCtx, fromShardID, node)
 			if err != nil {
 				return err
 			}
@@ -489,7 +576,7 @@ Send a offline signed transaction to the Harmony blockchain
 				}
 
 				ctrlr := transaction.NewController(networkHandler, nil, nil, *chainName.chainID, opts)
-				err := ctrlr.ExecuteRawTransaction(txLog.RawTxn)
+				err := ctrlr.ExecuteRawTransaction(rootCtx, txLog.RawTxn)
 				if handlerForError(txLog, err) != nil {
 					txLog.Errors = append(txLog.Errors, err.Error())
 					continue
@@ -0,0 +1,511 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	rpcEth "github.com/harmony-one/go-sdk/pkg/rpc/eth"
+	"github.com/harmony-one/go-sdk/pkg/store"
+	"github.com/harmony-one/go-sdk/pkg/transaction"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var (
+	priceBump uint64
+	// buildEth builds an eth transaction with hmy tx build
+	buildEth bool
+	// unsignedEnvelope has the staking commands output an envelope instead of signing
+	unsignedEnvelope bool
+	// trackCX has transfer follow its cross shard transaction into the destination shard
+	trackCX bool
+	// the cross shard tracker settings, see cxTracker
+	cxStallTime  uint32
+	cxMaxResends int
+)
+
+// cxTracker follows the cross shard transactions into their destination shard, reached
+// through the sharding structure of the node, its replies normalized as those of --node
+func cxTracker() *transaction.CXTracker {
+	return transaction.NewCXTracker(func(T *transaction.CXTracker) {
+		T.Shard = func(ctx context.Context, shardID uint32) (rpc.T, error) {
+			routes, err := client.NewClient(nodeHandler()).GetShardingStructure(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, route := range routes {
+				if uint32(route.ShardID) == shardID {
+					return shardHandler(rpc.NewHTTPHandler(route.HTTP)), nil
+				}
+			}
+			return nil, errors.Wrapf(transaction.ErrNoShardEndpoint, "shard %d", shardID)
+		}
+		T.StallTime = time.Duration(cxStallTime) * time.Second
+		T.MaxResends = cxMaxResends
+	})
+}
+
+// cxFlags adds the settings of cxTracker to cmd
+func cxFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint32Var(&cxStallTime, "stall-time", 30, "seconds without progress before the cross shard receipt is resent")
+	cmd.Flags().IntVar(&cxMaxResends, "max-resends", 3, "number of times the cross shard receipt is resent before giving up")
+}
+
+// replacementLog is the outcome of a speed-up or a cancel, Landed being the hash of
+// the transaction of the nonce that made it into a block
+type replacementLog struct {
+	Original    string      `json:"original-transaction-hash"`
+	Replacement string      `json:"transaction-hash"`
+	GasPrice    numeric.Dec `json:"gas-price"`
+	Landed      string      `json:"landed-transaction-hash,omitempty"`
+	Receipt     interface{} `json:"blockchain-receipt,omitempty"`
+	Errors      []string    `json:"errors,omitempty"`
+}
+
+// replaceTransaction re-signs the pending transaction hash with the same nonce at a
+// higher gas price, as an empty transfer to its sender when cancel is set, then waits
+// for either one to be mined
+func replaceTransaction(hash string, cancel bool) error {
+	networkHandler := nodeHandler()
+	replacement, err := transaction.NewReplacement(rootCtx, networkHandler, hash, cancel, priceBump, gasEstimator())
+	if err != nil {
+		return err
+	}
+	from := address.ToBech32(address.Parse(replacement.Original.From))
+	// Either transaction may land, they are tracked together once sent
+	noWait := func(ctlr *transaction.Controller) { ctlr.Behavior.ConfirmationWaitTime = 0 }
+	var ctrlr *transaction.Controller
+	if useLedgerWallet || remoteSigner != "" {
+		account := accounts.Account{Address: address.Parse(from)}
+		remote := func(c *transaction.Controller) { c.Behavior.Signer = remoteSignerFor(from) }
+		ctrlr = transaction.NewController(networkHandler, nil, &account, *chainName.chainID, opts, noWait, remote)
+	} else {
+		ks, acct, err := store.UnlockedKeystore(from, passphrase)
+		if err != nil {
+			return err
+		}
+		ctrlr = transaction.NewController(networkHandler, ks, acct, *chainName.chainID, opts, noWait)
+	}
+
+	txLog := replacementLog{
+		Original: hash,
+		GasPrice: numeric.NewDecFromBigInt(replacement.GasPrice).Quo(nanoAsDec),
+	}
+	err = replacement.Execute(rootCtx, ctrlr)
+	if err != nil {
+		for _, txError := range ctrlr.TransactionErrors() {
+			txLog.Errors = append(txLog.Errors, txError.Error().Error())
+		}
+		txLog.Errors = append(txLog.Errors, err.Error())
+	} else {
+		txLog.Replacement = *ctrlr.TransactionHash()
+		if timeout > 0 {
+			landed, receipt, waitErr := transaction.WaitMined(
+				rootCtx, networkHandler, []string{hash, txLog.Replacement}, time.Duration(timeout)*time.Second,
+			)
+			if waitErr != nil {
+				txLog.Errors = append(txLog.Errors, waitErr.Error())
+				err = waitErr
+			} else {
+				txLog.Landed, txLog.Receipt = landed, receipt
+			}
+		}
+	}
+	fmt.Println(common.ToJSONUnsafe(txLog, !noPrettyOutput))
+	return err
+}
+
+// gasFlags parses --gas-limit and --gas-price, left at zero when omitted for the
+// controllers to estimate them
+func gasFlags() (uint64, numeric.Dec, error) {
+	gPrice := numeric.ZeroDec()
+	if gasPrice != "" {
+		price, err := common.NewDecFromString(gasPrice)
+		if err != nil {
+			return 0, gPrice, fmt.Errorf("gas-price %w", err)
+		}
+		gPrice = price
+	}
+	var gLimit uint64
+	if gasLimit != "" {
+		if strings.HasPrefix(gasLimit, "-") {
+			return 0, gPrice, fmt.Errorf("gas-limit can not be negative: %s", gasLimit)
+		}
+		tempLimit, err := strconv.ParseUint(gasLimit, 10, 64)
+		if err != nil {
+			return 0, gPrice, fmt.Errorf("gas-limit %w", err)
+		}
+		gLimit = tempLimit
+	}
+	return gLimit, gPrice, nil
+}
+
+// printEnvelope outputs the envelope the controller built. The nonce it was given is
+// marked built, held for the envelope until hmy tx broadcast sends it or a day has
+// passed; it is given back at once when the build failed.
+func printEnvelope(
+	from string, nonce uint64, reserved *uint32, networkHandler rpc.T, err error,
+	envelope func() (*transaction.Envelope, error),
+) error {
+	if err != nil {
+		settleNonce(rootCtx, from, nonce, reserved, networkHandler, false)
+		return err
+	}
+	built, err := envelope()
+	if err != nil {
+		settleNonce(rootCtx, from, nonce, reserved, networkHandler, false)
+		return err
+	}
+	if reserved != nil {
+		_ = nonces.Built(chainName.chainID.Value, from, *reserved, nonce)
+	}
+	fmt.Println(common.ToJSONUnsafe(built, !noPrettyOutput))
+	return nil
+}
+
+// buildEnvelope builds the transfer of the flags without signing it, for hmy tx sign
+func buildEnvelope() error {
+	from := fromAddress.String()
+	account := accounts.Account{Address: address.Parse(from)}
+	amt, err := common.NewDecFromString(amount)
+	if err != nil {
+		return fmt.Errorf("amount %w", err)
+	}
+	gLimit, gPrice, err := gasFlags()
+	if err != nil {
+		return err
+	}
+	dataByte, err := transaction.StringToByte(data)
+	if err != nil {
+		return err
+	}
+	to := toAddress.String()
+
+	if buildEth {
+		// Built from the shard the eth chain-id is of, which the envelope is broadcast to
+		var ethShard uint32
+		if _, shardID := common.KnownChain(chainName.chainID.Value); shardID != nil {
+			ethShard = *shardID
+		}
+		networkHandler, err := handlerForShard(rootCtx, ethShard, node)
+		if err != nil {
+			return err
+		}
+		rpc.Method = rpcEth.Method
+		nonce, reserved, err := getNonce(rootCtx, from, networkHandler)
+		if err != nil {
+			return err
+		}
+		ctrlr := transaction.NewEthController(networkHandler, nil, &account, *chainName.chainID, ethOpts)
+		err = ctrlr.BuildEthTransaction(rootCtx, nonce, gLimit, to, amt, gPrice, dataByte)
+		return printEnvelope(from, nonce, reserved, networkHandler, err, ctrlr.Envelope)
+	}
+	networkHandler, err := handlerForShard(rootCtx, fromShardID, node)
+	if err != nil {
+		return err
+	}
+	nonce, reserved, err := getNonce(rootCtx, from, networkHandler)
+	if err != nil {
+		return err
+	}
+	ctrlr := transaction.NewController(networkHandler, nil, &account, *chainName.chainID, opts)
+	err = ctrlr.BuildTransaction(rootCtx, nonce, gLimit, &to, fromShardID, toShardID, amt, gPrice, dataByte)
+	return printEnvelope(from, nonce, reserved, networkHandler, err, ctrlr.Envelope)
+}
+
+// buildStakingEnvelope builds the staking transaction f makes without signing it
+func buildStakingEnvelope(
+	f staking.StakeMsgFulfiller, networkHandler rpc.T, from string, gLimit uint64, gPrice numeric.Dec,
+) error {
+	account := accounts.Account{Address: address.Parse(from)}
+	nonce, reserved, err := getNonce(rootCtx, from, networkHandler)
+	if err != nil {
+		return err
+	}
+	ctrlr := transaction.NewStakingController(networkHandler, nil, &account, *chainName.chainID, stakingOpts)
+	err = ctrlr.BuildStakingTransaction(rootCtx, nonce, gLimit, gPrice, f)
+	return printEnvelope(from, nonce, reserved, networkHandler, err, ctrlr.Envelope)
+}
+
+func readEnvelope(path string) (*transaction.Envelope, error) {
+	openFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer openFile.Close()
+	envelope := new(transaction.Envelope)
+	if err := json.NewDecoder(openFile).Decode(envelope); err != nil {
+		return nil, errors.Wrapf(err, "could not read the envelope %s", path)
+	}
+	return envelope, nil
+}
+
+// signEnvelope previews the envelope at path on stderr, then outputs it signed with the
+// keystore, the ledger or the remote signer
+func signEnvelope(path string) error {
+	envelope, err := readEnvelope(path)
+	if err != nil {
+		return err
+	}
+	preview, err := envelope.Preview()
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stderr, preview)
+
+	var signer transaction.Signer
+	switch {
+	case remoteSigner != "":
+		signer = remoteSignerFor(envelope.From)
+	case useLedgerWallet:
+		signer = transaction.NewLedgerSigner(address.Parse(envelope.From))
+	default:
+		pp, err := getPassphrase()
+		if err != nil {
+			return err
+		}
+		ks, acct, err := store.UnlockedKeystore(envelope.From, pp)
+		if err != nil {
+			return err
+		}
+		signer = transaction.NewKeystoreSigner(ks, acct)
+	}
+	if err := envelope.Sign(rootCtx, signer); err != nil {
+		return err
+	}
+	fmt.Println(common.ToJSONUnsafe(envelope, !noPrettyOutput))
+	return nil
+}
+
+// broadcastEnvelope sends the signed envelope at path to a node of its shard
+func broadcastEnvelope(path string) error {
+	envelope, err := readEnvelope(path)
+	if err != nil {
+		return err
+	}
+	if envelope.RawTxn == "" {
+		return transaction.ErrNotSigned
+	}
+	var sent interface {
+		TransactionHash() *string
+		Receipt() rpc.Reply
+		TransactionErrors() transaction.Errors
+	}
+	switch envelope.Kind {
+	case transaction.EnvelopePlain, transaction.EnvelopeStaking:
+		networkHandler, handlerErr := handlerForShard(rootCtx, envelope.ShardID, node)
+		if handlerErr != nil {
+			return handlerErr
+		}
+		if envelope.Kind == transaction.EnvelopePlain {
+			ctrlr := transaction.NewController(networkHandler, nil, nil, *chainName.chainID, opts)
+			err, sent = ctrlr.ExecuteRawTransaction(rootCtx, envelope.RawTxn), ctrlr
+		} else {
+			ctrlr := transaction.NewStakingController(networkHandler, nil, nil, *chainName.chainID, stakingOpts)
+			err, sent = ctrlr.ExecuteRawStakingTransaction(rootCtx, envelope.RawTxn), ctrlr
+		}
+	case transaction.EnvelopeEth:
+		// The shard is looked up before switching to the eth methods, as nodes serve no
+		// sharding structure under eth
+		networkHandler, handlerErr := handlerForShard(rootCtx, envelope.ShardID, node)
+		if handlerErr != nil {
+			return handlerErr
+		}
+		rpc.Method = rpcEth.Method
+		ctrlr := transaction.NewEthController(networkHandler, nil, nil, *chainName.chainID, ethOpts)
+		err, sent = ctrlr.ExecuteRawTransaction(rootCtx, envelope.RawTxn), ctrlr
+	default:
+		return errors.Wrap(transaction.ErrUnknownEnvelope, envelope.Kind)
+	}
+
+	txLog := transactionLog{}
+	if txHash := sent.TransactionHash(); txHash != nil {
+		txLog.TxHash = *txHash
+		markEnvelopeSent(envelope)
+	}
+	txLog.Receipt = sent.Receipt()["result"]
+	if err != nil {
+		for _, txError := range sent.TransactionErrors() {
+			_ = handlerForError(&txLog, txError.Error())
+		}
+		err = handlerForError(&txLog, err)
+	}
+	fmt.Println(common.ToJSONUnsafe(txLog, !noPrettyOutput))
+	return err
+}
+
+// markEnvelopeSent records the nonce of a broadcast envelope as sent, should hmy tx build
+// have reserved it on this machine
+func markEnvelopeSent(envelope *transaction.Envelope) {
+	tx, err := envelope.Transaction()
+	if err != nil || envelope.ChainID == nil {
+		return
+	}
+	if withNonce, ok := tx.(interface{ Nonce() uint64 }); ok {
+		_ = nonces.Sent(envelope.ChainID, envelope.From, envelope.ShardID, withNonce.Nonce())
+	}
+}
+
+func init() {
+	cmdTx := &cobra.Command{
+		Use:   "tx",
+		Short: "Act on transactions already sent",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.Help()
+			return nil
+		},
+	}
+
+	replaceCmd := func(use, short string, cancel bool) *cobra.Command {
+		cmd := &cobra.Command{
+			Use:   use + " <transaction-hash>",
+			Short: short,
+			Long: fmt.Sprintf(`
+%s
+
+The transaction is looked up in the pool of the node given with --node, which must be a
+node of its shard, and re-signed by its sender with the same nonce at a gas price raised
+by --gas-price-bump percent, or the estimated gas price when higher. Both transactions
+are then tracked until one of them is in a block.
+`, short),
+			Args: cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				pp, err := getPassphrase()
+				if err != nil {
+					return err
+				}
+				passphrase = pp
+				return replaceTransaction(args[0], cancel)
+			},
+		}
+		cmd.Flags().Uint64Var(&priceBump, "gas-price-bump", transaction.DefaultPriceBump, "percentage the gas price is raised by")
+		cmd.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
+		cmd.Flags().Uint32Var(&timeout, "timeout", defaultTimeout, "set timeout in seconds. Set to 0 to not wait for confirm")
+		cmd.Flags().BoolVar(&userProvidesPassphrase, "passphrase", false, ppPrompt)
+		cmd.Flags().StringVar(&passphraseFilePath, "passphrase-file", "", "path to a file containing the passphrase")
+		return cmd
+	}
+	cmdTx.AddCommand(
+		replaceCmd("speed-up", "Resend a pending transaction at a higher gas price", false),
+		replaceCmd("cancel", "Replace a pending transaction by an empty transfer to its sender", true),
+	)
+
+	cmdBuild := &cobra.Command{
+		Use:   "build",
+		Short: "Output an unsigned transfer, to be signed by hmy tx sign",
+		Long: `
+Build a transfer with the nonce, gas, chain id and shards filled in from the network, and
+output it as an unsigned envelope that hmy tx sign signs on a machine with no network
+access, the keystore of the sender being needed there only. Staking transactions are
+built with the --unsigned flag of the staking commands.
+`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return buildEnvelope()
+		},
+	}
+	cmdBuild.Flags().Var(&fromAddress, "from", "sender's one address")
+	cmdBuild.Flags().Var(&toAddress, "to", "the destination one address")
+	cmdBuild.Flags().StringVar(&amount, "amount", "0", "amount to send (ONE)")
+	cmdBuild.Flags().Uint32Var(&fromShardID, "from-shard", 0, "source shard id")
+	cmdBuild.Flags().Uint32Var(&toShardID, "to-shard", 0, "target shard id")
+	cmdBuild.Flags().BoolVar(&buildEth, "eth", false, "build an Ethereum compatible transaction")
+	cmdBuild.Flags().BoolVar(&trueNonce, "true-nonce", false, "use the on-chain nonce")
+	cmdBuild.Flags().StringVar(&inputNonce, "nonce", "", "set nonce for tx")
+	cmdBuild.Flags().StringVar(&gasPrice, "gas-price", "", "gas price to pay (NANO), estimated when omitted")
+	cmdBuild.Flags().StringVar(&gasLimit, "gas-limit", "", "gas limit, estimated when omitted")
+	cmdBuild.Flags().StringVar(&data, "data", "", "transaction data")
+	cmdBuild.Flags().StringVar(&targetChain, "chain-id", "", "what chain ID to target")
+	for _, flagName := range [...]string{"from", "to"} {
+		cmdBuild.MarkFlagRequired(flagName)
+	}
+
+	cmdSign := &cobra.Command{
+		Use:   "sign <envelope-file>",
+		Short: "Sign a transaction envelope of hmy tx build, after showing what it does",
+		Long: `
+Sign the transaction of an envelope output by hmy tx build, or by a staking command given
+--unsigned, and output the envelope signed for hmy tx broadcast. The transaction is
+described on stderr first. The key of the sender is taken from the keystore, the ledger
+with --ledger or the signing service of --remote-signer; no node is contacted.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return signEnvelope(args[0])
+		},
+	}
+	cmdSign.Flags().BoolVar(&userProvidesPassphrase, "passphrase", false, ppPrompt)
+	cmdSign.Flags().StringVar(&passphraseFilePath, "passphrase-file", "", "path to a file containing the passphrase")
+
+	cmdBroadcast := &cobra.Command{
+		Use:   "broadcast <envelope-file>",
+		Short: "Send a transaction envelope signed by hmy tx sign",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return broadcastEnvelope(args[0])
+		},
+	}
+	cmdBroadcast.Flags().Uint32Var(&timeout, "timeout", defaultTimeout, "set timeout in seconds. Set to 0 to not wait for confirm")
+
+	cmdTx.AddCommand(cmdBuild, cmdSign, cmdBroadcast)
+
+	cmdTx.AddCommand(&cobra.Command{
+		Use:   "decode <raw-transaction>",
+		Short: "Decode a signed transaction and recover its signer",
+		Long: `
+Decode the hex of a signed plain, Ethereum compatible or staking transaction, such as the
+raw-transaction of a transaction log or an envelope, and recover the account that signed
+it. The chain-id is checked against the known chains, the problems found being listed
+under warnings. No node is contacted.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			decoded, err := transaction.DecodeRawTransaction(args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Println(common.ToJSONUnsafe(decoded, !noPrettyOutput))
+			return nil
+		},
+	})
+
+	cmdTrackCX := &cobra.Command{
+		Use:   "track-cx <transaction-hash>",
+		Short: "Follow a cross shard transaction until its destination shard credits it",
+		Long: `
+Follow a cross shard transaction, sent to the shard of the node given with --node, until
+the destination shard credits its receipt. The destination shard is reached through the
+sharding structure of the node. A receipt making no progress for --stall-time seconds is
+resent by the source shard (resendCx), at most --max-resends times. Each step is reported
+on stderr and the final status printed.
+`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tracker := cxTracker()
+			tracker.Progress = func(status transaction.CXStatus) {
+				fmt.Fprintf(os.Stderr, "[%s] shard %d to %d: %s, %d resends\n",
+					time.Now().UTC().Format(timeFormat), status.ShardID, status.ToShardID, status.Stage, status.Resends,
+				)
+			}
+			status, err := tracker.Track(rootCtx, nodeHandler(), args[0])
+			fmt.Println(common.ToJSONUnsafe(status, !noPrettyOutput))
+			return err
+		},
+	}
+	cxFlags(cmdTrackCX)
+	cmdTx.AddCommand(cmdTrackCX)
+
+	RootCmd.AddCommand(cmdTx)
+}
@@ -8,6 +8,8 @@ import (
 
 	bls_core "github.com/harmony-one/bls/ffi/go/bls"
 	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/fakenode"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
 	"github.com/harmony-one/harmony/crypto/bls"
 	"github.com/spf13/cobra"
@@ -111,7 +113,7 @@ func init() {
 			if err := key.DeserializeHexStr(inputKey); err != nil {
 				return err
 			}
-			reply, err := rpc.Request(rpc.Method.GetShardingStructure, node, []interface{}{})
+			reply, err := nodeHandler().SendRPC(rootCtx, rpc.Method.GetShardingStructure, []interface{}{})
 			if err != nil {
 				return err
 			}
@@ -138,5 +140,60 @@ func init() {
 		},
 	}}...)
 
+	var (
+		fakeShards uint32
+		fakePort   int
+		fakeFunds  []string
+		fakeLostCX int
+	)
+	cmdFakeNode := &cobra.Command{
+		Use:   "fake-node",
+		Short: "serve an in-memory network for local testing, until interrupted",
+		Long: `
+Serve an in-memory network on local ports, shard i listening on port + i. Point the other
+commands at it with --node http://127.0.0.1:<port>; transactions are verified and applied
+as soon as they are received. Use --fund address:amount to credit an account with ONEs on
+every shard. Use --lost-cx-receipts to drop the first receipts of cross-shard transfers on
+their way to the destination shard, until resent.
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			network := fakenode.NewNetwork(func(n *fakenode.Network) {
+				n.ShardCount = fakeShards
+				n.Port = fakePort
+				n.Chain = *chainName.chainID
+				n.LostCXReceipts = fakeLostCX
+			})
+			for _, fund := range fakeFunds {
+				parts := strings.Split(fund, ":")
+				if len(parts) != 2 {
+					return fmt.Errorf("invalid --fund %s, expected address:amount", fund)
+				}
+				amount, err := common.NewDecFromString(parts[1])
+				if err != nil {
+					return err
+				}
+				for shardID := uint32(0); shardID < fakeShards; shardID++ {
+					if err := network.Fund(parts[0], shardID, amount.Mul(oneAsDec).TruncateInt()); err != nil {
+						return err
+					}
+				}
+			}
+			if err := network.Start(); err != nil {
+				return err
+			}
+			defer network.Close()
+			endpoints, _ := network.Endpoints()
+			result, _ := json.Marshal(endpoints)
+			fmt.Println(string(result))
+			<-rootCtx.Done()
+			return nil
+		},
+	}
+	cmdFakeNode.Flags().Uint32Var(&fakeShards, "shards", 2, "number of shards")
+	cmdFakeNode.Flags().IntVar(&fakePort, "port", 9500, "port of shard 0")
+	cmdFakeNode.Flags().StringArrayVar(&fakeFunds, "fund", []string{}, "address:amount to credit on every shard, may be repeated")
+	cmdFakeNode.Flags().IntVar(&fakeLostCX, "lost-cx-receipts", 0, "number of cross-shard receipts lost until resent")
+	cmdUtilities.AddCommand(cmdFakeNode)
+
 	RootCmd.AddCommand(cmdUtilities)
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
@@ -46,11 +47,20 @@ var (
 			return request(rpc.Method.GetValidatorInformationByBlockNumber, []interface{}{addr.address, args[1]})
 		},
 	}, {
-		Use:   "all-information",
+		Use:   "all-information [page]",
 		Short: "all validators information",
-		Args:  cobra.ExactArgs(1),
+		Long: `
+A page of 100 validators, or every validator with --all
+`,
+		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			noLatest = true
+			if listAll {
+				return printAll(client.NewClient(nodeHandler()).IterateAllValidatorInformation("").Iterator)
+			}
+			if len(args) == 0 {
+				return errors.New("the page argument is required without --all")
+			}
 			page, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return errors.Wrapf(err, "the page argument must be integer, supplied %v", args[0])
@@ -58,11 +68,23 @@ var (
 			return request(rpc.Method.GetAllValidatorInformation, []interface{}{page})
 		},
 	}, {
-		Use:   "all-information-by-block-number",
+		Use:   "all-information-by-block-number [page] <block>",
 		Short: "all validators information by block number",
-		Args:  cobra.ExactArgs(2),
+		Long: `
+A page of 100 validators at the block, or every validator with --all
+`,
+		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			noLatest = true
+			if listAll {
+				if len(args) != 1 {
+					return errors.New("only the block argument is taken with --all")
+				}
+				return printAll(client.NewClient(nodeHandler()).IterateAllValidatorInformation(args[0]).Iterator)
+			}
+			if len(args) != 2 {
+				return errors.New("the page and block arguments are required without --all")
+			}
 			page, err := strconv.ParseInt(args[0], 10, 64)
 			if err != nil {
 				return errors.Wrapf(err, "the page argument must be integer, supplied %v", args[0])
@@ -0,0 +1,66 @@
+package keys
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/fakenode"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/sharding"
+	"github.com/harmony-one/go-sdk/pkg/transaction"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/common/denominations"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+func TestCrossShardTransfer(t *testing.T) {
+	network := fakenode.NewNetwork()
+	if err := network.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer network.Close()
+	endpoints, _ := network.Endpoints()
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	from, _ := ks.NewAccount("")
+	to, _ := ks.NewAccount("")
+	ks.Unlock(from, "")
+	sender, receiver := address.ToBech32(from.Address), address.ToBech32(to.Address)
+	network.Fund(sender, 0, new(big.Int).Mul(big.NewInt(10), big.NewInt(denominations.One)))
+
+	routes, err := sharding.Structure(context.Background(), endpoints[0])
+	if err != nil || len(routes) != 2 {
+		t.Fatalf("expected the structure of 2 shards, got %v %v", routes, err)
+	}
+	handler := rpc.NewHTTPHandler(routes[0].HTTP)
+	nonce := transaction.GetNextNonce(context.Background(), sender, handler)
+	controller := transaction.NewController(handler, ks, &from, common.Chain.TestNet, func(c *transaction.Controller) {
+		c.Behavior.ConfirmationWaitTime = 5
+	})
+	err = controller.ExecuteTransaction(
+		context.Background(), nonce, 21000, &receiver, 0, 1, numeric.NewDec(4), numeric.NewDec(1), []byte{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	balances, err := sharding.CheckAllShards(context.Background(), endpoints[0], receiver, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type balance struct {
+		Shard  int         `json:"shard"`
+		Amount json.Number `json:"amount"`
+	}
+	decoded := []balance{}
+	if err := json.Unmarshal([]byte(balances), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != 2 || decoded[1].Amount != "4.000000000000000000" {
+		t.Errorf("expected 4 ONE on shard 1, got %s", balances)
+	}
+}
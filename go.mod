@@ -10,6 +10,7 @@ require (
 	github.com/dop251/goja v0.0.0-20210427212725-462d53687b0d
 	github.com/ethereum/go-ethereum v1.9.23
 	github.com/fatih/color v1.9.0
+	github.com/gorilla/websocket v1.4.2
 	github.com/harmony-one/bls v0.0.7-0.20191214005344-88c23f91a8a9
 	github.com/harmony-one/harmony v1.10.2-0.20210123081216-6993b9ad0ca1
 	github.com/karalabe/usb v0.0.0-20190919080040-51dc0efba356
@@ -19,7 +20,7 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v0.0.5
 	github.com/tyler-smith/go-bip39 v1.0.2
-	github.com/valyala/fasthttp v1.2.0
+	github.com/valyala/fasthttp v1.10.0
 	github.com/valyala/fastjson v1.6.3
 	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a
 )
@@ -42,14 +43,13 @@ require (
 	github.com/golang/protobuf v1.4.3 // indirect
 	github.com/golang/snappy v0.0.2-0.20200707131729-196ae77b8a26 // indirect
 	github.com/google/uuid v1.1.2 // indirect
-	github.com/gorilla/websocket v1.4.2 // indirect
 	github.com/harmony-one/taggedrlp v0.1.4 // indirect
 	github.com/hashicorp/golang-lru v0.5.4 // indirect
 	github.com/huin/goupnp v1.0.0 // indirect
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/ipfs/go-cid v0.0.7 // indirect
 	github.com/jackpal/go-nat-pmp v1.0.2 // indirect
-	github.com/klauspost/compress v1.4.1 // indirect
+	github.com/klauspost/compress v1.8.2 // indirect
 	github.com/klauspost/cpuid v1.2.1 // indirect
 	github.com/libp2p/go-buffer-pool v0.0.2 // indirect
 	github.com/libp2p/go-libp2p-core v0.8.0 // indirect
@@ -30,6 +30,39 @@ var Chain = chainIDList{
 	StressNet:  ChainID{"stress", big.NewInt(5)},
 }
 
+// ethShard0ChainIDs are the eth compatible chain-ids of shard 0 of the known chains, the
+// ones of the other shards following
+var ethShard0ChainIDs = []struct {
+	chain *ChainID
+	value *big.Int
+}{
+	{&Chain.MainNet, big.NewInt(1666600000)},
+	{&Chain.TestNet, big.NewInt(1666700000)},
+	{&Chain.PangaeaNet, big.NewInt(1666800000)},
+	{&Chain.PartnerNet, big.NewInt(1666900000)},
+	{&Chain.StressNet, big.NewInt(1661000000)},
+}
+
+// maxEthShards bounds the shards an eth compatible chain-id is looked for in
+const maxEthShards = 100
+
+// KnownChain returns the known chain the chain-id is of, with the shard its eth
+// compatible chain-ids are of, nil when the chain-id is of no known chain
+func KnownChain(value *big.Int) (*ChainID, *uint32) {
+	for _, chain := range []*ChainID{&Chain.MainNet, &Chain.TestNet, &Chain.PangaeaNet, &Chain.PartnerNet, &Chain.StressNet} {
+		if chain.Value.Cmp(value) == 0 {
+			return chain, nil
+		}
+	}
+	for _, eth := range ethShard0ChainIDs {
+		if offset := new(big.Int).Sub(value, eth.value); offset.Sign() >= 0 && offset.Cmp(big.NewInt(maxEthShards)) < 0 {
+			shardID := uint32(offset.Uint64())
+			return eth.chain, &shardID
+		}
+	}
+	return nil, nil
+}
+
 func (c chainIDList) String() string {
 	s, _ := json.MarshalIndent(c, "", "  ")
 	return string(s)
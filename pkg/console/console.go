@@ -1,6 +1,7 @@
 package console
 
 import (
+	"context"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -597,8 +598,9 @@ func (b *bridge) HmySignTransaction(call jsre.Call) (goja.Value, error) {
 		toP = nil
 	}
 
-	nonce := transaction.GetNextPendingNonce(from, networkHandler)
+	nonce := transaction.GetNextPendingNonce(context.Background(), from, networkHandler)
 	err = ctrlr.SignTransaction(
+		context.Background(),
 		nonce, gLimit,
 		toP,
 		uint32(b.console.shardId), uint32(b.console.shardId),
@@ -683,8 +685,9 @@ func (b *bridge) HmySendTransaction(call jsre.Call) (goja.Value, error) {
 		toP = nil
 	}
 
-	nonce := transaction.GetNextPendingNonce(from, networkHandler)
+	nonce := transaction.GetNextPendingNonce(context.Background(), from, networkHandler)
 	err = ctrlr.ExecuteTransaction(
+		context.Background(),
 		nonce, gLimit,
 		toP,
 		uint32(b.console.shardId), uint32(b.console.shardId),
@@ -0,0 +1,227 @@
+// Package fakenode is an in-memory stand-in for a Harmony network, serving the JSON-RPC
+// methods the SDK and the hmy CLI rely on to send and confirm transactions. Each shard
+// listens on its own address; balances, nonces, receipts and the error sinks are kept
+// in memory and signed raw plain and staking transactions are verified and applied.
+package fakenode
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultHost = "127.0.0.1"
+	// defaultGasPrice is the price reported by gasPrice, 1 Gwei like the real network
+	defaultGasPrice = 1000000000
+)
+
+var (
+	// ErrNotStarted is returned when the endpoints of a Network are asked before Start
+	ErrNotStarted = errors.New("fake network is not started")
+	// ErrUnknownShard is returned for a shard beyond the ShardCount of a Network
+	ErrUnknownShard = errors.New("no such shard in the fake network")
+)
+
+// Network is a set of shards sharing a chain id. Transactions are mined as soon as
+// they are received unless AutoMine is unset, in which case they wait in the pool
+// until Mine is called.
+type Network struct {
+	// Chain is the chain id transactions must be signed for
+	Chain common.ChainID
+	// ShardCount is the number of shards, each one served on its own port
+	ShardCount uint32
+	// Host is the address the shards listen on
+	Host string
+	// Port is the port of shard 0, shard i listening on Port+i; 0 picks free ports
+	Port int
+	// AutoMine applies the transactions as soon as they are received
+	AutoMine bool
+	// GasPrice is the price reported by gasPrice
+	GasPrice *big.Int
+	// LostCXReceipts is the number of cross-shard receipts lost on their way to the
+	// destination shard, resendCx sending them again
+	LostCXReceipts int
+
+	shards    []*shard
+	mu        sync.RWMutex
+	endpoints []string
+	servers   []*http.Server
+}
+
+// NewNetwork creates a stopped network of empty shards, caller can control its
+// configuration via options
+func NewNetwork(options ...func(*Network)) *Network {
+	network := &Network{
+		Chain:      common.Chain.TestNet,
+		ShardCount: 2,
+		Host:       defaultHost,
+		AutoMine:   true,
+		GasPrice:   big.NewInt(defaultGasPrice),
+	}
+	for _, option := range options {
+		option(network)
+	}
+	for i := uint32(0); i < network.ShardCount; i++ {
+		network.shards = append(network.shards, newShard(network, i))
+	}
+	return network
+}
+
+// Start listens on one port per shard and serves the JSON-RPC calls in the background
+func (N *Network) Start() error {
+	N.mu.Lock()
+	defer N.mu.Unlock()
+	listeners := make([]net.Listener, len(N.shards))
+	for i := range N.shards {
+		port := 0
+		if N.Port != 0 {
+			port = N.Port + i
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", N.Host, port))
+		if err != nil {
+			for _, l := range listeners[:i] {
+				l.Close()
+			}
+			return errors.Wrapf(err, "could not listen for shard %d", i)
+		}
+		listeners[i] = listener
+	}
+	N.endpoints = make([]string, len(N.shards))
+	for i, listener := range listeners {
+		N.endpoints[i] = "http://" + listener.Addr().String()
+		server := &http.Server{Handler: &server{network: N, shard: N.shards[i]}}
+		N.servers = append(N.servers, server)
+		go server.Serve(listener)
+	}
+	return nil
+}
+
+// Close stops serving the shards
+func (N *Network) Close() error {
+	N.mu.Lock()
+	defer N.mu.Unlock()
+	var err error
+	for _, server := range N.servers {
+		if e := server.Shutdown(context.Background()); e != nil {
+			err = e
+		}
+	}
+	N.servers = nil
+	return err
+}
+
+// Endpoints returns the URL of each shard, in shard order
+func (N *Network) Endpoints() ([]string, error) {
+	N.mu.RLock()
+	defer N.mu.RUnlock()
+	if N.endpoints == nil {
+		return nil, ErrNotStarted
+	}
+	return append([]string{}, N.endpoints...), nil
+}
+
+// Endpoint returns the URL of the given shard
+func (N *Network) Endpoint(shardID uint32) (string, error) {
+	endpoints, err := N.Endpoints()
+	if err != nil {
+		return "", err
+	}
+	if shardID >= uint32(len(endpoints)) {
+		return "", ErrUnknownShard
+	}
+	return endpoints[shardID], nil
+}
+
+// Fund credits amount, in atto, to the account of addr on the given shard
+func (N *Network) Fund(addr string, shardID uint32, amount *big.Int) error {
+	account, err := parseAddress(addr)
+	if err != nil {
+		return err
+	}
+	s, err := N.shard(shardID)
+	if err != nil {
+		return err
+	}
+	s.credit(account, amount)
+	return nil
+}
+
+// Balance returns the balance, in atto, of addr on the given shard
+func (N *Network) Balance(addr string, shardID uint32) (*big.Int, error) {
+	account, err := parseAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	s, err := N.shard(shardID)
+	if err != nil {
+		return nil, err
+	}
+	return s.balance(account), nil
+}
+
+// Nonce returns the number of transactions of addr mined on the given shard
+func (N *Network) Nonce(addr string, shardID uint32) (uint64, error) {
+	account, err := parseAddress(addr)
+	if err != nil {
+		return 0, err
+	}
+	s, err := N.shard(shardID)
+	if err != nil {
+		return 0, err
+	}
+	return s.nonce(account, false), nil
+}
+
+// Mine applies the transactions waiting in the pool of every shard
+func (N *Network) Mine() {
+	for _, s := range N.shards {
+		s.mine()
+	}
+}
+
+// deliver hands the receipt of a cross-shard transaction to its destination shard,
+// unless it is one of the LostCXReceipts
+func (N *Network) deliver(t transfer) {
+	N.mu.Lock()
+	lost := N.LostCXReceipts > 0
+	if lost {
+		N.LostCXReceipts--
+	}
+	N.mu.Unlock()
+	if lost {
+		return
+	}
+	destination := N.shards[t.toShardID]
+	destination.receive(t)
+	if N.AutoMine {
+		destination.mine()
+	}
+}
+
+func (N *Network) shard(shardID uint32) (*shard, error) {
+	if shardID >= uint32(len(N.shards)) {
+		return nil, errors.Wrapf(ErrUnknownShard, "shard %d", shardID)
+	}
+	return N.shards[shardID], nil
+}
+
+// parseAddress accepts both one1 bech32 and 0x hex addresses
+func parseAddress(addr string) (ethCommon.Address, error) {
+	if strings.HasPrefix(addr, "one1") {
+		return address.Bech32ToAddress(addr)
+	}
+	if !ethCommon.IsHexAddress(addr) {
+		return ethCommon.Address{}, errors.Errorf("invalid address %s", addr)
+	}
+	return ethCommon.HexToAddress(addr), nil
+}
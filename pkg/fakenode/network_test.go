@@ -0,0 +1,239 @@
+package fakenode
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/transaction"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/common/denominations"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+var (
+	oneONE   = big.NewInt(denominations.One)
+	gasPrice = big.NewInt(denominations.Nano)
+)
+
+func startNetwork(t *testing.T, options ...func(*Network)) (*Network, []string) {
+	t.Helper()
+	network := NewNetwork(options...)
+	if err := network.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { network.Close() })
+	endpoints, err := network.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return network, endpoints
+}
+
+func newAccount(t *testing.T, ks *keystore.KeyStore) accounts.Account {
+	t.Helper()
+	acct, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Unlock(acct, ""); err != nil {
+		t.Fatal(err)
+	}
+	return acct
+}
+
+func ones(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), oneONE)
+}
+
+func fee(gas uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+}
+
+func checkBalance(t *testing.T, network *Network, addr string, shardID uint32, expected *big.Int) {
+	t.Helper()
+	balance, err := network.Balance(addr, shardID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(expected) != 0 {
+		t.Errorf("balance of %s on shard %d: expected %s, got %s", addr, shardID, expected, balance)
+	}
+}
+
+func TestCrossShardTransferThroughController(t *testing.T) {
+	network, endpoints := startNetwork(t)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	from, to := newAccount(t, ks), newAccount(t, ks)
+	sender, receiver := address.ToBech32(from.Address), address.ToBech32(to.Address)
+	network.Fund(sender, 0, ones(10))
+
+	controller := transaction.NewController(
+		rpc.NewHTTPHandler(endpoints[0]), ks, &from, common.Chain.TestNet,
+		func(c *transaction.Controller) { c.Behavior.ConfirmationWaitTime = 5 },
+	)
+	err := controller.ExecuteTransaction(
+		context.Background(), 0, 21000, &receiver, 0, 1, numeric.NewDec(3), numeric.NewDec(1), []byte{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if controller.Receipt() == nil {
+		t.Fatal("transaction was not confirmed")
+	}
+	checkBalance(t, network, sender, 0, new(big.Int).Sub(ones(7), fee(21000)))
+	checkBalance(t, network, receiver, 0, big.NewInt(0))
+	checkBalance(t, network, receiver, 1, ones(3))
+	if nonce, _ := network.Nonce(sender, 0); nonce != 1 {
+		t.Errorf("expected nonce 1, got %d", nonce)
+	}
+
+	// The same nonce again is refused and lands in the error sink
+	replay := transaction.NewController(rpc.NewHTTPHandler(endpoints[0]), ks, &from, common.Chain.TestNet)
+	err = replay.ExecuteTransaction(
+		context.Background(), 0, 21000, &receiver, 0, 0, numeric.NewDec(1), numeric.NewDec(1), []byte{},
+	)
+	if err == nil {
+		t.Fatal("expected the replayed nonce to be refused")
+	}
+	sinkErrors, err := transaction.GetError(context.Background(), replay.TransactionInfo().Hash().Hex(), rpc.NewHTTPHandler(endpoints[0]))
+	if err != nil || len(sinkErrors) != 1 {
+		t.Fatalf("expected one error in the sink, got %v %v", sinkErrors, err)
+	}
+}
+
+func TestRejectsForeignChainSignature(t *testing.T) {
+	_, endpoints := startNetwork(t)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	from := newAccount(t, ks)
+	tx := types.NewCrossShardTransaction(0, &from.Address, 0, 0, big.NewInt(1), 21000, gasPrice, nil)
+	signed, err := ks.SignTx(from, tx, common.Chain.MainNet.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := rlp.EncodeToBytes(signed)
+	_, err = rpc.Request(context.Background(), rpc.Method.SendRawTransaction, endpoints[0], []interface{}{hexutil.Encode(raw)})
+	if err == nil {
+		t.Fatal("expected a transaction signed for mainnet to be refused")
+	}
+}
+
+func sendStaking(
+	t *testing.T, ks *keystore.KeyStore, acct accounts.Account, node string, nonce uint64,
+	directive staking.Directive, msg interface{},
+) string {
+	t.Helper()
+	tx, err := staking.NewStakingTransaction(nonce, 10000000, gasPrice, func() (staking.Directive, interface{}) {
+		return directive, msg
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := ks.SignStakingTx(acct, tx, common.Chain.TestNet.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := rlp.EncodeToBytes(signed)
+	reply, err := rpc.Request(context.Background(), rpc.Method.SendRawStakingTransaction, node, []interface{}{hexutil.Encode(raw)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reply["result"].(string)
+}
+
+func TestStakingTransactions(t *testing.T) {
+	network, endpoints := startNetwork(t)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	validator, delegator := newAccount(t, ks), newAccount(t, ks)
+	network.Fund(validator.Address.Hex(), 0, ones(100))
+	network.Fund(delegator.Address.Hex(), 0, ones(100))
+
+	rate := numeric.NewDecWithPrec(1, 1)
+	sendStaking(t, ks, validator, endpoints[0], 0, staking.DirectiveCreateValidator, staking.CreateValidator{
+		ValidatorAddress:   validator.Address,
+		CommissionRates:    staking.CommissionRates{Rate: rate, MaxRate: rate, MaxChangeRate: rate},
+		MinSelfDelegation:  ones(10),
+		MaxTotalDelegation: ones(1000),
+		Amount:             ones(10),
+	})
+	hash := sendStaking(t, ks, delegator, endpoints[0], 0, staking.DirectiveDelegate, staking.Delegate{
+		DelegatorAddress: delegator.Address,
+		ValidatorAddress: validator.Address,
+		Amount:           ones(20),
+	})
+	reply, err := rpc.Request(context.Background(), rpc.Method.GetTransactionReceipt, endpoints[0], []interface{}{hash})
+	if err != nil || reply["result"] == nil {
+		t.Fatalf("expected a receipt for the delegation, got %v %v", reply, err)
+	}
+	balance, _ := network.Balance(delegator.Address.Hex(), 0)
+	if balance.Cmp(ones(80)) >= 0 {
+		t.Errorf("expected the delegation and its fee to be paid, balance is %s", balance)
+	}
+
+	hash = sendStaking(t, ks, delegator, endpoints[0], 1, staking.DirectiveUndelegate, staking.Undelegate{
+		DelegatorAddress: delegator.Address,
+		ValidatorAddress: validator.Address,
+		Amount:           ones(30),
+	})
+	sinkErrors, err := transaction.GetError(context.Background(), hash, rpc.NewHTTPHandler(endpoints[0]))
+	if err != nil || len(sinkErrors) != 1 || sinkErrors[0].StakingDirective == nil {
+		t.Fatalf("expected the undelegation to be in the staking error sink, got %v %v", sinkErrors, err)
+	}
+	if _, err := network.shards[1].submitStakingTransaction(nil); err == nil {
+		t.Error("expected shard 1 to refuse staking transactions")
+	}
+}
+
+func TestReplacementInPool(t *testing.T) {
+	network, endpoints := startNetwork(t, func(n *Network) { n.AutoMine = false })
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	from := newAccount(t, ks)
+	network.Fund(from.Address.Hex(), 0, ones(10))
+
+	send := func(price int64) (string, error) {
+		tx := types.NewCrossShardTransaction(0, &from.Address, 0, 0, big.NewInt(1), 21000, big.NewInt(price), nil)
+		signed, err := ks.SignTx(from, tx, common.Chain.TestNet.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, _ := rlp.EncodeToBytes(signed)
+		reply, err := rpc.Request(context.Background(), rpc.Method.SendRawTransaction, endpoints[0], []interface{}{hexutil.Encode(raw)})
+		if err != nil {
+			return "", err
+		}
+		return reply["result"].(string), nil
+	}
+	first, err := send(1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := send(1000); err == nil {
+		t.Fatal("expected a replacement at the same gas price to be refused")
+	}
+	second, err := send(2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, _ := rpc.Request(context.Background(), rpc.Method.GetTransactionCount, endpoints[0], []interface{}{from.Address.Hex(), "pending"})
+	if reply["result"] != "0x1" {
+		t.Errorf("expected pending nonce 0x1, got %v", reply["result"])
+	}
+	network.Mine()
+	for hash, mined := range map[string]bool{first: false, second: true} {
+		reply, err := rpc.Request(context.Background(), rpc.Method.GetTransactionReceipt, endpoints[0], []interface{}{hash})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (reply["result"] != nil) != mined {
+			t.Errorf("receipt of %s: expected mined %v, got %v", hash, mined, reply["result"])
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package fakenode
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeParseError     = -32700
+	codeGeneric        = -32000
+	// v2Prefix is the namespace answering quantities as numbers instead of hex strings
+	v2Prefix = "hmyv2"
+)
+
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type replyError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// callError is a failure of a call carrying its JSON-RPC error code
+type callError struct {
+	code int
+	err  error
+}
+
+func (e *callError) Error() string {
+	return e.err.Error()
+}
+
+func invalidParams(err error) error {
+	return &callError{codeInvalidParams, err}
+}
+
+// route is an entry of the sharding structure
+type route struct {
+	Current bool   `json:"current"`
+	HTTP    string `json:"http"`
+	ShardID uint32 `json:"shardID"`
+	WS      string `json:"ws"`
+}
+
+type metadata struct {
+	BLSPublicKey []string        `json:"blskey"`
+	Version      string          `json:"version"`
+	NetworkType  string          `json:"network"`
+	ChainConfig  json.RawMessage `json:"chain-config"`
+	IsLeader     bool            `json:"is-leader"`
+	ShardID      uint32          `json:"shard-id"`
+	Role         string          `json:"role"`
+}
+
+// server answers the JSON-RPC calls made to a shard, single or batched, whatever the
+// namespace of the method (hmy, hmyv2 or eth)
+type server struct {
+	network *Network
+	shard   *shard
+}
+
+func (S *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var reply interface{}
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		batch := []request{}
+		if err := json.Unmarshal(body, &batch); err != nil {
+			reply = errorReply(nil, &callError{codeParseError, err})
+		} else {
+			replies := make([]interface{}, len(batch))
+			for i := range batch {
+				replies[i] = S.answer(&batch[i])
+			}
+			reply = replies
+		}
+	} else {
+		call := request{}
+		if err := json.Unmarshal(body, &call); err != nil {
+			reply = errorReply(nil, &callError{codeParseError, err})
+		} else {
+			reply = S.answer(&call)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+func (S *server) answer(call *request) interface{} {
+	result, err := S.dispatch(call)
+	if err != nil {
+		return errorReply(call.ID, err)
+	}
+	return map[string]interface{}{
+		"jsonrpc": common.JSONRPCVersion,
+		"id":      call.ID,
+		"result":  result,
+	}
+}
+
+func errorReply(id json.RawMessage, err error) interface{} {
+	code := codeGeneric
+	var cErr *callError
+	if errors.As(err, &cErr) {
+		code = cErr.code
+	}
+	return map[string]interface{}{
+		"jsonrpc": common.JSONRPCVersion,
+		"id":      id,
+		"error":   replyError{code, err.Error()},
+	}
+}
+
+func (S *server) dispatch(call *request) (interface{}, error) {
+	separator := strings.Index(call.Method, "_")
+	if separator < 0 {
+		return nil, &callError{codeMethodNotFound, errors.Errorf("the method %s does not exist/is not available", call.Method)}
+	}
+	prefix, name := call.Method[:separator], call.Method[separator+1:]
+	quantity := func(n *big.Int) interface{} {
+		if prefix == v2Prefix {
+			return n
+		}
+		return (*hexutil.Big)(n)
+	}
+	switch name {
+	case "getShardingStructure":
+		return S.structure()
+	case "getShardID":
+		return S.shard.id, nil
+	case "blockNumber":
+		return quantity(new(big.Int).SetUint64(S.shard.currentBlock())), nil
+	case "syncing":
+		return false, nil
+	case "gasPrice":
+		return quantity(S.network.GasPrice), nil
+	case "protocolVersion":
+		return quantity(big.NewInt(1)), nil
+	case "getNodeMetadata":
+		return S.metadata(), nil
+	case "getBalance":
+		account, err := call.address(0)
+		if err != nil {
+			return nil, err
+		}
+		return quantity(S.shard.balance(account)), nil
+	case "getTransactionCount":
+		account, err := call.address(0)
+		if err != nil {
+			return nil, err
+		}
+		block := ""
+		if len(call.Params) > 1 {
+			json.Unmarshal(call.Params[1], &block)
+		}
+		nonce := S.shard.nonce(account, block == "pending")
+		return quantity(new(big.Int).SetUint64(nonce)), nil
+	case "sendRawTransaction", "sendRawStakingTransaction":
+		raw, err := call.bytes(0)
+		if err != nil {
+			return nil, err
+		}
+		submit := S.shard.submitTransaction
+		if name == "sendRawStakingTransaction" {
+			submit = S.shard.submitStakingTransaction
+		}
+		hash, err := submit(raw)
+		if err != nil {
+			return nil, err
+		}
+		return hash, nil
+	case "getTransactionReceipt":
+		hash, err := call.hash(0)
+		if err != nil {
+			return nil, err
+		}
+		return S.shard.receipt(hash), nil
+	case "getTransactionByHash":
+		hash, err := call.hash(0)
+		if err != nil {
+			return nil, err
+		}
+		return S.shard.transaction(hash), nil
+	case "pendingTransactions":
+		return S.shard.pendingTransactions(), nil
+	case "getCXReceiptByHash":
+		hash, err := call.hash(0)
+		if err != nil {
+			return nil, err
+		}
+		return S.shard.cxReceipt(hash), nil
+	case "getPendingCXReceipts":
+		return S.shard.pendingCXReceipts(), nil
+	case "resendCx":
+		hash, err := call.hash(0)
+		if err != nil {
+			return nil, err
+		}
+		return S.shard.resend(hash), nil
+	case "getCurrentTransactionErrorSink":
+		txErrors, _ := S.shard.errorSinks()
+		return txErrors, nil
+	case "getCurrentStakingErrorSink":
+		_, stakingErrors := S.shard.errorSinks()
+		return stakingErrors, nil
+	}
+	return nil, &callError{codeMethodNotFound, errors.Errorf("the method %s does not exist/is not available", call.Method)}
+}
+
+func (S *server) structure() ([]route, error) {
+	endpoints, err := S.network.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+	routes := make([]route, len(endpoints))
+	for i, endpoint := range endpoints {
+		routes[i] = route{Current: uint32(i) == S.shard.id, HTTP: endpoint, ShardID: uint32(i)}
+	}
+	return routes, nil
+}
+
+func (S *server) metadata() metadata {
+	chainConfig, _ := json.Marshal(map[string]interface{}{"chain-id": S.network.Chain.Value})
+	return metadata{
+		BLSPublicKey: []string{},
+		Version:      "fake-node",
+		NetworkType:  S.network.Chain.Name,
+		ChainConfig:  chainConfig,
+		IsLeader:     true,
+		ShardID:      S.shard.id,
+		Role:         "Validator",
+	}
+}
+
+func (r *request) param(i int, v interface{}) error {
+	if i >= len(r.Params) {
+		return invalidParams(errors.Errorf("missing value for required argument %d", i))
+	}
+	if err := json.Unmarshal(r.Params[i], v); err != nil {
+		return invalidParams(errors.Wrapf(err, "invalid argument %d", i))
+	}
+	return nil
+}
+
+func (r *request) address(i int) (ethCommon.Address, error) {
+	var addr string
+	if err := r.param(i, &addr); err != nil {
+		return ethCommon.Address{}, err
+	}
+	account, err := parseAddress(addr)
+	if err != nil {
+		return ethCommon.Address{}, invalidParams(err)
+	}
+	return account, nil
+}
+
+func (r *request) bytes(i int) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := r.param(i, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func (r *request) hash(i int) (ethCommon.Hash, error) {
+	var hash string
+	if err := r.param(i, &hash); err != nil {
+		return ethCommon.Hash{}, err
+	}
+	if len(strings.TrimPrefix(hash, "0x")) != 2*ethCommon.HashLength {
+		return ethCommon.Hash{}, invalidParams(fmt.Errorf("invalid transaction hash %s", hash))
+	}
+	return ethCommon.HexToHash(hash), nil
+}
@@ -0,0 +1,635 @@
+package fakenode
+
+import (
+	"encoding/binary"
+	"math/big"
+	"sync"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/core/types"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	errWrongShard           = errors.New("transaction sent to another shard")
+	errNoSuchDestination    = errors.New("destination shard does not exist")
+	errContractCreation     = errors.New("contract creation is not supported by the fake network")
+	errStakingOffBeacon     = errors.New("staking transactions are only accepted by shard 0")
+	errSignerMismatch       = errors.New("staking message is not signed by its sender")
+	errValidatorExists      = errors.New("validator already exists")
+	errNoSuchValidator      = errors.New("validator does not exist")
+	errSelfDelegationTooLow = errors.New("amount can not be less than min-self-delegation")
+	errUndelegateTooMuch    = errors.New("insufficient delegation to undelegate")
+)
+
+// pending is a transaction verified and waiting in the pool, exactly one of plain and
+// stake is set, msg being the decoded message of stake
+type pending struct {
+	hash     ethCommon.Hash
+	from     ethCommon.Address
+	nonce    uint64
+	gasPrice *big.Int
+	plain    *types.Transaction
+	stake    *staking.StakingTransaction
+	msg      interface{}
+}
+
+// transfer is the receipt of a cross-shard transaction, credited by the destination
+// shard the next time it mines
+type transfer struct {
+	hash      ethCommon.Hash
+	from      ethCommon.Address
+	shardID   uint32
+	toShardID uint32
+	to        ethCommon.Address
+	amount    *big.Int
+}
+
+// cxReceipt is a transfer credited by the destination shard, as served by getCXReceiptByHash
+type cxReceipt struct {
+	BlockHash   ethCommon.Hash `json:"blockHash"`
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	TxHash      ethCommon.Hash `json:"hash"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	ShardID     uint32         `json:"shardID"`
+	ToShardID   uint32         `json:"toShardID"`
+	Amount      *hexutil.Big   `json:"value"`
+}
+
+type delegation struct {
+	delegator ethCommon.Address
+	validator ethCommon.Address
+}
+
+// sinkError is an entry of the error sinks, as served by the nodes
+type sinkError struct {
+	TxHashID             string  `json:"tx-hash-id"`
+	StakingDirective     *string `json:"directive-kind,omitempty"`
+	ErrMessage           string  `json:"error-message"`
+	TimestampOfRejection int64   `json:"time-at-rejection"`
+}
+
+type receiptBase struct {
+	BlockHash         ethCommon.Hash    `json:"blockHash"`
+	TransactionHash   ethCommon.Hash    `json:"transactionHash"`
+	BlockNumber       hexutil.Uint64    `json:"blockNumber"`
+	TransactionIndex  hexutil.Uint64    `json:"transactionIndex"`
+	GasUsed           hexutil.Uint64    `json:"gasUsed"`
+	CumulativeGasUsed hexutil.Uint64    `json:"cumulativeGasUsed"`
+	ContractAddress   ethCommon.Address `json:"contractAddress"`
+	Logs              []interface{}     `json:"logs"`
+	LogsBloom         ethTypes.Bloom    `json:"logsBloom"`
+	Root              hexutil.Bytes     `json:"root"`
+	Status            hexutil.Uint      `json:"status"`
+}
+
+type plainReceipt struct {
+	receiptBase
+	ShardID   uint32 `json:"shardID"`
+	ToShardID uint32 `json:"toShardID"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+}
+
+// rpcTransaction is a plain transaction as served by getTransactionByHash and
+// pendingTransactions, BlockNumber being nil while it waits in the pool
+type rpcTransaction struct {
+	BlockHash        ethCommon.Hash  `json:"blockHash"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber"`
+	From             string          `json:"from"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Hash             ethCommon.Hash  `json:"hash"`
+	Input            hexutil.Bytes   `json:"input"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	To               string          `json:"to"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	Value            *hexutil.Big    `json:"value"`
+	ShardID          uint32          `json:"shardID"`
+	ToShardID        uint32          `json:"toShardID"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+}
+
+func newRPCTransaction(p *pending) rpcTransaction {
+	tx := p.plain
+	v, r, sig := tx.RawSignatureValues()
+	return rpcTransaction{
+		From:      address.ToBech32(p.from),
+		Gas:       hexutil.Uint64(tx.GasLimit()),
+		GasPrice:  (*hexutil.Big)(tx.GasPrice()),
+		Hash:      p.hash,
+		Input:     tx.Data(),
+		Nonce:     hexutil.Uint64(tx.Nonce()),
+		To:        address.ToBech32(*tx.To()),
+		Value:     (*hexutil.Big)(tx.Value()),
+		ShardID:   tx.ShardID(),
+		ToShardID: tx.ToShardID(),
+		V:         (*hexutil.Big)(v),
+		R:         (*hexutil.Big)(r),
+		S:         (*hexutil.Big)(sig),
+	}
+}
+
+type stakingReceipt struct {
+	receiptBase
+	Sender string            `json:"sender"`
+	Type   staking.Directive `json:"type"`
+}
+
+// shard is the state of a single shard, one block being mined per transaction
+type shard struct {
+	id      uint32
+	network *Network
+
+	mu            sync.Mutex
+	blockNumber   uint64
+	balances      map[ethCommon.Address]*big.Int
+	nonces        map[ethCommon.Address]uint64
+	pool          map[ethCommon.Address]map[uint64]*pending
+	receipts      map[ethCommon.Hash]interface{}
+	transactions  map[ethCommon.Hash]rpcTransaction
+	validators    map[ethCommon.Address]bool
+	delegations   map[delegation]*big.Int
+	txErrors      []sinkError
+	stakingErrors []sinkError
+	// sent are the transfers to other shards, kept for resendCx
+	sent map[ethCommon.Hash]transfer
+	// incoming are the transfers received from other shards and not credited yet
+	incoming   []transfer
+	cxReceipts map[ethCommon.Hash]cxReceipt
+}
+
+func newShard(network *Network, id uint32) *shard {
+	return &shard{
+		id:           id,
+		network:      network,
+		balances:     map[ethCommon.Address]*big.Int{},
+		nonces:       map[ethCommon.Address]uint64{},
+		pool:         map[ethCommon.Address]map[uint64]*pending{},
+		receipts:     map[ethCommon.Hash]interface{}{},
+		transactions: map[ethCommon.Hash]rpcTransaction{},
+		validators:   map[ethCommon.Address]bool{},
+		delegations:  map[delegation]*big.Int{},
+		sent:         map[ethCommon.Hash]transfer{},
+		cxReceipts:   map[ethCommon.Hash]cxReceipt{},
+	}
+}
+
+func (s *shard) credit(account ethCommon.Address, amount *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[account] = new(big.Int).Add(s.balanceLocked(account), amount)
+}
+
+func (s *shard) balance(account ethCommon.Address) *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return new(big.Int).Set(s.balanceLocked(account))
+}
+
+func (s *shard) balanceLocked(account ethCommon.Address) *big.Int {
+	if balance, ok := s.balances[account]; ok {
+		return balance
+	}
+	return big.NewInt(0)
+}
+
+// nonce is the next nonce of account, counting the transactions ready in the pool
+// when withPool is set
+func (s *shard) nonce(account ethCommon.Address, withPool bool) uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	nonce := s.nonces[account]
+	if withPool {
+		for s.pool[account][nonce] != nil {
+			nonce++
+		}
+	}
+	return nonce
+}
+
+func (s *shard) currentBlock() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blockNumber
+}
+
+func (s *shard) receipt(hash ethCommon.Hash) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.receipts[hash]
+}
+
+// transaction is the mined plain transaction hash, nil when it is not in a block
+func (s *shard) transaction(hash ethCommon.Hash) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tx, ok := s.transactions[hash]; ok {
+		return tx
+	}
+	return nil
+}
+
+// pendingTransactions lists the plain transactions of the pool
+func (s *shard) pendingTransactions() []rpcTransaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txs := []rpcTransaction{}
+	for _, queue := range s.pool {
+		for _, p := range queue {
+			if p.plain != nil {
+				txs = append(txs, newRPCTransaction(p))
+			}
+		}
+	}
+	return txs
+}
+
+// cxReceipt is the credited transfer hash, nil when it is not in a block
+func (s *shard) cxReceipt(hash ethCommon.Hash) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cx, ok := s.cxReceipts[hash]; ok {
+		return cx
+	}
+	return nil
+}
+
+// pendingCXReceipts lists the transfers received and not credited yet, each one in
+// its own proof as nodes serve them
+func (s *shard) pendingCXReceipts() []types.CXReceiptsProof {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	proofs := []types.CXReceiptsProof{}
+	for _, t := range s.incoming {
+		to := t.to
+		proofs = append(proofs, types.CXReceiptsProof{Receipts: types.CXReceipts{{
+			TxHash: t.hash, From: t.from, To: &to, ShardID: t.shardID, ToShardID: t.toShardID, Amount: t.amount,
+		}}})
+	}
+	return proofs
+}
+
+// receive queues a transfer from another shard, unless it was already received
+func (s *shard) receive(t transfer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cxReceipts[t.hash]; ok {
+		return
+	}
+	for _, queued := range s.incoming {
+		if queued.hash == t.hash {
+			return
+		}
+	}
+	s.incoming = append(s.incoming, t)
+}
+
+// resend delivers the transfer of the transaction hash again, false when it is not a
+// cross-shard transaction of the shard
+func (s *shard) resend(hash ethCommon.Hash) bool {
+	s.mu.Lock()
+	t, ok := s.sent[hash]
+	s.mu.Unlock()
+	if ok {
+		s.network.deliver(t)
+	}
+	return ok
+}
+
+func (s *shard) errorSinks() ([]sinkError, []sinkError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sinkError{}, s.txErrors...), append([]sinkError{}, s.stakingErrors...)
+}
+
+// submitTransaction verifies a signed raw plain transaction and adds it to the pool
+func (s *shard) submitTransaction(raw []byte) (ethCommon.Hash, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return ethCommon.Hash{}, errors.Wrap(err, "could not decode transaction")
+	}
+	from, err := types.Sender(types.NewEIP155Signer(s.network.Chain.Value), tx)
+	if err != nil {
+		return ethCommon.Hash{}, errors.Wrap(err, "invalid transaction signature")
+	}
+	p := &pending{hash: tx.Hash(), from: from, nonce: tx.Nonce(), gasPrice: tx.GasPrice(), plain: tx}
+	switch {
+	case tx.ShardID() != s.id:
+		return p.hash, s.reject(p, errors.Wrapf(errWrongShard, "shard %d", tx.ShardID()))
+	case tx.ToShardID() >= s.network.ShardCount:
+		return p.hash, s.reject(p, errors.Wrapf(errNoSuchDestination, "shard %d", tx.ToShardID()))
+	case tx.To() == nil:
+		return p.hash, s.reject(p, errContractCreation)
+	}
+	gas, err := core.IntrinsicGas(tx.Data(), false, true, true, false)
+	if err != nil {
+		return p.hash, s.reject(p, err)
+	}
+	if tx.GasLimit() < gas {
+		return p.hash, s.reject(p, core.ErrIntrinsicGas)
+	}
+	cost, _ := tx.Cost()
+	return p.hash, s.add(p, cost)
+}
+
+// submitStakingTransaction verifies a signed raw staking transaction and adds it to the pool
+func (s *shard) submitStakingTransaction(raw []byte) (ethCommon.Hash, error) {
+	tx := new(staking.StakingTransaction)
+	if err := rlp.DecodeBytes(raw, tx); err != nil {
+		return ethCommon.Hash{}, errors.Wrap(err, "could not decode staking transaction")
+	}
+	from, err := staking.Sender(staking.NewEIP155Signer(s.network.Chain.Value), tx)
+	if err != nil {
+		return ethCommon.Hash{}, errors.Wrap(err, "invalid staking transaction signature")
+	}
+	p := &pending{hash: tx.Hash(), from: from, nonce: tx.Nonce(), gasPrice: tx.GasPrice(), stake: tx}
+	if s.id != 0 {
+		return p.hash, s.reject(p, errStakingOffBeacon)
+	}
+	// The message of a decoded transaction is left as a raw RLP list
+	if p.msg, err = staking.RLPDecodeStakeMsg(tx.Data(), tx.StakingType()); err != nil {
+		return p.hash, s.reject(p, err)
+	}
+	if err := checkStakingSender(from, p.msg); err != nil {
+		return p.hash, s.reject(p, err)
+	}
+	gas, err := stakingGas(tx)
+	if err != nil {
+		return p.hash, s.reject(p, err)
+	}
+	if tx.GasLimit() < gas {
+		return p.hash, s.reject(p, core.ErrIntrinsicGas)
+	}
+	cost, err := tx.Cost()
+	if err != nil {
+		return p.hash, s.reject(p, err)
+	}
+	return p.hash, s.add(p, cost)
+}
+
+// add puts a verified transaction in the pool, replacing the one with the same nonce
+// if it pays a higher gas price, and mines it right away in AutoMine
+func (s *shard) add(p *pending, cost *big.Int) error {
+	s.mu.Lock()
+	if p.nonce < s.nonces[p.from] {
+		s.mu.Unlock()
+		return s.reject(p, core.ErrNonceTooLow)
+	}
+	if existing := s.pool[p.from][p.nonce]; existing != nil && p.gasPrice.Cmp(existing.gasPrice) <= 0 {
+		s.mu.Unlock()
+		return s.reject(p, core.ErrReplaceUnderpriced)
+	}
+	if cost.Cmp(s.balanceLocked(p.from)) > 0 {
+		s.mu.Unlock()
+		return s.reject(p, core.ErrInsufficientFunds)
+	}
+	if s.pool[p.from] == nil {
+		s.pool[p.from] = map[uint64]*pending{}
+	}
+	s.pool[p.from][p.nonce] = p
+	s.mu.Unlock()
+	if s.network.AutoMine {
+		s.mine()
+	}
+	return nil
+}
+
+// reject records the failure of p in the error sink of its kind and returns it
+func (s *shard) reject(p *pending, err error) error {
+	entry := sinkError{
+		TxHashID:             p.hash.Hex(),
+		ErrMessage:           err.Error(),
+		TimestampOfRejection: time.Now().Unix(),
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p.stake != nil {
+		directive := p.stake.StakingType().String()
+		entry.StakingDirective = &directive
+		s.stakingErrors = append(s.stakingErrors, entry)
+	} else {
+		s.txErrors = append(s.txErrors, entry)
+	}
+	return err
+}
+
+// mine credits the transfers received and applies the transactions of the pool
+// following their nonce, until none is ready
+func (s *shard) mine() {
+	var transfers []transfer
+	var rejected []*pending
+	var failures []error
+	s.mu.Lock()
+	for _, t := range s.incoming {
+		s.applyTransfer(t)
+	}
+	s.incoming = nil
+	for progress := true; progress; {
+		progress = false
+		for from, queue := range s.pool {
+			p := queue[s.nonces[from]]
+			if p == nil {
+				continue
+			}
+			progress = true
+			if delete(queue, p.nonce); len(queue) == 0 {
+				delete(s.pool, from)
+			}
+			var t *transfer
+			var err error
+			if p.plain != nil {
+				t, err = s.applyTransaction(p)
+			} else {
+				err = s.applyStakingTransaction(p)
+			}
+			if err != nil {
+				rejected, failures = append(rejected, p), append(failures, err)
+			} else if t != nil {
+				transfers = append(transfers, *t)
+			}
+		}
+	}
+	s.mu.Unlock()
+	for i, p := range rejected {
+		s.reject(p, failures[i])
+	}
+	for _, t := range transfers {
+		s.network.deliver(t)
+	}
+}
+
+// applyTransfer credits a transfer received from another shard, in a block of its own
+func (s *shard) applyTransfer(t transfer) {
+	s.balances[t.to] = new(big.Int).Add(s.balanceLocked(t.to), t.amount)
+	base := s.nextBlock(t.hash, 0)
+	s.cxReceipts[t.hash] = cxReceipt{
+		BlockHash:   base.BlockHash,
+		BlockNumber: base.BlockNumber,
+		TxHash:      t.hash,
+		From:        address.ToBech32(t.from),
+		To:          address.ToBech32(t.to),
+		ShardID:     t.shardID,
+		ToShardID:   t.toShardID,
+		Amount:      (*hexutil.Big)(t.amount),
+	}
+}
+
+func (s *shard) applyTransaction(p *pending) (*transfer, error) {
+	tx := p.plain
+	gasUsed, _ := core.IntrinsicGas(tx.Data(), false, true, true, false)
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), tx.GasPrice())
+	if err := s.debit(p.from, new(big.Int).Add(tx.Value(), fee)); err != nil {
+		return nil, err
+	}
+	var t *transfer
+	if tx.ToShardID() == s.id {
+		s.balances[*tx.To()] = new(big.Int).Add(s.balanceLocked(*tx.To()), tx.Value())
+	} else {
+		t = &transfer{p.hash, p.from, tx.ShardID(), tx.ToShardID(), *tx.To(), tx.Value()}
+		s.sent[p.hash] = *t
+	}
+	s.nonces[p.from]++
+	base := s.nextBlock(p.hash, gasUsed)
+	s.receipts[p.hash] = plainReceipt{
+		receiptBase: base,
+		ShardID:     tx.ShardID(),
+		ToShardID:   tx.ToShardID(),
+		From:        address.ToBech32(p.from),
+		To:          address.ToBech32(*tx.To()),
+	}
+	mined := newRPCTransaction(p)
+	mined.BlockHash, mined.BlockNumber = base.BlockHash, &base.BlockNumber
+	s.transactions[p.hash] = mined
+	return t, nil
+}
+
+// applyStakingTransaction keeps the stake of each delegation, the amount of an
+// undelegation is not paid back since it would stay locked on a real network
+func (s *shard) applyStakingTransaction(p *pending) error {
+	tx := p.stake
+	gasUsed, _ := stakingGas(tx)
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasUsed), tx.GasPrice())
+	stake := big.NewInt(0)
+	switch msg := p.msg.(type) {
+	case *staking.CreateValidator:
+		if s.validators[msg.ValidatorAddress] {
+			return errValidatorExists
+		}
+		if msg.MinSelfDelegation != nil && msg.Amount.Cmp(msg.MinSelfDelegation) < 0 {
+			return errSelfDelegationTooLow
+		}
+		stake = msg.Amount
+	case *staking.EditValidator:
+		if !s.validators[msg.ValidatorAddress] {
+			return errNoSuchValidator
+		}
+	case *staking.Delegate:
+		if !s.validators[msg.ValidatorAddress] {
+			return errNoSuchValidator
+		}
+		stake = msg.Amount
+	case *staking.Undelegate:
+		key := delegation{msg.DelegatorAddress, msg.ValidatorAddress}
+		if s.delegations[key] == nil || s.delegations[key].Cmp(msg.Amount) < 0 {
+			return errUndelegateTooMuch
+		}
+	}
+	if err := s.debit(p.from, new(big.Int).Add(stake, fee)); err != nil {
+		return err
+	}
+	switch msg := p.msg.(type) {
+	case *staking.CreateValidator:
+		s.validators[msg.ValidatorAddress] = true
+		s.delegate(delegation{msg.ValidatorAddress, msg.ValidatorAddress}, msg.Amount)
+	case *staking.Delegate:
+		s.delegate(delegation{msg.DelegatorAddress, msg.ValidatorAddress}, msg.Amount)
+	case *staking.Undelegate:
+		s.delegate(delegation{msg.DelegatorAddress, msg.ValidatorAddress}, new(big.Int).Neg(msg.Amount))
+	}
+	s.nonces[p.from]++
+	s.receipts[p.hash] = stakingReceipt{
+		receiptBase: s.nextBlock(p.hash, gasUsed),
+		Sender:      address.ToBech32(p.from),
+		Type:        tx.StakingType(),
+	}
+	return nil
+}
+
+func (s *shard) debit(account ethCommon.Address, amount *big.Int) error {
+	balance := s.balanceLocked(account)
+	if amount.Cmp(balance) > 0 {
+		return core.ErrInsufficientFunds
+	}
+	s.balances[account] = new(big.Int).Sub(balance, amount)
+	return nil
+}
+
+func (s *shard) delegate(key delegation, amount *big.Int) {
+	current := s.delegations[key]
+	if current == nil {
+		current = big.NewInt(0)
+	}
+	s.delegations[key] = new(big.Int).Add(current, amount)
+}
+
+// nextBlock mines a block holding the single transaction hash
+func (s *shard) nextBlock(hash ethCommon.Hash, gasUsed uint64) receiptBase {
+	s.blockNumber++
+	seed := make([]byte, 12)
+	binary.BigEndian.PutUint32(seed, s.id)
+	binary.BigEndian.PutUint64(seed[4:], s.blockNumber)
+	return receiptBase{
+		BlockHash:         crypto.Keccak256Hash(seed),
+		TransactionHash:   hash,
+		BlockNumber:       hexutil.Uint64(s.blockNumber),
+		GasUsed:           hexutil.Uint64(gasUsed),
+		CumulativeGasUsed: hexutil.Uint64(gasUsed),
+		Logs:              []interface{}{},
+		Root:              hexutil.Bytes{},
+		Status:            hexutil.Uint(types.ReceiptStatusSuccessful),
+	}
+}
+
+func stakingGas(tx *staking.StakingTransaction) (uint64, error) {
+	data, err := tx.RLPEncodeStakeMsg()
+	if err != nil {
+		return 0, err
+	}
+	isCreateValidator := tx.StakingType() == staking.DirectiveCreateValidator
+	return core.IntrinsicGas(data, false, true, true, isCreateValidator)
+}
+
+// checkStakingSender makes sure the account acting in the message is the signer
+func checkStakingSender(from ethCommon.Address, msg interface{}) error {
+	var sender ethCommon.Address
+	switch m := msg.(type) {
+	case *staking.CreateValidator:
+		sender = m.ValidatorAddress
+	case *staking.EditValidator:
+		sender = m.ValidatorAddress
+	case *staking.Delegate:
+		sender = m.DelegatorAddress
+	case *staking.Undelegate:
+		sender = m.DelegatorAddress
+	case *staking.CollectRewards:
+		sender = m.DelegatorAddress
+	default:
+		return staking.ErrInvalidStakingKind
+	}
+	if sender != from {
+		return errSignerMismatch
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package keys
 
 import (
 	"bufio"
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/md5"
@@ -335,7 +336,7 @@ func decryptRaw(data []byte, passphrase string) ([]byte, error) {
 }
 
 func genBlsKeyForNode(blsKeys []*BlsKey, node string, shardID uint32) ([]*BlsKey, int, error) {
-	shardingStructure, err := sharding.Structure(node)
+	shardingStructure, err := sharding.Structure(context.Background(), node)
 	if err != nil {
 		return blsKeys, -1, err
 	}
@@ -0,0 +1,115 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrMissingBatchReply is set on a BatchElem the node did not answer
+	ErrMissingBatchReply = errors.New("no reply for this call in the batch response")
+)
+
+// BatchElem is a single call of a batch, Reply and Error are filled in once the batch is sent
+type BatchElem struct {
+	Method string
+	Params []interface{}
+	Reply  Reply
+	Error  error
+}
+
+// Batcher is implemented by the messengers able to send many calls in a single round-trip
+type Batcher interface {
+	SendBatch(context.Context, []BatchElem) error
+}
+
+// SendBatch sends the calls through messenger as one batch if it is a Batcher,
+// or one by one otherwise. The returned error is only for the batch as a whole,
+// the outcome of each call is in its BatchElem.
+func SendBatch(ctx context.Context, messenger T, batch []BatchElem) error {
+	if batcher, ok := messenger.(Batcher); ok {
+		return batcher.SendBatch(ctx, batch)
+	}
+	for i := range batch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		batch[i].Reply, batch[i].Error = messenger.SendRPC(ctx, batch[i].Method, batch[i].Params)
+	}
+	return nil
+}
+
+// SendBatch sends all the calls as one JSON-RPC array
+func (M *HTTPMessenger) SendBatch(ctx context.Context, batch []BatchElem) error {
+	return M.Client.BatchRequest(ctx, M.node, batch)
+}
+
+// BatchRequest posts the calls as one JSON-RPC array to node and matches the replies back by id
+func BatchRequest(ctx context.Context, node string, batch []BatchElem) error {
+	return DefaultHTTPClient.BatchRequest(ctx, node, batch)
+}
+
+// BatchRequest posts the calls as one JSON-RPC array to node and matches the replies back by id
+func (C *HTTPClient) BatchRequest(ctx context.Context, node string, batch []BatchElem) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	requests := make([]map[string]interface{}, len(batch))
+	for i, elem := range batch {
+		params := elem.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		requests[i] = map[string]interface{}{
+			"jsonrpc": common.JSONRPCVersion,
+			"id":      strconv.Itoa(i),
+			"method":  elem.Method,
+			"params":  params,
+		}
+	}
+	requestBody, _ := json.Marshal(requests)
+	rawReply, err := C.postRequest(ctx, node, requestBody)
+	if err != nil {
+		return err
+	}
+	var replies []Reply
+	if err := json.Unmarshal(rawReply, &replies); err != nil {
+		// A node rejecting the batch as a whole answers with a single error object
+		single := Reply{}
+		if json.Unmarshal(rawReply, &single) == nil {
+			if err := replyError(single); err != nil {
+				return err
+			}
+		}
+		return errors.Wrap(err, "could not decode batch response")
+	}
+	answered := make([]bool, len(batch))
+	for _, reply := range replies {
+		index := -1
+		switch id := reply["id"].(type) {
+		case string:
+			if i, err := strconv.Atoi(id); err == nil {
+				index = i
+			}
+		case float64:
+			index = int(id)
+		}
+		if index < 0 || index >= len(batch) {
+			continue
+		}
+		answered[index] = true
+		if batch[index].Error = replyError(reply); batch[index].Error == nil {
+			batch[index].Reply = reply
+		}
+	}
+	for i := range batch {
+		if !answered[i] {
+			batch[i].Error = ErrMissingBatchReply
+		}
+	}
+	return nil
+}
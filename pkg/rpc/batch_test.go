@@ -0,0 +1,55 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&requests); err != nil {
+			t.Error(err)
+			return
+		}
+		replies := []map[string]interface{}{}
+		// Reply in reverse order and skip the last call to check matching by id
+		for i := len(requests) - 2; i >= 0; i-- {
+			req := requests[i]
+			if req["method"] == Method.GetBalance {
+				replies = append(replies, map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x1"})
+			} else {
+				replies = append(replies, map[string]interface{}{
+					"jsonrpc": "2.0", "id": req["id"],
+					"error": map[string]interface{}{"code": -32601, "message": "not found"},
+				})
+			}
+		}
+		json.NewEncoder(w).Encode(replies)
+	}))
+	defer server.Close()
+
+	batch := []BatchElem{
+		{Method: Method.GetBalance, Params: []interface{}{"one1", "latest"}},
+		{Method: Method.GetWork},
+		{Method: Method.GetBalance, Params: []interface{}{"one2", "latest"}},
+		{Method: Method.GetBalance, Params: []interface{}{"one3", "latest"}},
+	}
+	if err := NewHTTPHandler(server.URL).SendBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	for _, i := range []int{0, 2} {
+		if batch[i].Error != nil || batch[i].Reply["result"] != "0x1" {
+			t.Errorf("call %d: unexpected reply %v, error %v", i, batch[i].Reply, batch[i].Error)
+		}
+	}
+	if batch[1].Error == nil {
+		t.Error("call 1: expected an error for an unknown method")
+	}
+	if batch[3].Error != ErrMissingBatchReply {
+		t.Errorf("call 3: expected a missing reply, got %v", batch[3].Error)
+	}
+}
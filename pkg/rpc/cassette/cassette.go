@@ -0,0 +1,243 @@
+// Package cassette records the traffic of an rpc.T to a file and serves it back offline,
+// so that flows built on the SDK can be tested without a live node.
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sync"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrUnmatchedCall is returned by a Replayer for a call absent from its cassette
+	ErrUnmatchedCall = errors.New("call not found in cassette")
+)
+
+// Interaction is a call and its outcome, Reply is the whole JSON-RPC reply as received
+type Interaction struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Reply  json.RawMessage `json:"reply,omitempty"`
+	Error  *RecordedError  `json:"error,omitempty"`
+}
+
+// ErrorKind tells which error type a RecordedError is replayed as
+type ErrorKind string
+
+const (
+	// RPCErrorKind is the error member of a reply, replayed as an *rpc.RPCError
+	RPCErrorKind ErrorKind = "rpc"
+	// HTTPErrorKind is an HTTP status other than 200, replayed as an *rpc.HTTPError
+	HTTPErrorKind ErrorKind = "http"
+	// DeadlineErrorKind is a call cut by its deadline, replayed as context.DeadlineExceeded
+	DeadlineErrorKind ErrorKind = "deadline"
+	// CanceledErrorKind is a call cut by its cancellation, replayed as context.Canceled
+	CanceledErrorKind ErrorKind = "canceled"
+	// TransportErrorKind is any other failure, replayed with its message only
+	TransportErrorKind ErrorKind = "transport"
+)
+
+// RecordedError is a failed call, keeping what it takes to replay it with its type
+type RecordedError struct {
+	Kind       ErrorKind   `json:"kind"`
+	Code       int         `json:"code,omitempty"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	StatusCode int         `json:"status-code,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+type cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+func recordError(err error) *RecordedError {
+	var rpcErr *rpc.RPCError
+	var httpErr *rpc.HTTPError
+	switch {
+	case errors.As(err, &rpcErr):
+		return &RecordedError{Kind: RPCErrorKind, Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+	case errors.As(err, &httpErr):
+		return &RecordedError{
+			Kind: HTTPErrorKind, Message: err.Error(), StatusCode: httpErr.StatusCode, Body: httpErr.Body,
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &RecordedError{Kind: DeadlineErrorKind, Message: err.Error()}
+	case errors.Is(err, context.Canceled):
+		return &RecordedError{Kind: CanceledErrorKind, Message: err.Error()}
+	}
+	return &RecordedError{Kind: TransportErrorKind, Message: err.Error()}
+}
+
+func (e *RecordedError) err() error {
+	switch e.Kind {
+	case RPCErrorKind:
+		return &rpc.RPCError{Code: e.Code, Message: e.Message, Data: e.Data}
+	case HTTPErrorKind:
+		return &rpc.HTTPError{StatusCode: e.StatusCode, Body: e.Body}
+	case DeadlineErrorKind:
+		return context.DeadlineExceeded
+	case CanceledErrorKind:
+		return context.Canceled
+	}
+	return errors.New(e.Message)
+}
+
+// key identifies a call by its method and params. The params are put in a canonical form
+// by a round-trip through interface{}, since json.Marshal sorts the keys of maps but not
+// the fields of structs. Missing params are the same as an empty list.
+func key(meth string, params interface{}) (string, json.RawMessage, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", nil, err
+	}
+	if string(encoded) == "null" {
+		encoded = []byte("[]")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return "", nil, err
+	}
+	if encoded, err = json.Marshal(generic); err != nil {
+		return "", nil, err
+	}
+	return meth + string(encoded), encoded, nil
+}
+
+// Recorder is an rpc.T passing the calls to another one and keeping them for Save
+type Recorder struct {
+	messenger    rpc.T
+	path         string
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecorder wraps messenger, Save writes the calls made so far to the cassette at path
+func NewRecorder(messenger rpc.T, path string) *Recorder {
+	return &Recorder{messenger: messenger, path: path}
+}
+
+// SendRPC passes the call on and records it
+func (R *Recorder) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	reply, err := R.messenger.SendRPC(ctx, meth, params)
+	var raw []byte
+	if err == nil {
+		raw, _ = json.Marshal(reply)
+	}
+	R.record(meth, params, raw, err)
+	return reply, err
+}
+
+// SendRawRPC passes the call on and records the reply undecoded when messenger allows it
+func (R *Recorder) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	messenger, ok := R.messenger.(rpc.RawT)
+	if !ok {
+		reply, err := R.SendRPC(ctx, meth, params)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(reply)
+	}
+	raw, err := messenger.SendRawRPC(ctx, meth, params)
+	R.record(meth, params, raw, err)
+	return raw, err
+}
+
+func (R *Recorder) record(meth string, params []interface{}, raw []byte, err error) {
+	_, encoded, encodeErr := key(meth, params)
+	if encodeErr != nil {
+		return
+	}
+	interaction := Interaction{Method: meth, Params: encoded, Reply: raw}
+	if err != nil {
+		interaction.Error = recordError(err)
+	}
+	R.mu.Lock()
+	R.interactions = append(R.interactions, interaction)
+	R.mu.Unlock()
+}
+
+// Save writes the recorded calls to the cassette file
+func (R *Recorder) Save() error {
+	R.mu.Lock()
+	content, err := json.MarshalIndent(cassette{R.interactions}, "", "  ")
+	R.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(R.path, content, 0644)
+}
+
+// Replayer is an rpc.T answering from a cassette. Identical calls are answered in
+// the order they were recorded, the last answer being repeated once they run out.
+type Replayer struct {
+	mu      sync.Mutex
+	answers map[string][]Interaction
+}
+
+// NewReplayer loads the cassette at path
+func NewReplayer(path string) (*Replayer, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	recorded := cassette{}
+	if err := json.Unmarshal(content, &recorded); err != nil {
+		return nil, errors.Wrapf(err, "could not decode cassette %s", path)
+	}
+	replayer := &Replayer{answers: map[string][]Interaction{}}
+	for _, interaction := range recorded.Interactions {
+		k, _, err := key(interaction.Method, interaction.Params)
+		if err != nil {
+			return nil, err
+		}
+		replayer.answers[k] = append(replayer.answers[k], interaction)
+	}
+	return replayer, nil
+}
+
+// SendRPC answers the call as recorded
+func (R *Replayer) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	raw, err := R.SendRawRPC(ctx, meth, params)
+	if err != nil {
+		return nil, err
+	}
+	reply := rpc.Reply{}
+	if err := json.Unmarshal(raw, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// SendRawRPC answers the call as recorded, undecoded
+func (R *Replayer) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	k, encoded, err := key(meth, params)
+	if err != nil {
+		return nil, err
+	}
+	R.mu.Lock()
+	queue := R.answers[k]
+	if len(queue) == 0 {
+		R.mu.Unlock()
+		return nil, errors.Wrapf(ErrUnmatchedCall, "%s %s", meth, encoded)
+	}
+	answer := queue[0]
+	if len(queue) > 1 {
+		R.answers[k] = queue[1:]
+	}
+	R.mu.Unlock()
+	if answer.Error != nil {
+		return nil, answer.Error.err()
+	}
+	return answer.Reply, nil
+}
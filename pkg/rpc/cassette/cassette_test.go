@@ -0,0 +1,96 @@
+package cassette
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+)
+
+// liveNode stands for a node, answering receipts only from the second poll on
+type liveNode struct {
+	polls int
+}
+
+func (n *liveNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	switch meth {
+	case rpc.Method.GetTransactionReceipt:
+		n.polls++
+		if n.polls == 1 {
+			return rpc.Reply{"jsonrpc": "2.0", "id": "1", "result": nil}, nil
+		}
+		return rpc.Reply{"jsonrpc": "2.0", "id": "1", "result": map[string]interface{}{"status": "0x1"}}, nil
+	case rpc.Method.SendRawTransaction:
+		return nil, rpc.ErrorCodeToError("nonce too low", -26)
+	}
+	return rpc.Reply{"jsonrpc": "2.0", "id": "1", "result": "0x10"}, nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewRecorder(&liveNode{}, path)
+	recorder.SendRPC(ctx, rpc.Method.BlockNumber, nil)
+	recorder.SendRPC(ctx, rpc.Method.GetTransactionReceipt, []interface{}{"0xabc"})
+	recorder.SendRPC(ctx, rpc.Method.GetTransactionReceipt, []interface{}{"0xabc"})
+	recorder.SendRPC(ctx, rpc.Method.SendRawTransaction, []interface{}{"0xf8"})
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := replayer.SendRPC(ctx, rpc.Method.BlockNumber, []interface{}{})
+	if err != nil || reply["result"] != "0x10" {
+		t.Errorf("unexpected replay of block number: %v, %v", reply, err)
+	}
+	for i, pending := range []bool{true, false, false} {
+		reply, err := replayer.SendRPC(ctx, rpc.Method.GetTransactionReceipt, []interface{}{"0xabc"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (reply["result"] == nil) != pending {
+			t.Errorf("poll %d: unexpected receipt %v", i, reply["result"])
+		}
+	}
+	if _, err := replayer.SendRPC(ctx, rpc.Method.SendRawTransaction, []interface{}{"0xf8"}); !errors.Is(err, rpc.ErrVerifyRejected) {
+		t.Errorf("expected the recorded rpc error, got %v", err)
+	}
+	if _, err := replayer.SendRPC(ctx, rpc.Method.GetTransactionReceipt, []interface{}{"0xdef"}); !errors.Is(err, ErrUnmatchedCall) {
+		t.Errorf("expected an unmatched call, got %v", err)
+	}
+}
+
+// overloadedNode answers every call with an HTTP 503
+type overloadedNode struct{}
+
+func (overloadedNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	return nil, &rpc.HTTPError{StatusCode: 503, Body: []byte("overloaded")}
+}
+
+func TestReplayHTTPError(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	recorder := NewRecorder(overloadedNode{}, path)
+	recorder.SendRPC(ctx, rpc.Method.BlockNumber, nil)
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replayer, err := NewReplayer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = replayer.SendRPC(ctx, rpc.Method.BlockNumber, nil)
+	var httpErr *rpc.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != 503 || string(httpErr.Body) != "overloaded" {
+		t.Fatalf("expected the recorded http status, got %v", err)
+	}
+	if !rpc.Retryable(err) {
+		t.Error("expected the replayed 503 to be retryable as it is live")
+	}
+}
@@ -0,0 +1,454 @@
+// Package client is a typed layer over rpc.T, decoding the replies of every method
+// of rpc.Method into Go values instead of leaving callers with an rpc.Reply map.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+	"github.com/harmony-one/go-sdk/pkg/sharding"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Latest is the block parameter for the state at the head of the chain
+	Latest = "latest"
+	// Pending is the block parameter for the state including the transaction pool
+	Pending = "pending"
+)
+
+// BlockArg formats a block number as a block parameter
+func BlockArg(number uint64) string {
+	return hexutil.EncodeUint64(number)
+}
+
+// Client calls the methods of rpc.Method through a messenger and decodes their results
+type Client struct {
+	messenger rpc.T
+}
+
+// NewClient creates a Client sending its calls through messenger
+func NewClient(messenger rpc.T) *Client {
+	return &Client{messenger}
+}
+
+// Messenger is the transport the client sends its calls through
+func (c *Client) Messenger() rpc.T {
+	return c.messenger
+}
+
+// call sends meth and decodes the result member of the reply into result. The reply
+// is read undecoded when the messenger allows it, so that big numbers keep their precision.
+// The results of the hmyv2 methods are normalized first, the types decoding the v1 shape.
+func (c *Client) call(ctx context.Context, meth string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	if rpcV2.IsMethod(meth) {
+		params = rpcV2.Params(meth, params)
+	}
+	var raw json.RawMessage
+	if messenger, ok := c.messenger.(rpc.RawT); ok {
+		rawReply, err := messenger.SendRawRPC(ctx, meth, params)
+		if err != nil {
+			return err
+		}
+		reply := struct {
+			Result json.RawMessage `json:"result"`
+		}{}
+		if err := json.Unmarshal(rawReply, &reply); err != nil {
+			return errors.Wrapf(err, "could not decode %s reply", meth)
+		}
+		raw = reply.Result
+	} else {
+		reply, err := c.messenger.SendRPC(ctx, meth, params)
+		if err != nil {
+			return err
+		}
+		if raw, err = json.Marshal(reply["result"]); err != nil {
+			return errors.Wrapf(err, "could not decode %s reply", meth)
+		}
+	}
+	if rpcV2.IsMethod(meth) {
+		var err error
+		if raw, err = rpcV2.Normalize(meth, raw); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return errors.Wrapf(err, "could not decode %s result", meth)
+	}
+	return nil
+}
+
+func (c *Client) callBig(ctx context.Context, meth string, params ...interface{}) (*big.Int, error) {
+	result := quantity{}
+	if err := c.call(ctx, meth, &result, params...); err != nil {
+		return nil, err
+	}
+	return &result.Int, nil
+}
+
+func (c *Client) callUint64(ctx context.Context, meth string, params ...interface{}) (uint64, error) {
+	result := quantity{}
+	if err := c.call(ctx, meth, &result, params...); err != nil {
+		return 0, err
+	}
+	return result.uint64()
+}
+
+func (c *Client) callHash(ctx context.Context, meth string, params ...interface{}) (common.Hash, error) {
+	result := common.Hash{}
+	err := c.call(ctx, meth, &result, params...)
+	return result, err
+}
+
+func (c *Client) callString(ctx context.Context, meth string, params ...interface{}) (string, error) {
+	result := ""
+	err := c.call(ctx, meth, &result, params...)
+	return result, err
+}
+
+// GetShardingStructure returns the endpoints of every shard of the network
+func (c *Client) GetShardingStructure(ctx context.Context) ([]sharding.RPCRoutes, error) {
+	result := []sharding.RPCRoutes{}
+	err := c.call(ctx, rpc.Method.GetShardingStructure, &result)
+	return result, err
+}
+
+// GetShardID returns the shard of the node
+func (c *Client) GetShardID(ctx context.Context) (uint32, error) {
+	id, err := c.callUint64(ctx, rpc.Method.GetShardID)
+	return uint32(id), err
+}
+
+// GetNodeMetadata describes the node
+func (c *Client) GetNodeMetadata(ctx context.Context) (*NodeMetadata, error) {
+	result := &NodeMetadata{}
+	if err := c.call(ctx, rpc.Method.GetNodeMetadata, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProtocolVersion returns the version of the protocol spoken by the node
+func (c *Client) ProtocolVersion(ctx context.Context) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.ProtocolVersion)
+}
+
+// NetVersion returns the network id
+func (c *Client) NetVersion(ctx context.Context) (string, error) {
+	result := quantity{}
+	if err := c.call(ctx, rpc.Method.NetVersion, &result); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// PeerCount returns the number of peers of the node
+func (c *Client) PeerCount(ctx context.Context) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.PeerCount)
+}
+
+// Syncing returns the progress of the node catching up with the chain, nil once in sync
+func (c *Client) Syncing(ctx context.Context) (*SyncProgress, error) {
+	raw := json.RawMessage{}
+	if err := c.call(ctx, rpc.Method.Syncing, &raw); err != nil {
+		return nil, err
+	}
+	inSync := false
+	if json.Unmarshal(raw, &inSync) == nil {
+		return nil, nil
+	}
+	result := &SyncProgress{}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s result", rpc.Method.Syncing)
+	}
+	return result, nil
+}
+
+// BlockNumber returns the number of the head of the chain
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.BlockNumber)
+}
+
+// GetBlockByNumber returns a block, with its full transactions if fullTx is set
+func (c *Client) GetBlockByNumber(ctx context.Context, block string, fullTx bool) (*Block, error) {
+	result := &Block{}
+	if err := c.call(ctx, rpc.Method.GetBlockByNumber, result, block, fullTx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlockByHash returns a block, with its full transactions if fullTx is set
+func (c *Client) GetBlockByHash(ctx context.Context, hash string, fullTx bool) (*Block, error) {
+	result := &Block{}
+	if err := c.call(ctx, rpc.Method.GetBlockByHash, result, hash, fullTx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBlockTransactionCountByNumber returns the number of plain transactions in a block
+func (c *Client) GetBlockTransactionCountByNumber(ctx context.Context, block string) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.GetBlockTransactionCountByNumber, block)
+}
+
+// GetBlockTransactionCountByHash returns the number of plain transactions in a block
+func (c *Client) GetBlockTransactionCountByHash(ctx context.Context, hash string) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.GetBlockTransactionCountByHash, hash)
+}
+
+// GetLatestBlockHeader returns the header of the head of the chain
+func (c *Client) GetLatestBlockHeader(ctx context.Context) (*Header, error) {
+	result := &Header{}
+	if err := c.call(ctx, rpc.Method.GetLatestBlockHeader, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetLatestChainHeaders returns the latest beacon chain and shard chain headers
+func (c *Client) GetLatestChainHeaders(ctx context.Context) (*ChainHeaders, error) {
+	result := &ChainHeaders{}
+	if err := c.call(ctx, rpc.Method.GetLatestChainHeaders, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBalance returns the balance of addr, in atto, at block
+func (c *Client) GetBalance(ctx context.Context, addr, block string) (*big.Int, error) {
+	return c.callBig(ctx, rpc.Method.GetBalance, addr, block)
+}
+
+// GetTransactionCount returns the nonce of addr at block
+func (c *Client) GetTransactionCount(ctx context.Context, addr, block string) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.GetTransactionCount, addr, block)
+}
+
+// GetCode returns the code of the contract at addr
+func (c *Client) GetCode(ctx context.Context, addr, block string) ([]byte, error) {
+	result := hexutil.Bytes{}
+	err := c.call(ctx, rpc.Method.GetCode, &result, addr, block)
+	return result, err
+}
+
+// GetStorageAt returns the storage slot key of the contract at addr
+func (c *Client) GetStorageAt(ctx context.Context, addr, key, block string) ([]byte, error) {
+	result := hexutil.Bytes{}
+	err := c.call(ctx, rpc.Method.GetStorageAt, &result, addr, key, block)
+	return result, err
+}
+
+// GetProof returns the Merkle proof of the account addr and of its storage slots keys
+func (c *Client) GetProof(ctx context.Context, addr string, keys []string, block string) (*AccountProof, error) {
+	if keys == nil {
+		keys = []string{}
+	}
+	result := &AccountProof{}
+	if err := c.call(ctx, rpc.Method.GetProof, result, addr, keys, block); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactionByHash returns a plain transaction
+func (c *Client) GetTransactionByHash(ctx context.Context, hash string) (*Transaction, error) {
+	return c.transaction(ctx, rpc.Method.GetTransactionByHash, hash)
+}
+
+// GetTransactionByBlockHashAndIndex returns the plain transaction at index in a block
+func (c *Client) GetTransactionByBlockHashAndIndex(ctx context.Context, hash string, index uint64) (*Transaction, error) {
+	return c.transaction(ctx, rpc.Method.GetTransactionByBlockHashAndIndex, hash, hexutil.EncodeUint64(index))
+}
+
+// GetTransactionByBlockNumberAndIndex returns the plain transaction at index in a block
+func (c *Client) GetTransactionByBlockNumberAndIndex(ctx context.Context, block string, index uint64) (*Transaction, error) {
+	return c.transaction(ctx, rpc.Method.GetTransactionByBlockNumberAndIndex, block, hexutil.EncodeUint64(index))
+}
+
+// transaction decodes a transaction lookup, nodes answer null for an unknown transaction
+func (c *Client) transaction(ctx context.Context, meth string, params ...interface{}) (*Transaction, error) {
+	var result *Transaction
+	if err := c.call(ctx, meth, &result, params...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStakingTransactionByHash returns a staking transaction
+func (c *Client) GetStakingTransactionByHash(ctx context.Context, hash string) (*StakingTransaction, error) {
+	var result *StakingTransaction
+	if err := c.call(ctx, rpc.Method.GetStakingTransactionByHash, &result, hash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactionReceipt returns the receipt of a mined transaction, nil while it is pending
+func (c *Client) GetTransactionReceipt(ctx context.Context, hash string) (*Receipt, error) {
+	var result *Receipt
+	if err := c.call(ctx, rpc.Method.GetTransactionReceipt, &result, hash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTransactionsHistory returns a page of the transactions of an address
+func (c *Client) GetTransactionsHistory(ctx context.Context, args HistoryArgs) (*TransactionsHistory, error) {
+	result := &TransactionsHistory{}
+	if err := c.call(ctx, rpc.Method.GetTransactionsHistory, result, args); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingTxnsInPool returns the plain transactions waiting in the pool of the node
+func (c *Client) GetPendingTxnsInPool(ctx context.Context) ([]Transaction, error) {
+	result := []Transaction{}
+	err := c.call(ctx, rpc.Method.GetPendingTxnsInPool, &result)
+	return result, err
+}
+
+// SendRawTransaction broadcasts a signed plain transaction given in hex
+func (c *Client) SendRawTransaction(ctx context.Context, rawTx string) (common.Hash, error) {
+	return c.callHash(ctx, rpc.Method.SendRawTransaction, rawTx)
+}
+
+// SendRawStakingTransaction broadcasts a signed staking transaction given in hex
+func (c *Client) SendRawStakingTransaction(ctx context.Context, rawTx string) (common.Hash, error) {
+	return c.callHash(ctx, rpc.Method.SendRawStakingTransaction, rawTx)
+}
+
+// SendTransaction has the node sign and send a transaction from one of its own accounts
+func (c *Client) SendTransaction(ctx context.Context, args CallArgs) (common.Hash, error) {
+	return c.callHash(ctx, rpc.Method.SendTransaction, args)
+}
+
+// ResendCX asks the node to send again the cross shard receipt of a transaction,
+// it reports whether the receipt was resent
+func (c *Client) ResendCX(ctx context.Context, hash string) (bool, error) {
+	result := false
+	err := c.call(ctx, rpc.Method.ResendCX, &result, hash)
+	return result, err
+}
+
+// GetCXReceiptByHash returns the receipt of a cross shard transaction once a node of its
+// destination shard credited it, nil until then
+func (c *Client) GetCXReceiptByHash(ctx context.Context, hash string) (*CXReceipt, error) {
+	var result *CXReceipt
+	if err := c.call(ctx, rpc.Method.GetCXReceiptByHash, &result, hash); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPendingCXReceipts returns the cross shard receipts waiting to be delivered,
+// undecoded since their proofs only make sense to a node
+func (c *Client) GetPendingCXReceipts(ctx context.Context) ([]json.RawMessage, error) {
+	result := []json.RawMessage{}
+	err := c.call(ctx, rpc.Method.GetPendingCXReceipts, &result)
+	return result, err
+}
+
+// Call runs a message against the state at block without creating a transaction
+func (c *Client) Call(ctx context.Context, args CallArgs, block string) ([]byte, error) {
+	result := hexutil.Bytes{}
+	err := c.call(ctx, rpc.Method.Call, &result, args, block)
+	return result, err
+}
+
+// EstimateGas returns the gas a message would use if sent as a transaction
+func (c *Client) EstimateGas(ctx context.Context, args CallArgs) (uint64, error) {
+	return c.callUint64(ctx, rpc.Method.EstimateGas, args)
+}
+
+// GasPrice returns the gas price suggested by the node, in atto
+func (c *Client) GasPrice(ctx context.Context) (*big.Int, error) {
+	return c.callBig(ctx, rpc.Method.GasPrice)
+}
+
+// GetWork returns the mining work package, kept for compatibility with the eth API
+func (c *Client) GetWork(ctx context.Context) ([]string, error) {
+	result := []string{}
+	err := c.call(ctx, rpc.Method.GetWork, &result)
+	return result, err
+}
+
+// GetPastLogs returns the logs matching filter, given as in the eth API
+func (c *Client) GetPastLogs(ctx context.Context, filter map[string]interface{}) ([]Log, error) {
+	result := []Log{}
+	err := c.call(ctx, rpc.Method.GetPastLogs, &result, filter)
+	return result, err
+}
+
+// NewFilter installs a log filter on the node and returns its id
+func (c *Client) NewFilter(ctx context.Context, filter map[string]interface{}) (string, error) {
+	return c.callString(ctx, rpc.Method.NewFilter, filter)
+}
+
+// NewBlockFilter installs a filter for new blocks on the node and returns its id
+func (c *Client) NewBlockFilter(ctx context.Context) (string, error) {
+	return c.callString(ctx, rpc.Method.NewBlockFilter)
+}
+
+// NewPendingTransactionFilter installs a filter for new pending transactions and returns its id
+func (c *Client) NewPendingTransactionFilter(ctx context.Context) (string, error) {
+	return c.callString(ctx, rpc.Method.NewPendingTransactionFilter)
+}
+
+// GetFilterChanges returns what a filter saw since the last poll: hashes for block
+// and pending transaction filters, logs for log filters
+func (c *Client) GetFilterChanges(ctx context.Context, id string) ([]json.RawMessage, error) {
+	result := []json.RawMessage{}
+	err := c.call(ctx, rpc.Method.GetFilterChanges, &result, id)
+	return result, err
+}
+
+// Subscribe starts a subscription and returns its id. The notifications only flow over
+// a websocket, a WSMessenger delivers them through its Subscribe methods instead.
+func (c *Client) Subscribe(ctx context.Context, kind string, args ...interface{}) (string, error) {
+	return c.callString(ctx, rpc.Method.Subscribe, append([]interface{}{kind}, args...)...)
+}
+
+// UnSubscribe ends a subscription, it reports whether the subscription existed
+func (c *Client) UnSubscribe(ctx context.Context, id string) (bool, error) {
+	result := false
+	err := c.call(ctx, rpc.Method.UnSubscribe, &result, id)
+	return result, err
+}
+
+// GetCurrentTransactionErrorSink returns the plain transactions recently rejected by the node
+func (c *Client) GetCurrentTransactionErrorSink(ctx context.Context) ([]SinkError, error) {
+	result := []SinkError{}
+	err := c.call(ctx, rpc.Method.GetCurrentTransactionErrorSink, &result)
+	return result, err
+}
+
+// GetCurrentBadBlocks returns the blocks the node rejected, undecoded
+func (c *Client) GetCurrentBadBlocks(ctx context.Context) ([]json.RawMessage, error) {
+	result := []json.RawMessage{}
+	err := c.call(ctx, rpc.Method.GetCurrentBadBlocks, &result)
+	return result, err
+}
+
+// GetPendingCrosslinks returns the cross links waiting to be recorded on the beacon chain
+func (c *Client) GetPendingCrosslinks(ctx context.Context) ([]CrossLink, error) {
+	result := []CrossLink{}
+	err := c.call(ctx, rpc.Method.GetPendingCrosslinks, &result)
+	return result, err
+}
+
+// GetLastCrossLinks returns the latest cross link of every shard
+func (c *Client) GetLastCrossLinks(ctx context.Context) ([]CrossLink, error) {
+	result := []CrossLink{}
+	err := c.call(ctx, rpc.Method.GetLastCrossLinks, &result)
+	return result, err
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	rpcCommon "github.com/harmony-one/go-sdk/pkg/rpc/common"
+	rpcV1 "github.com/harmony-one/go-sdk/pkg/rpc/v1"
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+)
+
+// cannedMessenger answers every method with a fixed result, as raw JSON
+type cannedMessenger map[string]string
+
+func (m cannedMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	return nil, fmt.Errorf("%s should be read raw", meth)
+}
+
+func (m cannedMessenger) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	result, ok := m[meth]
+	if !ok {
+		return nil, fmt.Errorf("unexpected method %s", meth)
+	}
+	return []byte(`{"jsonrpc":"2.0","id":"1","result":` + result + `}`), nil
+}
+
+func TestClientCoversEveryMethod(t *testing.T) {
+	renamed := map[string]string{"GetSuperCommmittees": "GetSuperCommittees"}
+	client := reflect.TypeOf(&Client{})
+	methods := reflect.TypeOf(rpcCommon.RpcEnumList{})
+	for i := 0; i < methods.NumField(); i++ {
+		name := methods.Field(i).Name
+		if renamed[name] != "" {
+			name = renamed[name]
+		}
+		if _, ok := client.MethodByName(name); !ok {
+			t.Errorf("no client method for %s", name)
+		}
+	}
+}
+
+func TestClientDecodes(t *testing.T) {
+	ctx := context.Background()
+	client := NewClient(cannedMessenger{
+		rpc.Method.GetBalance:  `"0xd3c21bcecceda1000000"`,
+		rpc.Method.BlockNumber: `"0x10"`,
+		rpc.Method.Syncing:     `false`,
+		rpc.Method.GetTransactionReceipt: `{"blockNumber":"0x2","transactionHash":
+			"0x0000000000000000000000000000000000000000000000000000000000000001","status":"0x1",
+			"from":"one1from","to":"one1to","logs":[]}`,
+		rpc.Method.GetValidatorInformation: `{"validator":{"address":"one1validator",
+			"rate":"0.100000000000000000","delegations":[{"delegator-address":"one1delegator",
+			"amount":12345678901234567890123,"reward":0,"undelegations":[]}]},
+			"total-delegation":12345678901234567890123,"active-status":"active"}`,
+	})
+
+	balance, err := client.GetBalance(ctx, "one1address", Latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.String() != "1000000000000000000000000" {
+		t.Errorf("unexpected balance %s", balance)
+	}
+	if number, err := client.BlockNumber(ctx); err != nil || number != 16 {
+		t.Errorf("unexpected block number %d, %v", number, err)
+	}
+	if progress, err := client.Syncing(ctx); err != nil || progress != nil {
+		t.Errorf("expected the node in sync, got %v, %v", progress, err)
+	}
+
+	receipt, err := client.GetTransactionReceipt(ctx, "0x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Succeeded() || receipt.BlockNumber != 2 || receipt.From != "one1from" {
+		t.Errorf("unexpected receipt %+v", receipt)
+	}
+
+	info, err := client.GetValidatorInformation(ctx, "one1validator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TotalDelegated.String() != "12345678901234567890123" || info.ActiveStatus != "active" {
+		t.Errorf("unexpected validator information %+v", info)
+	}
+	if info.Validator.Rate.String() != "0.100000000000000000" ||
+		len(info.Validator.Delegations) != 1 ||
+		info.Validator.Delegations[0].DelegatorAddress != "one1delegator" ||
+		info.Validator.Delegations[0].Amount.String() != "12345678901234567890123" {
+		t.Errorf("unexpected validator %+v", info.Validator)
+	}
+
+	if _, err := client.GetShardID(ctx); err == nil {
+		t.Error("expected the messenger error to be returned")
+	}
+}
+
+func TestClientDecodesV2LikeV1(t *testing.T) {
+	ctx := context.Background()
+	tx := `{"blockHash":"0x0000000000000000000000000000000000000000000000000000000000000002",
+		"blockNumber":%s,"from":"one1from","timestamp":%s,"gas":%s,"gasPrice":%s,
+		"hash":"0x0000000000000000000000000000000000000000000000000000000000000001","input":"0x",
+		"nonce":%s,"to":"one1to","transactionIndex":%s,"value":%s,"shardID":0,"toShardID":1,
+		"v":"0x25","r":"0x1","s":"0x2"}`
+	block := `{"number":%s,"viewID":%s,"epoch":%s,"nonce":0,"difficulty":0,"size":%s,
+		"gasLimit":%s,"gasUsed":%s,"timestamp":%s,
+		"stateRoot":"0x0000000000000000000000000000000000000000000000000000000000000003",
+		"transactions":[%s],"stakingTransactions":[]}`
+	receipt := `{"blockNumber":%s,"transactionIndex":%s,"gasUsed":%s,"cumulativeGasUsed":%s,
+		"status":%s,"from":"one1from","to":"one1to","logs":[{"blockNumber":"0x2","logIndex":"0x0",
+		"transactionIndex":"0x0","topics":[],"data":"0x"}]}`
+	cxReceipt := `{"blockHash":"0x0000000000000000000000000000000000000000000000000000000000000004",
+		"blockNumber":%s,"hash":"0x0000000000000000000000000000000000000000000000000000000000000001",
+		"from":"one1from","to":"one1to","shardID":0,"toShardID":1,"value":%s}`
+	v1Tx := fmt.Sprintf(tx, `"0x2"`, `"0x5f5e100"`, `"0x5208"`, `"0x3b9aca00"`, `"0x7"`, `"0x0"`,
+		`"0xd3c21bcecceda1000001"`)
+	v2Tx := fmt.Sprintf(tx, `2`, `100000000`, `21000`, `1000000000`, `7`, `0`, `1000000000000000000000001`)
+	canned := cannedMessenger{
+		"hmy_getBalance":            `"0xd3c21bcecceda1000001"`,
+		"hmyv2_getBalance":          `1000000000000000000000001`,
+		"hmy_getTransactionCount":   `"0x7"`,
+		"hmyv2_getTransactionCount": `7`,
+		"hmy_getBlockByNumber": fmt.Sprintf(block, `"0x2"`, `"0x3"`, `"0x1"`, `"0x2a"`, `"0x4c4b40"`,
+			`"0x5208"`, `"0x5f5e100"`, v1Tx),
+		"hmyv2_getBlockByNumber":      fmt.Sprintf(block, `2`, `3`, `1`, `42`, `5000000`, `21000`, `100000000`, v2Tx),
+		"hmy_getTransactionReceipt":   fmt.Sprintf(receipt, `"0x2"`, `"0x0"`, `"0x5208"`, `"0x5208"`, `"0x1"`),
+		"hmyv2_getTransactionReceipt": fmt.Sprintf(receipt, `2`, `0`, `21000`, `21000`, `1`),
+		"hmy_getCXReceiptByHash":      fmt.Sprintf(cxReceipt, `"0x3"`, `"0xd3c21bcecceda1000001"`),
+		"hmyv2_getCXReceiptByHash":    fmt.Sprintf(cxReceipt, `3`, `1000000000000000000000001`),
+	}
+
+	type values struct {
+		Balance *big.Int
+		Nonce   uint64
+		Block   *Block
+		Receipt *Receipt
+		CX      *CXReceipt
+	}
+	fetch := func(methods rpcCommon.RpcEnumList) values {
+		saved := rpc.Method
+		rpc.Method = methods
+		defer func() { rpc.Method = saved }()
+		client := NewClient(canned)
+		var v values
+		var err error
+		if v.Balance, err = client.GetBalance(ctx, "one1address", Latest); err != nil {
+			t.Fatal(err)
+		}
+		if v.Nonce, err = client.GetTransactionCount(ctx, "one1address", Latest); err != nil {
+			t.Fatal(err)
+		}
+		if v.Block, err = client.GetBlockByNumber(ctx, Latest, true); err != nil {
+			t.Fatal(err)
+		}
+		if v.Receipt, err = client.GetTransactionReceipt(ctx, "0x01"); err != nil {
+			t.Fatal(err)
+		}
+		if v.CX, err = client.GetCXReceiptByHash(ctx, "0x01"); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	v1, v2 := fetch(rpcV1.Method), fetch(rpcV2.Method)
+	if !reflect.DeepEqual(v1, v2) {
+		t.Errorf("v1 and v2 decode differently:\n%+v\n%+v", v1, v2)
+	}
+	if v2.Balance.String() != "1000000000000000000000001" || len(v2.Block.Transactions) != 1 ||
+		v2.Block.Transactions[0].Value.ToInt().String() != "1000000000000000000000001" ||
+		v2.CX.Value.ToInt().String() != "1000000000000000000000001" {
+		t.Errorf("v2 lost the precision of big numbers: %s %+v", v2.Balance, v2.Block.Transactions)
+	}
+}
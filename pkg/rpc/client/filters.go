@@ -0,0 +1,211 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultChunkSize    = 1024
+	defaultPollInterval = 2 * time.Second
+)
+
+// LogQuery selects the logs emitted by some contracts over a range of blocks
+type LogQuery struct {
+	FromBlock uint64
+	// ToBlock is the last block of the range, the head of the chain when nil
+	ToBlock *uint64
+	// Addresses are the contracts emitting the logs, any contract when empty
+	Addresses []common.Address
+	// Topics holds the accepted values of each topic position, a nil entry accepting any
+	Topics [][]common.Hash
+}
+
+// filter is the query as the filter object of the eth API, over the given blocks
+func (q LogQuery) filter(fromBlock, toBlock string) map[string]interface{} {
+	filter := map[string]interface{}{"fromBlock": fromBlock}
+	if toBlock != "" {
+		filter["toBlock"] = toBlock
+	}
+	if len(q.Addresses) > 0 {
+		filter["address"] = q.Addresses
+	}
+	if len(q.Topics) > 0 {
+		topics := make([]interface{}, len(q.Topics))
+		for i, alternatives := range q.Topics {
+			if len(alternatives) > 0 {
+				topics[i] = alternatives
+			}
+		}
+		filter["topics"] = topics
+	}
+	return filter
+}
+
+// FilterManager reads the logs matching a LogQuery, either over a past range of blocks,
+// split in chunks the node accepts, or as they are emitted by polling an installed filter
+type FilterManager struct {
+	// ChunkSize is the number of blocks asked for by each GetPastLogs call
+	ChunkSize uint64
+	// PollInterval is the delay between two GetFilterChanges calls of Watch
+	PollInterval time.Duration
+	client       *Client
+}
+
+// NewFilterManager creates a FilterManager calling the node through client
+func NewFilterManager(client *Client, options ...func(*FilterManager)) *FilterManager {
+	manager := &FilterManager{
+		ChunkSize:    defaultChunkSize,
+		PollInterval: defaultPollInterval,
+		client:       client,
+	}
+	for _, option := range options {
+		option(manager)
+	}
+	return manager
+}
+
+// GetLogs calls fn with the logs of the query in the order of the chain, asking for
+// ChunkSize blocks at a time. It stops at the first error, of the node or of fn.
+func (m *FilterManager) GetLogs(ctx context.Context, query LogQuery, fn func(Log) error) error {
+	toBlock := query.ToBlock
+	if toBlock == nil {
+		head, err := m.client.BlockNumber(ctx)
+		if err != nil {
+			return err
+		}
+		toBlock = &head
+	}
+	chunk := m.ChunkSize
+	if chunk == 0 {
+		chunk = defaultChunkSize
+	}
+	for from := query.FromBlock; from <= *toBlock; from += chunk {
+		to := from + chunk - 1
+		if to > *toBlock || to < from {
+			to = *toBlock
+		}
+		logs, err := m.client.GetPastLogs(ctx, query.filter(BlockArg(from), BlockArg(to)))
+		if err != nil {
+			return errors.Wrapf(err, "logs of blocks %d to %d", from, to)
+		}
+		for _, log := range logs {
+			if err := fn(log); err != nil {
+				return err
+			}
+		}
+		if to == *toBlock {
+			break
+		}
+	}
+	return nil
+}
+
+// Watch calls fn with the logs of the query from query.FromBlock on, then with the
+// new ones as the node reports them to a filter polled every PollInterval, until ctx
+// is done or fn fails. A filter the node expired is installed again, the logs emitted
+// meanwhile being read with GetPastLogs so that none is missed or repeated. The ToBlock
+// of the query is not used, and the filter is left to expire once the watch is over.
+func (m *FilterManager) Watch(ctx context.Context, query LogQuery, fn func(Log) error) error {
+	delivered := newLogCursor()
+	deliver := func(log Log) error {
+		if !delivered.advance(log) {
+			return nil
+		}
+		return fn(log)
+	}
+	id, err := m.install(ctx, query, delivered, deliver)
+	if err != nil {
+		return err
+	}
+	interval := m.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		changes, err := m.client.GetFilterChanges(ctx, id)
+		if isFilterGone(err) {
+			if id, err = m.install(ctx, query, delivered, deliver); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			log := Log{}
+			if err := json.Unmarshal(change, &log); err != nil {
+				return errors.Wrap(err, "could not decode filter changes")
+			}
+			if err := deliver(log); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// install creates the filter of the query, then reads the logs it will not report:
+// those before its creation, from the block of the last log delivered on
+func (m *FilterManager) install(
+	ctx context.Context, query LogQuery, delivered *logCursor, deliver func(Log) error,
+) (string, error) {
+	id, err := m.client.NewFilter(ctx, query.filter("latest", ""))
+	if err != nil {
+		return "", err
+	}
+	past := query
+	past.ToBlock = nil
+	if delivered.started {
+		past.FromBlock = delivered.block
+	}
+	if err := m.GetLogs(ctx, past, deliver); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// isFilterGone tells whether the node forgot the filter, as it does with the filters not
+// polled for a while
+func isFilterGone(err error) bool {
+	var rpcErr *rpc.RPCError
+	return errors.As(err, &rpcErr) && strings.Contains(strings.ToLower(rpcErr.Message), "filter not found")
+}
+
+// logCursor is the position of the last log delivered, the logs of its block already
+// delivered being kept to skip them when the block is read again
+type logCursor struct {
+	started bool
+	block   uint64
+	indexes map[uint]bool
+}
+
+func newLogCursor() *logCursor {
+	return &logCursor{indexes: map[uint]bool{}}
+}
+
+// advance moves the cursor to log, it returns false for a log already delivered
+func (c *logCursor) advance(log Log) bool {
+	number, index := uint64(log.BlockNumber), uint(log.LogIndex)
+	switch {
+	case c.started && number < c.block, c.started && number == c.block && c.indexes[index]:
+		return false
+	case !c.started || number > c.block:
+		c.started, c.block, c.indexes = true, number, map[uint]bool{}
+	}
+	c.indexes[index] = true
+	return true
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+)
+
+// logNode is a chain emitting a log per block, which forgets its filter on the second poll
+type logNode struct {
+	mu      sync.Mutex
+	head    uint64
+	filters map[string]uint64
+	polls   int
+	ranges  [][2]uint64
+}
+
+func (n *logNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	return nil, fmt.Errorf("%s should be read raw", meth)
+}
+
+func (n *logNode) logs(from, to uint64) []Log {
+	logs := []Log{}
+	for block := from; block <= to && block <= n.head; block++ {
+		if block > 0 {
+			logs = append(logs, Log{BlockNumber: hexutil.Uint64(block)})
+		}
+	}
+	return logs
+}
+
+func (n *logNode) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var result interface{}
+	switch meth {
+	case rpc.Method.BlockNumber:
+		result = hexutil.Uint64(n.head)
+	case rpc.Method.GetPastLogs:
+		filter := params[0].(map[string]interface{})
+		from, _ := hexutil.DecodeUint64(filter["fromBlock"].(string))
+		to, _ := hexutil.DecodeUint64(filter["toBlock"].(string))
+		n.ranges = append(n.ranges, [2]uint64{from, to})
+		result = n.logs(from, to)
+	case rpc.Method.NewFilter:
+		id := fmt.Sprintf("0x%x", len(n.filters)+1)
+		n.filters[id] = n.head
+		result = id
+	case rpc.Method.GetFilterChanges:
+		id := params[0].(string)
+		n.polls++
+		n.head++
+		last, ok := n.filters[id]
+		if !ok || n.polls == 2 {
+			delete(n.filters, id)
+			return nil, &rpc.RPCError{Code: -32000, Message: "filter not found"}
+		}
+		n.filters[id] = n.head
+		result = n.logs(last+1, n.head)
+	default:
+		return nil, fmt.Errorf("unexpected method %s", meth)
+	}
+	raw, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": "1", "result": result})
+	return raw, nil
+}
+
+func TestGetLogsInChunks(t *testing.T) {
+	node := &logNode{head: 2500, filters: map[string]uint64{}}
+	manager := NewFilterManager(NewClient(node), func(m *FilterManager) { m.ChunkSize = 1000 })
+	count := 0
+	err := manager.GetLogs(context.Background(), LogQuery{}, func(log Log) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][2]uint64{{0, 999}, {1000, 1999}, {2000, 2500}}
+	if !reflect.DeepEqual(node.ranges, expected) || count != 2500 {
+		t.Errorf("expected 2500 logs from %v, got %d from %v", expected, count, node.ranges)
+	}
+}
+
+func TestWatchReinstallsExpiredFilter(t *testing.T) {
+	node := &logNode{head: 2, filters: map[string]uint64{}}
+	manager := NewFilterManager(NewClient(node), func(m *FilterManager) { m.PollInterval = time.Millisecond })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blocks := []uint64{}
+	err := manager.Watch(ctx, LogQuery{FromBlock: 1}, func(log Log) error {
+		blocks = append(blocks, uint64(log.BlockNumber))
+		if len(blocks) == 5 {
+			cancel()
+		}
+		return nil
+	})
+	if err != context.Canceled {
+		t.Fatalf("expected the watch to end with its context, got %v", err)
+	}
+	if !reflect.DeepEqual(blocks, []uint64{1, 2, 3, 4, 5}) {
+		t.Errorf("expected every log once in order, got %v", blocks)
+	}
+	if len(node.filters) != 1 {
+		t.Errorf("expected the expired filter to be installed again, got %v", node.filters)
+	}
+}
@@ -0,0 +1,324 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	bls_core "github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/harmony/crypto/bls"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/pkg/errors"
+)
+
+const (
+	// VotePolicy is the quorum of two thirds of the keys of the committee, before staking
+	VotePolicy = "SuperMajorityVote"
+	// StakePolicy is the quorum of more than two thirds of the voting power of the committee
+	StakePolicy = "SuperMajorityStake"
+)
+
+var (
+	// ErrInvalidCommit is returned for a commit signature or bitmap not matching the committee
+	ErrInvalidCommit = errors.New("invalid commit signature")
+	// ErrNoQuorum is returned for a valid commit signature whose signers fall short of the quorum
+	ErrNoQuorum = errors.New("commit signers below the quorum")
+	// ErrUnknownCommittee is returned when the nodes cannot tell the committee of an epoch
+	ErrUnknownCommittee = errors.New("unknown committee")
+
+	twoThirds = numeric.NewDec(2).Quo(numeric.NewDec(3))
+)
+
+// CommitteeMember is a BLS key of a shard committee
+type CommitteeMember struct {
+	BLSPublicKey string
+	// VotingPower is the share of the vote of the key under StakePolicy
+	VotingPower numeric.Dec
+}
+
+// Committee is the set of keys signing the blocks of a shard during an epoch
+type Committee struct {
+	Policy  string
+	Members []CommitteeMember
+}
+
+// SuperCommittees are the committees of every shard, for the epoch of the beacon chain
+// head and the one before it
+type SuperCommittees struct {
+	Previous map[uint32]*Committee
+	Current  map[uint32]*Committee
+}
+
+// DecodeSuperCommittees reads the reply of GetSuperCommittees. The members of staked
+// committees come in no particular order, see Committee.Ordered.
+func DecodeSuperCommittees(raw json.RawMessage) (*SuperCommittees, error) {
+	type decider struct {
+		Policy  string            `json:"policy"`
+		Members []json.RawMessage `json:"committee-members"`
+	}
+	type registry struct {
+		Deciders map[string]decider `json:"quorum-deciders"`
+	}
+	reply := struct {
+		Previous registry `json:"previous"`
+		Current  registry `json:"current"`
+	}{}
+	if err := json.Unmarshal(raw, &reply); err != nil {
+		return nil, errors.Wrap(err, "could not decode super committees")
+	}
+	committees := func(r registry) (map[uint32]*Committee, error) {
+		shards := map[uint32]*Committee{}
+		for name, d := range r.Deciders {
+			shardID, err := strconv.ParseUint(strings.TrimPrefix(name, "shard-"), 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("unexpected committee %s", name)
+			}
+			committee := &Committee{Policy: d.Policy}
+			for _, raw := range d.Members {
+				member := CommitteeMember{VotingPower: numeric.ZeroDec()}
+				if err := json.Unmarshal(raw, &member.BLSPublicKey); err != nil {
+					// Staked committees list objects, the others bare keys
+					staked := struct {
+						Key         string      `json:"bls-public-key"`
+						VotingPower numeric.Dec `json:"voting-power-%"`
+					}{}
+					if err := json.Unmarshal(raw, &staked); err != nil {
+						return nil, errors.Wrapf(err, "could not decode member of %s", name)
+					}
+					member = CommitteeMember{staked.Key, staked.VotingPower}
+				}
+				committee.Members = append(committee.Members, member)
+			}
+			shards[uint32(shardID)] = committee
+		}
+		return shards, nil
+	}
+	previous, err := committees(reply.Previous)
+	if err != nil {
+		return nil, err
+	}
+	current, err := committees(reply.Current)
+	if err != nil {
+		return nil, err
+	}
+	return &SuperCommittees{previous, current}, nil
+}
+
+// Ordered returns the committee with its members in the order of keys, the order of
+// the slots the signer bitmaps refer to. keys must hold the keys of the committee.
+func (c *Committee) Ordered(keys []string) (*Committee, error) {
+	members := map[string]CommitteeMember{}
+	for _, member := range c.Members {
+		members[normalizeKey(member.BLSPublicKey)] = member
+	}
+	if len(keys) != len(members) {
+		return nil, errors.Wrapf(ErrUnknownCommittee, "%d keys given for a committee of %d", len(keys), len(members))
+	}
+	ordered := &Committee{Policy: c.Policy, Members: make([]CommitteeMember, len(keys))}
+	for i, key := range keys {
+		member, ok := members[normalizeKey(key)]
+		if !ok {
+			return nil, errors.Wrapf(ErrUnknownCommittee, "key %s is not a member", key)
+		}
+		ordered.Members[i] = member
+	}
+	return ordered, nil
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimPrefix(key, "0x"))
+}
+
+// CommitPayload is the message the committee signs to commit a block, the view is only
+// part of it once staking is on
+func CommitPayload(blockNumber uint64, blockHash common.Hash, viewID uint64, staking bool) []byte {
+	payload := make([]byte, 8, 8+common.HashLength+8)
+	binary.LittleEndian.PutUint64(payload, blockNumber)
+	payload = append(payload, blockHash.Bytes()...)
+	if !staking {
+		return payload
+	}
+	view := make([]byte, 8)
+	binary.LittleEndian.PutUint64(view, viewID)
+	return append(payload, view...)
+}
+
+// CommitTally counts the signers of a verified commit signature
+type CommitTally struct {
+	Signers int
+	// Power is the share of the vote of the signers, of the keys under VotePolicy
+	Power numeric.Dec
+}
+
+// VerifyCommit checks signature, the aggregate of the signatures of payload by the
+// members set in bitmap, then that the signers reach the quorum of the committee policy
+func (c *Committee) VerifyCommit(payload, signature, bitmap []byte) (*CommitTally, error) {
+	publics := make([]bls.PublicKeyWrapper, len(c.Members))
+	for i, member := range c.Members {
+		key, err := hex.DecodeString(normalizeKey(member.BLSPublicKey))
+		if err != nil || len(key) != bls.PublicKeySizeInBytes {
+			return nil, errors.Wrapf(ErrInvalidCommit, "bad committee key %s", member.BLSPublicKey)
+		}
+		object := &bls_core.PublicKey{}
+		if err := object.Deserialize(key); err != nil {
+			return nil, errors.Wrapf(ErrInvalidCommit, "bad committee key %s", member.BLSPublicKey)
+		}
+		publics[i].Object = object
+		copy(publics[i].Bytes[:], key)
+	}
+	mask, err := bls.NewMask(publics, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := mask.SetMask(bitmap); err != nil {
+		return nil, errors.Wrap(ErrInvalidCommit, err.Error())
+	}
+	aggregate := &bls_core.Sign{}
+	if err := aggregate.Deserialize(signature); err != nil {
+		return nil, errors.Wrap(ErrInvalidCommit, "could not decode the aggregate signature")
+	}
+	if !aggregate.VerifyHash(mask.AggregatePublic, payload) {
+		return nil, errors.Wrap(ErrInvalidCommit, "the signature does not match the signers")
+	}
+	tally := &CommitTally{Signers: mask.CountEnabled(), Power: numeric.ZeroDec()}
+	switch c.Policy {
+	case StakePolicy:
+		for i, member := range c.Members {
+			if enabled, _ := mask.IndexEnabled(i); enabled {
+				tally.Power = tally.Power.Add(member.VotingPower)
+			}
+		}
+		if !tally.Power.GT(twoThirds) {
+			return tally, errors.Wrapf(ErrNoQuorum, "signers hold %s of the vote", tally.Power)
+		}
+	case VotePolicy:
+		tally.Power = numeric.NewDec(int64(tally.Signers)).QuoInt64(int64(len(c.Members)))
+		if threshold := len(c.Members)*2/3 + 1; tally.Signers < threshold {
+			return tally, errors.Wrapf(ErrNoQuorum, "%d signers of %d needed", tally.Signers, threshold)
+		}
+	default:
+		return nil, errors.Errorf("unknown quorum policy %s", c.Policy)
+	}
+	return tally, nil
+}
+
+// CommitVerification is a block proven final by the commit signature of its child
+type CommitVerification struct {
+	ShardID     uint32
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Epoch       uint64
+	ViewID      uint64
+	Policy      string
+	Committee   int
+	CommitTally
+}
+
+// HeaderVerifier checks the commit signatures of headers against the committees of their
+// shard, so that a block is not taken as final on the word of a single node. The
+// committees are read from a beacon chain node, their slot order from a node of the
+// shard, and kept for the later headers. It is not safe for concurrent use.
+type HeaderVerifier struct {
+	beacon     *Client
+	shard      *Client
+	committees map[uint64]*Committee
+}
+
+// NewHeaderVerifier creates a HeaderVerifier for the headers of the shard of the shard
+// client, beacon calling a node of the beacon chain. Both may be the same client on shard 0.
+func NewHeaderVerifier(beacon, shard *Client) *HeaderVerifier {
+	return &HeaderVerifier{beacon: beacon, shard: shard, committees: map[uint64]*Committee{}}
+}
+
+// Committee returns the committee of the shard at epoch, ordered as its slots. The beacon
+// chain only knows the committees of its current and previous epochs.
+func (v *HeaderVerifier) Committee(ctx context.Context, shardID uint32, epoch uint64) (*Committee, error) {
+	key := uint64(shardID)<<32 | epoch
+	if committee, ok := v.committees[key]; ok {
+		return committee, nil
+	}
+	head, err := v.beacon.GetLatestBlockHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := v.beacon.GetSuperCommittees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	committees, err := DecodeSuperCommittees(raw)
+	if err != nil {
+		return nil, err
+	}
+	var committee *Committee
+	switch epoch {
+	case head.Epoch:
+		committee = committees.Current[shardID]
+	case head.Epoch - 1:
+		committee = committees.Previous[shardID]
+	}
+	if committee == nil || len(committee.Members) == 0 {
+		return nil, errors.Wrapf(ErrUnknownCommittee, "shard %d at epoch %d, the beacon chain is at epoch %d",
+			shardID, epoch, head.Epoch)
+	}
+	keys, err := v.shard.GetValidatorKeys(ctx, epoch)
+	if err != nil {
+		return nil, err
+	}
+	if committee, err = committee.Ordered(keys); err != nil {
+		return nil, errors.Wrapf(err, "shard %d at epoch %d", shardID, epoch)
+	}
+	v.committees[key] = committee
+	return committee, nil
+}
+
+// VerifyHeader checks the commit signature header carries. It certifies the parent of
+// the header, which is the block returned once proven final; header itself only becomes
+// final with the signature of its own child.
+func (v *HeaderVerifier) VerifyHeader(ctx context.Context, header *Header) (*CommitVerification, error) {
+	if header.BlockNumber == 0 {
+		return nil, errors.Wrap(ErrInvalidCommit, "the genesis block has no parent to commit")
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.LastCommitSig, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCommit, "could not decode the commit signature")
+	}
+	bitmap, err := hex.DecodeString(strings.TrimPrefix(header.LastCommitBitmap, "0x"))
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidCommit, "could not decode the commit bitmap")
+	}
+	parent, err := v.shard.GetBlockByNumber(ctx, BlockArg(header.BlockNumber-1), false)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Number == nil || parent.Number.ToInt().Uint64() != header.BlockNumber-1 ||
+		parent.Epoch == nil || parent.ViewID == nil {
+		return nil, errors.Errorf("no block %d", header.BlockNumber-1)
+	}
+	verification := &CommitVerification{
+		ShardID:     header.ShardID,
+		BlockNumber: header.BlockNumber - 1,
+		BlockHash:   parent.Hash,
+		Epoch:       parent.Epoch.ToInt().Uint64(),
+		ViewID:      parent.ViewID.ToInt().Uint64(),
+	}
+	committee, err := v.Committee(ctx, header.ShardID, verification.Epoch)
+	if err != nil {
+		return nil, err
+	}
+	verification.Policy, verification.Committee = committee.Policy, len(committee.Members)
+	payload := CommitPayload(
+		verification.BlockNumber, verification.BlockHash, verification.ViewID, committee.Policy == StakePolicy,
+	)
+	tally, err := committee.VerifyCommit(payload, signature, bitmap)
+	if tally != nil {
+		verification.CommitTally = *tally
+	}
+	if err != nil {
+		return verification, errors.Wrapf(err, "block %d of shard %d", verification.BlockNumber, header.ShardID)
+	}
+	return verification, nil
+}
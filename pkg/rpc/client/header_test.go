@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	bls_core "github.com/harmony-one/bls/ffi/go/bls"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/harmony/crypto/bls"
+)
+
+// commitNode serves a shard 0 whose block 10 of epoch 5 is committed by the header of block 11
+type commitNode struct {
+	keys   []*bls_core.SecretKey
+	powers []string
+	header Header
+}
+
+var committedHash = common.HexToHash("0x7d6b4f1e29a0b6c3d8e5f4a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f8091a2b")
+
+func newCommitNode(powers ...string) *commitNode {
+	node := &commitNode{powers: powers, header: Header{BlockNumber: 11, Epoch: 5}}
+	for range powers {
+		node.keys = append(node.keys, bls.RandPrivateKey())
+	}
+	return node
+}
+
+// commit signs the payload with the keys of the given slots
+func (n *commitNode) commit(payload []byte, slots ...int) {
+	aggregate := &bls_core.Sign{}
+	bitmap := make([]byte, (len(n.keys)+7)/8)
+	for _, slot := range slots {
+		aggregate.Add(n.keys[slot].SignHash(payload))
+		bitmap[slot/8] |= 1 << uint(slot%8)
+	}
+	n.header.LastCommitSig = hex.EncodeToString(aggregate.Serialize())
+	n.header.LastCommitBitmap = hex.EncodeToString(bitmap)
+}
+
+func (n *commitNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	return nil, fmt.Errorf("%s should be read raw", meth)
+}
+
+func (n *commitNode) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	var result interface{}
+	switch meth {
+	case rpc.Method.GetLatestBlockHeader:
+		result = n.header
+	case rpc.Method.GetBlockByNumber:
+		result = map[string]interface{}{"number": "0xa", "epoch": "0x5", "viewID": "0xc", "hash": committedHash}
+	case rpc.Method.GetValidatorKeys:
+		keys := []string{}
+		for _, key := range n.keys {
+			keys = append(keys, key.GetPublicKey().SerializeToHexStr())
+		}
+		result = keys
+	case rpc.Method.GetSuperCommmittees:
+		// The members are listed in reverse, as a node may list them in any order
+		members := []map[string]string{}
+		for i := len(n.keys) - 1; i >= 0; i-- {
+			members = append(members, map[string]string{
+				"bls-public-key": n.keys[i].GetPublicKey().SerializeToHexStr(),
+				"voting-power-%": n.powers[i],
+			})
+		}
+		decider := map[string]interface{}{"policy": StakePolicy, "committee-members": members}
+		result = map[string]interface{}{
+			"previous": map[string]interface{}{"quorum-deciders": map[string]interface{}{}},
+			"current":  map[string]interface{}{"quorum-deciders": map[string]interface{}{"shard-0": decider}},
+		}
+	default:
+		return nil, fmt.Errorf("unexpected method %s", meth)
+	}
+	raw, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": "1", "result": result})
+	return raw, nil
+}
+
+func TestVerifyHeader(t *testing.T) {
+	node := newCommitNode("0.400000000000000000", "0.300000000000000000", "0.200000000000000000", "0.100000000000000000")
+	c := NewClient(node)
+	verifier := NewHeaderVerifier(c, c)
+	payload := CommitPayload(10, committedHash, 12, true)
+
+	node.commit(payload, 0, 1)
+	verification, err := verifier.VerifyHeader(context.Background(), &node.header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verification.BlockNumber != 10 || verification.BlockHash != committedHash || verification.Signers != 2 ||
+		verification.Power.String() != "0.700000000000000000" {
+		t.Errorf("unexpected verification %+v", verification)
+	}
+
+	node.commit(payload, 1, 2, 3)
+	if _, err := verifier.VerifyHeader(context.Background(), &node.header); !errors.Is(err, ErrNoQuorum) {
+		t.Errorf("expected 0.6 of the vote to miss the quorum, got %v", err)
+	}
+
+	node.commit(CommitPayload(10, committedHash, 13, true), 0, 1, 2)
+	if _, err := verifier.VerifyHeader(context.Background(), &node.header); !errors.Is(err, ErrInvalidCommit) {
+		t.Errorf("expected a signature of another view to be rejected, got %v", err)
+	}
+
+	node.header.Epoch = 9
+	other := NewHeaderVerifier(c, c)
+	if _, err := other.Committee(context.Background(), 0, 3); !errors.Is(err, ErrUnknownCommittee) {
+		t.Errorf("expected no committee two epochs before the head, got %v", err)
+	}
+}
+
+func TestVerifyCommitByVote(t *testing.T) {
+	node := newCommitNode("0", "0", "0", "0")
+	committee := &Committee{Policy: VotePolicy}
+	for _, key := range node.keys {
+		committee.Members = append(committee.Members, CommitteeMember{BLSPublicKey: key.GetPublicKey().SerializeToHexStr()})
+	}
+	payload := CommitPayload(10, committedHash, 0, false)
+	verify := func() error {
+		signature, _ := hex.DecodeString(node.header.LastCommitSig)
+		bitmap, _ := hex.DecodeString(node.header.LastCommitBitmap)
+		_, err := committee.VerifyCommit(payload, signature, bitmap)
+		return err
+	}
+
+	node.commit(payload, 0, 2, 3)
+	if err := verify(); err != nil {
+		t.Errorf("expected 3 signers of 4 to reach the quorum, got %v", err)
+	}
+	node.commit(payload, 0, 2)
+	if err := verify(); !errors.Is(err, ErrNoQuorum) {
+		t.Errorf("expected 2 signers of 4 to miss the quorum, got %v", err)
+	}
+	node.commit(payload, 0, 2, 3)
+	node.header.LastCommitBitmap = "0f"
+	if err := verify(); !errors.Is(err, ErrInvalidCommit) {
+		t.Errorf("expected a bitmap claiming another signer to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/pkg/errors"
+)
+
+const (
+	// HistoryPageSize is the page size of the history when HistoryArgs leaves it at 0
+	HistoryPageSize = 100
+	// ValidatorsPageSize is the number of validators in a page of the validator information
+	ValidatorsPageSize = 100
+	// Ascending and Descending are the orders of the history, oldest or newest first
+	Ascending  = "ASC"
+	Descending = "DESC"
+	// AllTxs, SentTxs and ReceivedTxs filter the history on the side of the address
+	AllTxs      = "ALL"
+	SentTxs     = "SENT"
+	ReceivedTxs = "RECEIVED"
+)
+
+// ErrBadHistoryArgs is returned for an order or a transaction type the node does not know
+var ErrBadHistoryArgs = errors.New("invalid history arguments")
+
+// Iterator walks a listing the node answers page by page, fetching the next page once
+// the current one is consumed. Callers loop on Next, read each item, and check Err once
+// Next returns false. Leaving the loop early is enough to stop, no page is fetched ahead.
+type Iterator struct {
+	// Limit stops the walk after that many items, 0 walks every page
+	Limit int
+	// fetch returns the items of a page, pageSize of them unless it is the last one
+	fetch    func(ctx context.Context, page int) ([]json.RawMessage, error)
+	pageSize int
+	page     int
+	items    []json.RawMessage
+	index    int
+	seen     int
+	last     bool
+	err      error
+}
+
+func newIterator(
+	fetch func(ctx context.Context, page int) ([]json.RawMessage, error), firstPage, pageSize int,
+	options []func(*Iterator),
+) *Iterator {
+	it := &Iterator{fetch: fetch, page: firstPage, pageSize: pageSize}
+	for _, option := range options {
+		option(it)
+	}
+	return it
+}
+
+// Next moves to the next item, fetching a page when needed. It returns false at the
+// end of the listing, once Limit items were seen, or on an error reported by Err.
+func (it *Iterator) Next(ctx context.Context) bool {
+	if it.err != nil || (it.Limit > 0 && it.seen >= it.Limit) {
+		return false
+	}
+	for it.index >= len(it.items) {
+		if it.last {
+			return false
+		}
+		items, err := it.fetch(ctx, it.page)
+		if err != nil {
+			it.err = errors.Wrapf(err, "page %d", it.page)
+			return false
+		}
+		it.page++
+		it.items, it.index = items, 0
+		it.last = it.pageSize <= 0 || len(items) < it.pageSize
+	}
+	it.index++
+	it.seen++
+	return true
+}
+
+// Raw is the current item as the node sent it
+func (it *Iterator) Raw() json.RawMessage {
+	if it.index == 0 {
+		return nil
+	}
+	return it.items[it.index-1]
+}
+
+// Err is the error that ended the walk, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+func (it *Iterator) decode(item interface{}) error {
+	if err := json.Unmarshal(it.Raw(), item); err != nil {
+		return errors.Wrap(err, "could not decode item")
+	}
+	return nil
+}
+
+// HistoryItem is an entry of the history of an address, Transaction is only set when
+// the full transactions were asked for
+type HistoryItem struct {
+	Hash        common.Hash
+	Transaction *Transaction
+}
+
+// HistoryIterator walks the transactions of an address
+type HistoryIterator struct {
+	*Iterator
+	fullTx bool
+}
+
+// Item decodes the current transaction
+func (it *HistoryIterator) Item() (HistoryItem, error) {
+	if !it.fullTx {
+		var hash common.Hash
+		err := it.decode(&hash)
+		return HistoryItem{Hash: hash}, err
+	}
+	tx := &Transaction{}
+	if err := it.decode(tx); err != nil {
+		return HistoryItem{}, err
+	}
+	return HistoryItem{Hash: tx.Hash, Transaction: tx}, nil
+}
+
+// IterateTransactionsHistory walks the history of args.Address from args.PageIndex on,
+// in the order of args.Order and keeping the transactions of type args.TxType
+func (c *Client) IterateTransactionsHistory(args HistoryArgs, options ...func(*Iterator)) *HistoryIterator {
+	args.Order, args.TxType = strings.ToUpper(args.Order), strings.ToUpper(args.TxType)
+	pageSize := int(args.PageSize)
+	if pageSize == 0 {
+		pageSize = HistoryPageSize
+	}
+	fetch := func(ctx context.Context, page int) ([]json.RawMessage, error) {
+		args.PageIndex = uint32(page)
+		history := struct {
+			Transactions []json.RawMessage `json:"transactions"`
+		}{}
+		err := c.call(ctx, rpc.Method.GetTransactionsHistory, &history, args)
+		return history.Transactions, err
+	}
+	it := &HistoryIterator{newIterator(fetch, int(args.PageIndex), pageSize, options), args.FullTx}
+	switch {
+	case args.Order != "" && args.Order != Ascending && args.Order != Descending:
+		it.err = errors.Wrapf(ErrBadHistoryArgs, "order %s is not %s or %s", args.Order, Ascending, Descending)
+	case args.TxType != "" && args.TxType != AllTxs && args.TxType != SentTxs && args.TxType != ReceivedTxs:
+		it.err = errors.Wrapf(ErrBadHistoryArgs, "type %s is not %s, %s or %s", args.TxType, AllTxs, SentTxs, ReceivedTxs)
+	}
+	return it
+}
+
+// ValidatorIterator walks the information of the validators
+type ValidatorIterator struct {
+	*Iterator
+}
+
+// Item decodes the current validator
+func (it *ValidatorIterator) Item() (*ValidatorInformation, error) {
+	info := &ValidatorInformation{}
+	if err := it.decode(info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// IterateAllValidatorInformation walks the information of every validator, at the
+// given block or at the latest one when block is empty
+func (c *Client) IterateAllValidatorInformation(block string, options ...func(*Iterator)) *ValidatorIterator {
+	fetch := func(ctx context.Context, page int) ([]json.RawMessage, error) {
+		result := []json.RawMessage{}
+		if block == "" {
+			return result, c.call(ctx, rpc.Method.GetAllValidatorInformation, &result, page)
+		}
+		return result, c.call(ctx, rpc.Method.GetAllValidatorInformationByBlockNumber, &result, page, block)
+	}
+	return &ValidatorIterator{newIterator(fetch, 0, ValidatorsPageSize, options)}
+}
+
+// DelegationIterator walks delegations
+type DelegationIterator struct {
+	*Iterator
+}
+
+// Item decodes the current delegation
+func (it *DelegationIterator) Item() (*Delegation, error) {
+	delegation := &Delegation{}
+	if err := it.decode(delegation); err != nil {
+		return nil, err
+	}
+	return delegation, nil
+}
+
+// IterateDelegationsByDelegator walks the delegations made by addr. The node answers
+// them in a single page, the iterator gives them the same interface as the paged listings.
+func (c *Client) IterateDelegationsByDelegator(addr string, options ...func(*Iterator)) *DelegationIterator {
+	return c.iterateDelegations(rpc.Method.GetDelegationsByDelegator, addr, options)
+}
+
+// IterateDelegationsByValidator walks the delegations made to the validator addr
+func (c *Client) IterateDelegationsByValidator(addr string, options ...func(*Iterator)) *DelegationIterator {
+	return c.iterateDelegations(rpc.Method.GetDelegationsByValidator, addr, options)
+}
+
+func (c *Client) iterateDelegations(meth, addr string, options []func(*Iterator)) *DelegationIterator {
+	fetch := func(ctx context.Context, page int) ([]json.RawMessage, error) {
+		result := []json.RawMessage{}
+		return result, c.call(ctx, meth, &result, addr)
+	}
+	return &DelegationIterator{newIterator(fetch, 0, 0, options)}
+}
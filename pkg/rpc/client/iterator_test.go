@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+)
+
+// pagedMessenger serves a history of count hashes and as many validators, page by page
+type pagedMessenger struct {
+	count int
+	pages []int
+}
+
+func (m *pagedMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	return nil, fmt.Errorf("%s should be read raw", meth)
+}
+
+func (m *pagedMessenger) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	page, size, items := 0, ValidatorsPageSize, []string{}
+	switch meth {
+	case rpc.Method.GetTransactionsHistory:
+		args := params[0].(HistoryArgs)
+		page, size = int(args.PageIndex), int(args.PageSize)
+	case rpc.Method.GetAllValidatorInformation:
+		page = params[0].(int)
+	default:
+		return nil, fmt.Errorf("unexpected method %s", meth)
+	}
+	m.pages = append(m.pages, page)
+	for i := page * size; i < (page+1)*size && i < m.count; i++ {
+		if meth == rpc.Method.GetTransactionsHistory {
+			items = append(items, fmt.Sprintf(`"0x%064x"`, i))
+		} else {
+			items = append(items, fmt.Sprintf(`{"validator":{"address":"one1validator%d"}}`, i))
+		}
+	}
+	result := "[" + strings.Join(items, ",") + "]"
+	if meth == rpc.Method.GetTransactionsHistory {
+		result = `{"transactions":` + result + `}`
+	}
+	return []byte(`{"jsonrpc":"2.0","id":"1","result":` + result + `}`), nil
+}
+
+func TestHistoryIteratorWalksEveryPage(t *testing.T) {
+	messenger := &pagedMessenger{count: 25}
+	it := NewClient(messenger).IterateTransactionsHistory(HistoryArgs{Address: "one1address", PageSize: 10})
+	seen := 0
+	for it.Next(context.Background()) {
+		item, err := it.Item()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.Hash.Big().Int64() != int64(seen) || item.Transaction != nil {
+			t.Errorf("unexpected item %d: %+v", seen, item)
+		}
+		seen++
+	}
+	if it.Err() != nil || seen != 25 || len(messenger.pages) != 3 {
+		t.Errorf("expected 25 items over 3 pages, got %d over %v, %v", seen, messenger.pages, it.Err())
+	}
+
+	// A last page which is full costs one more empty page
+	messenger = &pagedMessenger{count: 20}
+	it = NewClient(messenger).IterateTransactionsHistory(HistoryArgs{Address: "one1address", PageSize: 10})
+	for it.Next(context.Background()) {
+	}
+	if len(messenger.pages) != 3 {
+		t.Errorf("expected the walk to end on an empty page, fetched %v", messenger.pages)
+	}
+}
+
+func TestIteratorStopsEarly(t *testing.T) {
+	messenger := &pagedMessenger{count: 250}
+	it := NewClient(messenger).IterateAllValidatorInformation("", func(it *Iterator) { it.Limit = 120 })
+	seen := 0
+	for it.Next(context.Background()) {
+		info, err := it.Item()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Validator.Address != fmt.Sprintf("one1validator%d", seen) {
+			t.Errorf("unexpected validator %d: %s", seen, info.Validator.Address)
+		}
+		var raw map[string]interface{}
+		if err := json.Unmarshal(it.Raw(), &raw); err != nil {
+			t.Error(err)
+		}
+		seen++
+	}
+	if seen != 120 || len(messenger.pages) != 2 {
+		t.Errorf("expected 120 validators from 2 pages, got %d from %v", seen, messenger.pages)
+	}
+}
+
+func TestHistoryIteratorChecksArgs(t *testing.T) {
+	messenger := &pagedMessenger{}
+	it := NewClient(messenger).IterateTransactionsHistory(HistoryArgs{Address: "one1address", Order: "newest"})
+	if it.Next(context.Background()) || !errors.Is(it.Err(), ErrBadHistoryArgs) || len(messenger.pages) != 0 {
+		t.Errorf("expected the order to be refused before any call, got %v", it.Err())
+	}
+	it = NewClient(messenger).IterateTransactionsHistory(HistoryArgs{Address: "one1address", TxType: "sent", Order: "desc"})
+	it.Next(context.Background())
+	if it.Err() != nil {
+		t.Errorf("expected the type and order in lower case to be accepted, got %v", it.Err())
+	}
+}
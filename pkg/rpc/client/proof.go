@@ -0,0 +1,151 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrInvalidProof is returned for a proof whose nodes do not lead to the root
+	ErrInvalidProof = errors.New("invalid merkle proof")
+	// ErrProofMismatch is returned for a proof valid in itself but proving other values
+	// than those the node claims
+	ErrProofMismatch = errors.New("proven value differs from the claimed one")
+
+	emptyRoot     = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	emptyCodeHash = crypto.Keccak256Hash(nil)
+)
+
+// account is an entry of the state trie, as encoded by the nodes
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// Verify checks the account and storage proofs against the state root of a block. The
+// root is only as trustworthy as its source: taken from an untrusted node, it merely
+// proves that the node answers consistently.
+func (p *AccountProof) Verify(stateRoot common.Hash) error {
+	value, err := verifyProof(stateRoot, crypto.Keccak256(p.Address.Bytes()), p.AccountProof)
+	if err != nil {
+		return errors.Wrapf(err, "account %s", p.Address.Hex())
+	}
+	proven := account{Balance: new(big.Int), Root: emptyRoot, CodeHash: emptyCodeHash.Bytes()}
+	if value != nil {
+		if err := rlp.DecodeBytes(value, &proven); err != nil {
+			return errors.Wrapf(ErrInvalidProof, "account %s: %s", p.Address.Hex(), err)
+		}
+	}
+	balance := new(big.Int)
+	if p.Balance != nil {
+		balance = p.Balance.ToInt()
+	}
+	storageHash, codeHash := p.StorageHash, p.CodeHash
+	if value == nil && storageHash == (common.Hash{}) {
+		// An absent account may be reported with zero hashes
+		storageHash = emptyRoot
+	}
+	if value == nil && codeHash == (common.Hash{}) {
+		codeHash = emptyCodeHash
+	}
+	switch {
+	case proven.Balance.Cmp(balance) != 0:
+		return errors.Wrapf(ErrProofMismatch, "balance of %s is %s, not %s", p.Address.Hex(), proven.Balance, balance)
+	case proven.Nonce != uint64(p.Nonce):
+		return errors.Wrapf(ErrProofMismatch, "nonce of %s is %d, not %d", p.Address.Hex(), proven.Nonce, p.Nonce)
+	case proven.Root != storageHash:
+		return errors.Wrapf(ErrProofMismatch, "storage hash of %s is %s", p.Address.Hex(), proven.Root.Hex())
+	case !bytes.Equal(proven.CodeHash, codeHash.Bytes()):
+		return errors.Wrapf(ErrProofMismatch, "code hash of %s is %s", p.Address.Hex(), hexutil.Encode(proven.CodeHash))
+	}
+	for _, slot := range p.StorageProof {
+		if err := slot.Verify(proven.Root); err != nil {
+			return errors.Wrapf(err, "account %s", p.Address.Hex())
+		}
+	}
+	return nil
+}
+
+// Verify checks the storage proof against the storage root of the account
+func (s *StorageResult) Verify(storageRoot common.Hash) error {
+	key, err := hexutil.DecodeBig(s.Key)
+	if err != nil || key.BitLen() > 256 {
+		return errors.Wrapf(ErrInvalidProof, "storage key %s", s.Key)
+	}
+	value, err := verifyProof(storageRoot, crypto.Keccak256(common.BigToHash(key).Bytes()), s.Proof)
+	if err != nil {
+		return errors.Wrapf(err, "storage key %s", s.Key)
+	}
+	proven := new(big.Int)
+	if value != nil {
+		content := []byte{}
+		if err := rlp.DecodeBytes(value, &content); err != nil {
+			return errors.Wrapf(ErrInvalidProof, "storage key %s: %s", s.Key, err)
+		}
+		proven.SetBytes(content)
+	}
+	claimed := new(big.Int)
+	if s.Value != nil {
+		claimed = s.Value.ToInt()
+	}
+	if proven.Cmp(claimed) != 0 {
+		return errors.Wrapf(ErrProofMismatch, "storage key %s holds %s, not %s", s.Key, proven, claimed)
+	}
+	return nil
+}
+
+// verifyProof walks the nodes of the proof from root to the value of key, nil when the
+// proof shows the key absent
+func verifyProof(root common.Hash, key []byte, proof []string) ([]byte, error) {
+	nodes := memorydb.New()
+	for _, encoded := range proof {
+		node, err := hexutil.Decode(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidProof, "node %s", encoded)
+		}
+		nodes.Put(crypto.Keccak256(node), node)
+	}
+	value, _, err := trie.VerifyProof(root, key, nodes)
+	if err != nil {
+		return nil, errors.Wrap(ErrInvalidProof, err.Error())
+	}
+	return value, nil
+}
+
+// GetVerifiedProof returns the proof of the account addr and of its storage slots keys,
+// once checked against the state root of the block the node gives
+func (c *Client) GetVerifiedProof(
+	ctx context.Context, addr string, keys []string, block string,
+) (*AccountProof, *Block, error) {
+	header, err := c.GetBlockByNumber(ctx, block, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if header.Number == nil {
+		return nil, nil, errors.Errorf("no block %s", block)
+	}
+	// The proof is asked at the number of the block, the head may move in between
+	proof, err := c.GetProof(ctx, addr, keys, hexutil.EncodeBig(header.Number.ToInt()))
+	if err != nil {
+		return nil, nil, err
+	}
+	if proof.Address != address.Parse(addr) {
+		return nil, header, errors.Wrapf(ErrProofMismatch, "proof of %s instead of %s", proof.Address.Hex(), addr)
+	}
+	if err := proof.Verify(header.StateRoot); err != nil {
+		return nil, header, err
+	}
+	return proof, header, nil
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// proofNodes collects the nodes written by trie.Prove
+type proofNodes []string
+
+func (p *proofNodes) Put(key, value []byte) error {
+	*p = append(*p, hexutil.Encode(value))
+	return nil
+}
+
+func (p *proofNodes) Delete(key []byte) error {
+	return nil
+}
+
+func newTrie(t *testing.T, entries map[string][]byte) *trie.Trie {
+	t.Helper()
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range entries {
+		tr.Update(crypto.Keccak256([]byte(key)), value)
+	}
+	return tr
+}
+
+func prove(t *testing.T, tr *trie.Trie, key []byte) []string {
+	t.Helper()
+	nodes := proofNodes{}
+	if err := tr.Prove(crypto.Keccak256(key), 0, &nodes); err != nil {
+		t.Fatal(err)
+	}
+	return nodes
+}
+
+func TestVerifyAccountProof(t *testing.T) {
+	slot := common.BigToHash(big.NewInt(1))
+	slotValue, _ := rlp.EncodeToBytes(big.NewInt(42).Bytes())
+	storage := newTrie(t, map[string][]byte{string(slot.Bytes()): slotValue})
+
+	owner := common.HexToAddress("0x0B585F8DaEfBC68a311FbD4cB20d9174aD174016")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	balance := new(big.Int).Mul(big.NewInt(5), big.NewInt(1e18))
+	codeHash := crypto.Keccak256Hash([]byte("code"))
+	encoded, _ := rlp.EncodeToBytes(account{Nonce: 3, Balance: balance, Root: storage.Hash(), CodeHash: codeHash.Bytes()})
+	otherEncoded, _ := rlp.EncodeToBytes(account{Balance: big.NewInt(1), Root: emptyRoot, CodeHash: emptyCodeHash.Bytes()})
+	state := newTrie(t, map[string][]byte{string(owner.Bytes()): encoded, string(other.Bytes()): otherEncoded})
+
+	valid := func() *AccountProof {
+		return &AccountProof{
+			Address:      owner,
+			AccountProof: prove(t, state, owner.Bytes()),
+			Balance:      (*hexutil.Big)(balance),
+			CodeHash:     codeHash,
+			Nonce:        3,
+			StorageHash:  storage.Hash(),
+			StorageProof: []StorageResult{{
+				Key: "0x1", Value: (*hexutil.Big)(big.NewInt(42)), Proof: prove(t, storage, slot.Bytes()),
+			}},
+		}
+	}
+	if err := valid().Verify(state.Hash()); err != nil {
+		t.Fatalf("expected the proof to hold, got %v", err)
+	}
+
+	lying := valid()
+	lying.Balance = (*hexutil.Big)(big.NewInt(1))
+	if err := lying.Verify(state.Hash()); !errors.Is(err, ErrProofMismatch) {
+		t.Errorf("expected a claimed balance differing from the proof to be caught, got %v", err)
+	}
+	lying = valid()
+	lying.StorageProof[0].Value = (*hexutil.Big)(big.NewInt(43))
+	if err := lying.Verify(state.Hash()); !errors.Is(err, ErrProofMismatch) {
+		t.Errorf("expected a claimed storage value differing from the proof to be caught, got %v", err)
+	}
+	if err := valid().Verify(common.HexToHash("0x01")); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("expected a proof for another state root to be refused, got %v", err)
+	}
+
+	absent := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	missing := &AccountProof{Address: absent, AccountProof: prove(t, state, absent.Bytes()), Balance: (*hexutil.Big)(big.NewInt(0))}
+	if err := missing.Verify(state.Hash()); err != nil {
+		t.Errorf("expected the absence of the account to be proven, got %v", err)
+	}
+	missing.Balance = (*hexutil.Big)(big.NewInt(1))
+	if err := missing.Verify(state.Hash()); !errors.Is(err, ErrProofMismatch) {
+		t.Errorf("expected a balance claimed for an absent account to be caught, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+)
+
+// GetAllValidatorAddresses returns the bech32 addresses of every validator
+func (c *Client) GetAllValidatorAddresses(ctx context.Context) ([]string, error) {
+	result := []string{}
+	err := c.call(ctx, rpc.Method.GetAllValidatorAddresses, &result)
+	return result, err
+}
+
+// GetElectedValidatorAddresses returns the bech32 addresses of the validators elected this epoch
+func (c *Client) GetElectedValidatorAddresses(ctx context.Context) ([]string, error) {
+	result := []string{}
+	err := c.call(ctx, rpc.Method.GetElectedValidatorAddresses, &result)
+	return result, err
+}
+
+// GetValidatorInformation returns the current state of a validator
+func (c *Client) GetValidatorInformation(ctx context.Context, addr string) (*ValidatorInformation, error) {
+	result := &ValidatorInformation{}
+	if err := c.call(ctx, rpc.Method.GetValidatorInformation, result, addr); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetValidatorInformationByBlockNumber returns the state of a validator at block
+func (c *Client) GetValidatorInformationByBlockNumber(
+	ctx context.Context, addr, block string,
+) (*ValidatorInformation, error) {
+	result := &ValidatorInformation{}
+	if err := c.call(ctx, rpc.Method.GetValidatorInformationByBlockNumber, result, addr, block); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAllValidatorInformation returns a page of the validators, every one of them for page -1
+func (c *Client) GetAllValidatorInformation(ctx context.Context, page int) ([]ValidatorInformation, error) {
+	result := []ValidatorInformation{}
+	err := c.call(ctx, rpc.Method.GetAllValidatorInformation, &result, page)
+	return result, err
+}
+
+// GetAllValidatorInformationByBlockNumber returns a page of the validators at block
+func (c *Client) GetAllValidatorInformationByBlockNumber(
+	ctx context.Context, page int, block string,
+) ([]ValidatorInformation, error) {
+	result := []ValidatorInformation{}
+	err := c.call(ctx, rpc.Method.GetAllValidatorInformationByBlockNumber, &result, page, block)
+	return result, err
+}
+
+// GetDelegationsByDelegator returns the delegations made by addr
+func (c *Client) GetDelegationsByDelegator(ctx context.Context, addr string) ([]Delegation, error) {
+	result := []Delegation{}
+	err := c.call(ctx, rpc.Method.GetDelegationsByDelegator, &result, addr)
+	return result, err
+}
+
+// GetDelegationsByValidator returns the delegations made to the validator addr
+func (c *Client) GetDelegationsByValidator(ctx context.Context, addr string) ([]Delegation, error) {
+	result := []Delegation{}
+	err := c.call(ctx, rpc.Method.GetDelegationsByValidator, &result, addr)
+	return result, err
+}
+
+// GetCurrentStakingErrorSink returns the staking transactions recently rejected by the node
+func (c *Client) GetCurrentStakingErrorSink(ctx context.Context) ([]SinkError, error) {
+	result := []SinkError{}
+	err := c.call(ctx, rpc.Method.GetCurrentStakingErrorSink, &result)
+	return result, err
+}
+
+// GetMedianRawStakeSnapshot returns the outcome of the election run on the current stakes
+func (c *Client) GetMedianRawStakeSnapshot(ctx context.Context) (*MedianStakeSnapshot, error) {
+	result := &MedianStakeSnapshot{}
+	if err := c.call(ctx, rpc.Method.GetMedianRawStakeSnapshot, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCurrentUtilityMetrics returns the staking figures driving the block reward
+func (c *Client) GetCurrentUtilityMetrics(ctx context.Context) (*UtilityMetrics, error) {
+	result := &UtilityMetrics{}
+	if err := c.call(ctx, rpc.Method.GetCurrentUtilityMetrics, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetSuperCommittees returns the previous and current committees of every shard, undecoded
+func (c *Client) GetSuperCommittees(ctx context.Context) (json.RawMessage, error) {
+	result := json.RawMessage{}
+	err := c.call(ctx, rpc.Method.GetSuperCommmittees, &result)
+	return result, err
+}
+
+// GetValidatorKeys returns the BLS public keys of the committee of the node shard at
+// epoch, in the order of the committee slots
+func (c *Client) GetValidatorKeys(ctx context.Context, epoch uint64) ([]string, error) {
+	result := []string{}
+	err := c.call(ctx, rpc.Method.GetValidatorKeys, &result, epoch)
+	return result, err
+}
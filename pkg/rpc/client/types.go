@@ -0,0 +1,385 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// quantity decodes the numbers nodes send either as hex strings or as JSON numbers
+type quantity struct {
+	big.Int
+}
+
+func (q *quantity) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		base, digits := 10, s
+		if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+			base, digits = 16, s[2:]
+		}
+		if digits == "" {
+			digits = "0"
+		}
+		if _, ok := q.SetString(digits, base); !ok {
+			return errors.Errorf("invalid quantity %s", s)
+		}
+		return nil
+	}
+	return q.Int.UnmarshalJSON(data)
+}
+
+func (q *quantity) uint64() (uint64, error) {
+	if !q.IsUint64() {
+		return 0, errors.Errorf("quantity %s does not fit in 64 bits", q.String())
+	}
+	return q.Uint64(), nil
+}
+
+// Block is a block as returned by GetBlockByNumber and GetBlockByHash, Transactions and
+// StakingTransactions are only filled in when the full transactions were asked for
+type Block struct {
+	Number                   *hexutil.Big   `json:"number"`
+	ViewID                   *hexutil.Big   `json:"viewID"`
+	Epoch                    *hexutil.Big   `json:"epoch"`
+	Hash                     common.Hash    `json:"hash"`
+	ParentHash               common.Hash    `json:"parentHash"`
+	MixHash                  common.Hash    `json:"mixHash"`
+	StateRoot                common.Hash    `json:"stateRoot"`
+	Miner                    string         `json:"miner"`
+	ExtraData                hexutil.Bytes  `json:"extraData"`
+	Size                     hexutil.Uint64 `json:"size"`
+	GasLimit                 hexutil.Uint64 `json:"gasLimit"`
+	GasUsed                  hexutil.Uint64 `json:"gasUsed"`
+	Timestamp                hexutil.Uint64 `json:"timestamp"`
+	TransactionsRoot         common.Hash    `json:"transactionsRoot"`
+	ReceiptsRoot             common.Hash    `json:"receiptsRoot"`
+	Signers                  []string       `json:"signers,omitempty"`
+	TransactionHashes        []common.Hash  `json:"-"`
+	StakingTransactionHashes []common.Hash  `json:"-"`
+	Transactions             []Transaction
+	StakingTransactions      []StakingTransaction
+}
+
+// UnmarshalJSON reads the transactions either as hashes or as full objects
+func (b *Block) UnmarshalJSON(data []byte) error {
+	type plain Block
+	block := struct {
+		*plain
+		Transactions        json.RawMessage `json:"transactions"`
+		StakingTransactions json.RawMessage `json:"stakingTransactions"`
+	}{plain: (*plain)(b)}
+	if err := json.Unmarshal(data, &block); err != nil {
+		return err
+	}
+	if err := hashesOrObjects(block.Transactions, &b.TransactionHashes, &b.Transactions); err != nil {
+		return err
+	}
+	return hashesOrObjects(block.StakingTransactions, &b.StakingTransactionHashes, &b.StakingTransactions)
+}
+
+// hashesOrObjects decodes a list which nodes send as hashes or as full objects
+func hashesOrObjects(data json.RawMessage, hashes *[]common.Hash, objects interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	if json.Unmarshal(data, hashes) == nil {
+		return nil
+	}
+	*hashes = nil
+	return json.Unmarshal(data, objects)
+}
+
+// Header is the summary of a block returned by GetLatestBlockHeader
+type Header struct {
+	BlockHash        common.Hash `json:"blockHash"`
+	BlockNumber      uint64      `json:"blockNumber"`
+	ShardID          uint32      `json:"shardID"`
+	Leader           string      `json:"leader"`
+	ViewID           uint64      `json:"viewID"`
+	Epoch            uint64      `json:"epoch"`
+	Timestamp        string      `json:"timestamp"`
+	UnixTime         uint64      `json:"unixtime"`
+	LastCommitSig    string      `json:"lastCommitSig"`
+	LastCommitBitmap string      `json:"lastCommitBitmap"`
+	CrossLinks       []CrossLink `json:"crossLinks,omitempty"`
+}
+
+// ChainHeaders are the latest headers of the beacon chain and of the node shard
+type ChainHeaders struct {
+	BeaconHeader json.RawMessage `json:"beacon-chain-header"`
+	ShardHeader  json.RawMessage `json:"shard-chain-header"`
+}
+
+// CrossLink is the commitment of a shard block recorded on the beacon chain
+type CrossLink struct {
+	Hash        common.Hash `json:"hash"`
+	BlockNumber *big.Int    `json:"block-number"`
+	ViewID      *big.Int    `json:"view-id"`
+	Signature   string      `json:"signature"`
+	Bitmap      string      `json:"signature-bitmap"`
+	ShardID     uint32      `json:"shard-id"`
+	EpochNumber *big.Int    `json:"epoch-number"`
+}
+
+// Transaction is a plain transaction, with its location in the chain once mined
+type Transaction struct {
+	BlockHash        common.Hash    `json:"blockHash"`
+	BlockNumber      *hexutil.Big   `json:"blockNumber"`
+	From             string         `json:"from"`
+	Timestamp        hexutil.Uint64 `json:"timestamp"`
+	Gas              hexutil.Uint64 `json:"gas"`
+	GasPrice         *hexutil.Big   `json:"gasPrice"`
+	Hash             common.Hash    `json:"hash"`
+	Input            hexutil.Bytes  `json:"input"`
+	Nonce            hexutil.Uint64 `json:"nonce"`
+	To               string         `json:"to"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	Value            *hexutil.Big   `json:"value"`
+	ShardID          uint32         `json:"shardID"`
+	ToShardID        uint32         `json:"toShardID"`
+	V                *hexutil.Big   `json:"v"`
+	R                *hexutil.Big   `json:"r"`
+	S                *hexutil.Big   `json:"s"`
+}
+
+// StakingTransaction is a staking transaction, Msg depends on its Type
+type StakingTransaction struct {
+	BlockHash        common.Hash     `json:"blockHash"`
+	BlockNumber      *hexutil.Big    `json:"blockNumber"`
+	From             string          `json:"from"`
+	Timestamp        hexutil.Uint64  `json:"timestamp"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Hash             common.Hash     `json:"hash"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
+	V                *hexutil.Big    `json:"v"`
+	R                *hexutil.Big    `json:"r"`
+	S                *hexutil.Big    `json:"s"`
+	Type             string          `json:"type"`
+	Msg              json.RawMessage `json:"msg"`
+}
+
+// Receipt is the outcome of a mined transaction. From, To and ShardID are set for plain
+// transactions, Sender and Type for staking ones.
+type Receipt struct {
+	BlockHash         common.Hash        `json:"blockHash"`
+	TransactionHash   common.Hash        `json:"transactionHash"`
+	BlockNumber       hexutil.Uint64     `json:"blockNumber"`
+	TransactionIndex  hexutil.Uint64     `json:"transactionIndex"`
+	GasUsed           hexutil.Uint64     `json:"gasUsed"`
+	CumulativeGasUsed hexutil.Uint64     `json:"cumulativeGasUsed"`
+	ContractAddress   common.Address     `json:"contractAddress"`
+	Logs              []Log              `json:"logs"`
+	ShardID           uint32             `json:"shardID"`
+	From              string             `json:"from"`
+	To                string             `json:"to"`
+	Sender            string             `json:"sender"`
+	Type              *staking.Directive `json:"type,omitempty"`
+	Root              hexutil.Bytes      `json:"root"`
+	Status            hexutil.Uint       `json:"status"`
+}
+
+// Succeeded tells whether the transaction was applied
+func (r *Receipt) Succeeded() bool {
+	return r.Status == 1
+}
+
+// CXReceipt is the part of a cross shard transaction its destination shard applies
+type CXReceipt struct {
+	BlockHash   common.Hash  `json:"blockHash"`
+	BlockNumber *hexutil.Big `json:"blockNumber"`
+	TxHash      common.Hash  `json:"hash"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	ShardID     uint32       `json:"shardID"`
+	ToShardID   uint32       `json:"toShardID"`
+	Value       *hexutil.Big `json:"value"`
+}
+
+// Log is an event emitted by a contract
+type Log struct {
+	Address          common.Address `json:"address"`
+	Topics           []common.Hash  `json:"topics"`
+	Data             hexutil.Bytes  `json:"data"`
+	BlockNumber      hexutil.Uint64 `json:"blockNumber"`
+	TransactionHash  common.Hash    `json:"transactionHash"`
+	TransactionIndex hexutil.Uint   `json:"transactionIndex"`
+	BlockHash        common.Hash    `json:"blockHash"`
+	LogIndex         hexutil.Uint   `json:"logIndex"`
+	Removed          bool           `json:"removed"`
+}
+
+// SyncProgress is the state of a node catching up with the chain
+type SyncProgress struct {
+	StartingBlock hexutil.Uint64 `json:"startingBlock"`
+	CurrentBlock  hexutil.Uint64 `json:"currentBlock"`
+	HighestBlock  hexutil.Uint64 `json:"highestBlock"`
+}
+
+// CallArgs are the fields of a message run by Call and EstimateGas, or sent by SendTransaction
+type CallArgs struct {
+	From     string          `json:"from,omitempty"`
+	To       string          `json:"to,omitempty"`
+	Gas      *hexutil.Uint64 `json:"gas,omitempty"`
+	GasPrice *hexutil.Big    `json:"gasPrice,omitempty"`
+	Value    *hexutil.Big    `json:"value,omitempty"`
+	Nonce    *hexutil.Uint64 `json:"nonce,omitempty"`
+	Data     hexutil.Bytes   `json:"data,omitempty"`
+}
+
+// AccountProof is the Merkle proof of an account and of some of its storage slots
+type AccountProof struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the Merkle proof of a storage slot
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// HistoryArgs selects a page of the transactions of an address
+type HistoryArgs struct {
+	Address   string `json:"address"`
+	PageIndex uint32 `json:"pageIndex"`
+	PageSize  uint32 `json:"pageSize"`
+	FullTx    bool   `json:"fullTx"`
+	TxType    string `json:"txType"`
+	Order     string `json:"order"`
+}
+
+// TransactionsHistory is a page of the transactions of an address, Transactions is
+// only filled in when the full transactions were asked for
+type TransactionsHistory struct {
+	TransactionHashes []common.Hash
+	Transactions      []Transaction
+}
+
+// UnmarshalJSON reads the transactions either as hashes or as full objects
+func (h *TransactionsHistory) UnmarshalJSON(data []byte) error {
+	history := struct {
+		Transactions json.RawMessage `json:"transactions"`
+	}{}
+	if err := json.Unmarshal(data, &history); err != nil {
+		return err
+	}
+	return hashesOrObjects(history.Transactions, &h.TransactionHashes, &h.Transactions)
+}
+
+// NodeMetadata describes the node answering the calls
+type NodeMetadata struct {
+	BLSPublicKey   []string        `json:"blskey"`
+	Version        string          `json:"version"`
+	NetworkType    string          `json:"network"`
+	ChainConfig    json.RawMessage `json:"chain-config"`
+	IsLeader       bool            `json:"is-leader"`
+	ShardID        uint32          `json:"shard-id"`
+	CurrentEpoch   uint64          `json:"current-epoch"`
+	BlocksPerEpoch *uint64         `json:"blocks-per-epoch,omitempty"`
+	Role           string          `json:"role"`
+	DNSZone        string          `json:"dns-zone"`
+	Archival       bool            `json:"is-archival"`
+	NodeBootTime   int64           `json:"node-unix-start-time"`
+	PeerID         string          `json:"peerid"`
+}
+
+// Validator is the on-chain record of a validator, addresses are in bech32
+type Validator struct {
+	Address              string       `json:"address"`
+	SlotPubKeys          []string     `json:"bls-public-keys"`
+	LastEpochInCommittee *big.Int     `json:"last-epoch-in-committee"`
+	MinSelfDelegation    *big.Int     `json:"min-self-delegation"`
+	MaxTotalDelegation   *big.Int     `json:"max-total-delegation"`
+	UpdateHeight         *big.Int     `json:"update-height"`
+	CreationHeight       *big.Int     `json:"creation-height"`
+	Delegations          []Delegation `json:"delegations"`
+	staking.CommissionRates
+	staking.Description
+}
+
+// Delegation is the stake of a delegator on a validator
+type Delegation struct {
+	ValidatorAddress string                 `json:"validator_address"`
+	DelegatorAddress string                 `json:"delegator_address"`
+	Amount           *big.Int               `json:"amount"`
+	Reward           *big.Int               `json:"reward"`
+	Undelegations    []staking.Undelegation `json:"undelegations"`
+}
+
+// UnmarshalJSON accepts both the naming of the delegation calls and the one
+// of the delegations embedded in the validator information
+func (d *Delegation) UnmarshalJSON(data []byte) error {
+	type plain Delegation
+	delegation := struct {
+		*plain
+		Delegator           string                 `json:"delegator-address"`
+		LegacyUndelegations []staking.Undelegation `json:"Undelegations"`
+	}{plain: (*plain)(d)}
+	if err := json.Unmarshal(data, &delegation); err != nil {
+		return err
+	}
+	if d.DelegatorAddress == "" {
+		d.DelegatorAddress = delegation.Delegator
+	}
+	if d.Undelegations == nil {
+		d.Undelegations = delegation.LegacyUndelegations
+	}
+	return nil
+}
+
+// ValidatorInformation is a validator with its performance and election status
+type ValidatorInformation struct {
+	Validator            Validator                        `json:"validator"`
+	Performance          *staking.CurrentEpochPerformance `json:"current-epoch-performance"`
+	TotalDelegated       *big.Int                         `json:"total-delegation"`
+	CurrentlyInCommittee bool                             `json:"currently-in-committee"`
+	EPoSStatus           string                           `json:"epos-status"`
+	EPoSWinningStake     *numeric.Dec                     `json:"epos-winning-stake"`
+	BootedStatus         *string                          `json:"booted-status"`
+	ActiveStatus         string                           `json:"active-status"`
+	Lifetime             json.RawMessage                  `json:"lifetime"`
+	Metrics              json.RawMessage                  `json:"metrics"`
+}
+
+// MedianStakeSnapshot is the outcome of the EPoS election computed on the current stakes
+type MedianStakeSnapshot struct {
+	MedianStake      numeric.Dec       `json:"epos-median-stake"`
+	MaxExternalSlots int               `json:"max-external-slots"`
+	Winners          []json.RawMessage `json:"epos-slot-winners"`
+	Candidates       []json.RawMessage `json:"epos-slot-candidates"`
+}
+
+// UtilityMetrics are the staking figures driving the block reward adjustment
+type UtilityMetrics struct {
+	AccumulatorSnapshot     *big.Int
+	CurrentStakedPercentage numeric.Dec
+	Deviation               numeric.Dec
+	Adjustment              numeric.Dec
+}
+
+// SinkError is a transaction rejected by the node, StakingDirective is only set for staking ones
+type SinkError struct {
+	TxHashID             string `json:"tx-hash-id"`
+	StakingDirective     string `json:"directive-kind,omitempty"`
+	ErrMessage           string `json:"error-message"`
+	TimestampOfRejection int64  `json:"time-at-rejection"`
+}
@@ -56,7 +56,9 @@ type RpcEnumList struct {
 	GetPendingCXReceipts                    RpcMethod
 	GetCurrentUtilityMetrics                RpcMethod
 	ResendCX                                RpcMethod
+	GetCXReceiptByHash                      RpcMethod
 	GetSuperCommmittees                     RpcMethod
+	GetValidatorKeys                        RpcMethod
 	GetCurrentBadBlocks                     RpcMethod
 	GetShardID                              RpcMethod
 	GetLastCrossLinks                       RpcMethod
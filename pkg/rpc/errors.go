@@ -0,0 +1,99 @@
+package rpc
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// RPCError is the error member of a JSON-RPC reply. errors.Is matches two RPCError by
+// code, so the sentinels below tell the kinds of failure apart whatever the message.
+type RPCError struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *RPCError) Error() string {
+	if e.Message == "" {
+		return codeToMessage(float64(e.Code))
+	}
+	return fmt.Sprintf("%s: %s", codeToMessage(float64(e.Code)), e.Message)
+}
+
+// Is reports whether target is an RPCError with the same code
+func (e *RPCError) Is(target error) bool {
+	t, ok := target.(*RPCError)
+	return ok && t.Code == e.Code
+}
+
+func sentinel(code errorCode) *RPCError {
+	return &RPCError{Code: int(code)}
+}
+
+// Sentinels for the codes of errorCodeEnumeration, to be used with errors.Is
+var (
+	ErrInvalidRequest      = sentinel(errorCodeEnumeration.rpcInvalidRequest)
+	ErrMethodNotFound      = sentinel(errorCodeEnumeration.rpcMethodNotFound)
+	ErrInvalidParams       = sentinel(errorCodeEnumeration.rpcInvalidParams)
+	ErrInternal            = sentinel(errorCodeEnumeration.rpcInternalError)
+	ErrParse               = sentinel(errorCodeEnumeration.rpcParseError)
+	ErrMisc                = sentinel(errorCodeEnumeration.rpcMiscError)
+	ErrType                = sentinel(errorCodeEnumeration.rpcTypeError)
+	ErrInvalidAddressOrKey = sentinel(errorCodeEnumeration.rpcInvalidAddressOrKey)
+	ErrInvalidParameter    = sentinel(errorCodeEnumeration.rpcInvalidParameter)
+	ErrDatabase            = sentinel(errorCodeEnumeration.rpcDatabaseError)
+	ErrDeserialization     = sentinel(errorCodeEnumeration.rpcDeserializationError)
+	ErrVerify              = sentinel(errorCodeEnumeration.rpcVerifyError)
+	ErrVerifyRejected      = sentinel(errorCodeEnumeration.rpcVerifyRejected)
+	ErrInWarmup            = sentinel(errorCodeEnumeration.rpcInWarmup)
+	ErrMethodDeprecated    = sentinel(errorCodeEnumeration.rpcMethodDeprecated)
+	ErrGeneric             = sentinel(errorCodeEnumeration.rpcGenericError)
+)
+
+// HTTPError is returned when the node answers with an HTTP status other than 200
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http status code not 200, received: %d", e.StatusCode)
+}
+
+// Retryable tells the failures worth another attempt: the transport ones, the HTTP
+// statuses of an overloaded or failing server and a node still warming up. Other RPC
+// errors are the node's answer to the call and would come back the same.
+func Retryable(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return errors.Is(rpcErr, ErrInWarmup)
+	}
+	return true
+}
+
+// liftError turns the error member of a reply into an RPCError, making do with
+// whatever the node sent when it is not the object the spec requires
+func liftError(oops interface{}) error {
+	if oops == nil {
+		return nil
+	}
+	fields, ok := oops.(map[string]interface{})
+	if !ok {
+		return &RPCError{Code: int(errorCodeEnumeration.rpcGenericError), Message: fmt.Sprint(oops)}
+	}
+	rpcErr := &RPCError{Code: int(errorCodeEnumeration.rpcGenericError), Data: fields["data"]}
+	if code, ok := fields["code"].(float64); ok {
+		rpcErr.Code = int(code)
+	}
+	if message, ok := fields["message"].(string); ok {
+		rpcErr.Message = message
+	} else if fields["message"] != nil {
+		rpcErr.Message = fmt.Sprint(fields["message"])
+	}
+	return rpcErr
+}
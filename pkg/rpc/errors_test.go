@@ -0,0 +1,53 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRPCErrorIs(t *testing.T) {
+	err := liftError(map[string]interface{}{"code": float64(-32601), "message": "the method foo does not exist"})
+	if !errors.Is(err, ErrMethodNotFound) || errors.Is(err, ErrVerifyRejected) {
+		t.Errorf("unexpected match for %v", err)
+	}
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Message != "the method foo does not exist" {
+		t.Errorf("expected the node message to be kept, got %v", err)
+	}
+	if Retryable(err) || !Retryable(liftError(map[string]interface{}{"code": float64(-28)})) {
+		t.Error("expected only the warmup error to be retryable")
+	}
+}
+
+func TestLiftMalformedErrors(t *testing.T) {
+	for _, oops := range []interface{}{
+		"plain string",
+		map[string]interface{}{"message": "no code"},
+		map[string]interface{}{"code": "-26", "message": 42},
+		[]interface{}{1, 2},
+	} {
+		if err := liftError(oops); !errors.Is(err, ErrGeneric) {
+			t.Errorf("expected a generic error for %v, got %v", oops, err)
+		}
+	}
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("overloaded"))
+	}))
+	defer server.Close()
+	_, err := Request(context.Background(), Method.BlockNumber, server.URL, []interface{}{})
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusServiceUnavailable ||
+		string(httpErr.Body) != "overloaded" {
+		t.Fatalf("expected an HTTP status error, got %v", err)
+	}
+	if !Retryable(err) {
+		t.Error("expected a 503 to be retryable")
+	}
+}
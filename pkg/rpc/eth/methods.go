@@ -65,7 +65,9 @@ var Method = rpcCommon.RpcEnumList{
 	GetPendingCXReceipts:                    fmt.Sprintf("%s_getPendingCXReceipts", prefix),
 	GetCurrentUtilityMetrics:                fmt.Sprintf("%s_getCurrentUtilityMetrics", prefix),
 	ResendCX:                                fmt.Sprintf("%s_resendCx", prefix),
+	GetCXReceiptByHash:                      fmt.Sprintf("%s_getCXReceiptByHash", prefix),
 	GetSuperCommmittees:                     fmt.Sprintf("%s_getSuperCommittees", prefix),
+	GetValidatorKeys:                        fmt.Sprintf("%s_getValidatorKeys", prefix),
 	GetCurrentBadBlocks:                     fmt.Sprintf("%s_getCurrentBadBlocks", prefix),
 	GetShardID:                              fmt.Sprintf("%s_getShardID", prefix),
 	GetLastCrossLinks:                       fmt.Sprintf("%s_getLastCrossLinks", prefix),
@@ -0,0 +1,320 @@
+package rpc
+
+import (
+	"context"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Strategy decides the order in which the endpoints of a FailoverMessenger are tried
+type Strategy int
+
+const (
+	// RoundRobin spreads the calls evenly over the healthy endpoints
+	RoundRobin Strategy = iota
+	// LatencyWeighted favors the healthy endpoints answering the fastest
+	LatencyWeighted
+)
+
+const (
+	defaultHealthCheckInterval = 30 * time.Second
+	healthCheckTimeout         = 5 * time.Second
+	defaultMaxBlockLag         = 5
+	latencySmoothing           = 0.3
+)
+
+var (
+	// ErrNoEndpoints is returned by a FailoverMessenger built without any endpoint
+	ErrNoEndpoints = errors.New("no rpc endpoint given")
+)
+
+// StringToStrategy parses the name of a Strategy, as given on the command line
+func StringToStrategy(name string) (Strategy, error) {
+	switch strings.ToLower(name) {
+	case "round-robin", "":
+		return RoundRobin, nil
+	case "latency":
+		return LatencyWeighted, nil
+	default:
+		return RoundRobin, errors.Errorf("unknown endpoint strategy %s, use round-robin or latency", name)
+	}
+}
+
+type endpoint struct {
+	node        string
+	messenger   T
+	healthy     bool
+	blockNumber uint64
+	latency     time.Duration
+}
+
+// EndpointStatus is the last known state of an endpoint of a FailoverMessenger
+type EndpointStatus struct {
+	Node        string        `json:"node"`
+	Healthy     bool          `json:"healthy"`
+	BlockNumber uint64        `json:"block-number"`
+	Latency     time.Duration `json:"latency"`
+}
+
+// FailoverMessenger is a T over several endpoints serving the same shard. Endpoints
+// that are down, syncing or lagging more than MaxBlockLag blocks behind the others
+// are routed around; a call failing on one endpoint is retried on the next one.
+type FailoverMessenger struct {
+	Strategy            Strategy
+	MaxBlockLag         uint64
+	HealthCheckInterval time.Duration
+
+	endpoints   []*endpoint
+	mu          sync.RWMutex
+	checkMu     sync.Mutex
+	lastCheck   time.Time
+	next        uint32
+	randomIndex func(int) int
+}
+
+// NewFailoverHandler creates a messenger balancing over the given HTTP nodes
+func NewFailoverHandler(nodes []string, options ...func(*FailoverMessenger)) *FailoverMessenger {
+	messengers := make(map[string]T, len(nodes))
+	for _, node := range nodes {
+		messengers[node] = NewHTTPHandler(node)
+	}
+	return NewFailoverMessenger(nodes, messengers, options...)
+}
+
+// NewFailoverMessenger creates a messenger balancing over the given messengers,
+// nodes gives their order and the names used to report their status
+func NewFailoverMessenger(
+	nodes []string, messengers map[string]T, options ...func(*FailoverMessenger),
+) *FailoverMessenger {
+	messenger := &FailoverMessenger{
+		Strategy:            RoundRobin,
+		MaxBlockLag:         defaultMaxBlockLag,
+		HealthCheckInterval: defaultHealthCheckInterval,
+		randomIndex:         rand.Intn,
+	}
+	for _, node := range nodes {
+		messenger.endpoints = append(messenger.endpoints, &endpoint{
+			node: node, messenger: messengers[node], healthy: true,
+		})
+	}
+	for _, option := range options {
+		option(messenger)
+	}
+	return messenger
+}
+
+// SendRPC sends the call to the best endpoint, falling over to the next ones on failure
+func (M *FailoverMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	var reply Reply
+	err := M.try(ctx, func(e *endpoint) error {
+		var err error
+		reply, err = e.messenger.SendRPC(ctx, meth, params)
+		return err
+	})
+	return reply, err
+}
+
+// SendBatch sends the batch to the best endpoint, falling over to the next ones on failure
+func (M *FailoverMessenger) SendBatch(ctx context.Context, batch []BatchElem) error {
+	return M.try(ctx, func(e *endpoint) error {
+		return SendBatch(ctx, e.messenger, batch)
+	})
+}
+
+// Status reports the last known state of each endpoint
+func (M *FailoverMessenger) Status() []EndpointStatus {
+	M.mu.RLock()
+	defer M.mu.RUnlock()
+	status := make([]EndpointStatus, len(M.endpoints))
+	for i, e := range M.endpoints {
+		status[i] = EndpointStatus{e.node, e.healthy, e.blockNumber, e.latency}
+	}
+	return status
+}
+
+// CheckHealth queries BlockNumber and Syncing on every endpoint, endpoints failing to
+// answer, still syncing or lagging behind the highest block seen are marked unhealthy.
+// Nothing is updated when ctx ends before the check does.
+func (M *FailoverMessenger) CheckHealth(ctx context.Context) {
+	M.checkMu.Lock()
+	defer M.checkMu.Unlock()
+	type result struct {
+		healthy     bool
+		blockNumber uint64
+		latency     time.Duration
+	}
+	results := make([]result, len(M.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range M.endpoints {
+		wg.Add(1)
+		go func(i int, e *endpoint) {
+			defer wg.Done()
+			start := time.Now()
+			reply, err := e.messenger.SendRPC(ctx, Method.BlockNumber, []interface{}{})
+			if err != nil {
+				return
+			}
+			latency := time.Since(start)
+			blockNumber, ok := replyToUint64(reply["result"])
+			if !ok {
+				return
+			}
+			syncing, err := e.messenger.SendRPC(ctx, Method.Syncing, []interface{}{})
+			if err != nil {
+				return
+			}
+			// Nodes answer false when in sync, a progress object otherwise
+			if inSync, ok := syncing["result"].(bool); syncing["result"] != nil && (!ok || inSync) {
+				return
+			}
+			results[i] = result{true, blockNumber, latency}
+		}(i, e)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		// The endpoints failed for the caller giving up, not for being unhealthy
+		return
+	}
+
+	highest := uint64(0)
+	for _, r := range results {
+		if r.healthy && r.blockNumber > highest {
+			highest = r.blockNumber
+		}
+	}
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	for i, e := range M.endpoints {
+		r := results[i]
+		e.healthy = r.healthy && highest-r.blockNumber <= M.MaxBlockLag
+		if r.healthy {
+			e.blockNumber = r.blockNumber
+			e.observe(r.latency)
+		}
+	}
+	M.lastCheck = time.Now()
+}
+
+func (M *FailoverMessenger) try(ctx context.Context, call func(*endpoint) error) error {
+	if len(M.endpoints) == 0 {
+		return ErrNoEndpoints
+	}
+	M.checkIfStale()
+	var err error
+	for _, e := range M.candidates() {
+		start := time.Now()
+		err = call(e)
+		if err == nil {
+			M.mu.Lock()
+			e.observe(time.Since(start))
+			M.mu.Unlock()
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if !Retryable(err) {
+			// The node answered the call, the other endpoints would answer the same
+			return err
+		}
+		M.mu.Lock()
+		e.healthy = false
+		M.mu.Unlock()
+	}
+	return err
+}
+
+func (M *FailoverMessenger) checkIfStale() {
+	M.mu.RLock()
+	stale := time.Since(M.lastCheck) > M.HealthCheckInterval
+	M.mu.RUnlock()
+	if stale {
+		// The check is for every later call, the deadline of this one must not cut it
+		checkCtx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		defer cancel()
+		M.CheckHealth(checkCtx)
+	}
+}
+
+// candidates orders the endpoints to try, healthy ones first according to the strategy
+func (M *FailoverMessenger) candidates() []*endpoint {
+	M.mu.RLock()
+	defer M.mu.RUnlock()
+	healthy, unhealthy := []*endpoint{}, []*endpoint{}
+	for _, e := range M.endpoints {
+		if e.healthy {
+			healthy = append(healthy, e)
+		} else {
+			unhealthy = append(unhealthy, e)
+		}
+	}
+	if len(healthy) > 1 {
+		switch M.Strategy {
+		case RoundRobin:
+			start := int(atomic.AddUint32(&M.next, 1)-1) % len(healthy)
+			healthy = append(healthy[start:], healthy[:start]...)
+		case LatencyWeighted:
+			sort.SliceStable(healthy, func(i, j int) bool {
+				return healthy[i].latency < healthy[j].latency
+			})
+			first := M.pickByLatency(healthy)
+			healthy[0], healthy[first] = healthy[first], healthy[0]
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// pickByLatency draws an endpoint with a probability inversely proportional to its latency
+func (M *FailoverMessenger) pickByLatency(endpoints []*endpoint) int {
+	weights := make([]int, len(endpoints))
+	total := 0
+	for i, e := range endpoints {
+		latency := e.latency
+		if latency < time.Millisecond {
+			latency = time.Millisecond
+		}
+		weights[i] = int(time.Hour / latency)
+		if weights[i] < 1 {
+			weights[i] = 1
+		}
+		total += weights[i]
+	}
+	draw := M.randomIndex(total)
+	for i, weight := range weights {
+		if draw < weight {
+			return i
+		}
+		draw -= weight
+	}
+	return 0
+}
+
+// observe folds a new sample in the moving average of the endpoint latency
+func (e *endpoint) observe(latency time.Duration) {
+	if e.latency == 0 {
+		e.latency = latency
+		return
+	}
+	e.latency = time.Duration(latencySmoothing*float64(latency) + (1-latencySmoothing)*float64(e.latency))
+}
+
+func replyToUint64(result interface{}) (uint64, bool) {
+	switch r := result.(type) {
+	case string:
+		n, ok := new(big.Int).SetString(strings.TrimPrefix(r, "0x"), 16)
+		if !ok || !n.IsUint64() {
+			return 0, false
+		}
+		return n.Uint64(), true
+	case float64:
+		return uint64(r), true
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeEndpoint answers BlockNumber with its block, Syncing with false and records the other calls
+type fakeEndpoint struct {
+	block   uint64
+	down    bool
+	reject  bool
+	handled int
+}
+
+func (f *fakeEndpoint) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	if f.down {
+		return nil, errors.New("connection refused")
+	}
+	switch meth {
+	case Method.BlockNumber:
+		return Reply{"result": fmt.Sprintf("0x%x", f.block)}, nil
+	case Method.Syncing:
+		return Reply{"result": false}, nil
+	}
+	f.handled++
+	if f.reject {
+		return nil, ErrorCodeToError("transaction rejected", -26)
+	}
+	return Reply{"result": "ok"}, nil
+}
+
+func newTestFailover(endpoints ...*fakeEndpoint) *FailoverMessenger {
+	nodes := []string{}
+	messengers := map[string]T{}
+	for i, e := range endpoints {
+		node := fmt.Sprintf("node-%d", i)
+		nodes = append(nodes, node)
+		messengers[node] = e
+	}
+	return NewFailoverMessenger(nodes, messengers)
+}
+
+func TestFailoverRoutesAroundUnhealthyEndpoints(t *testing.T) {
+	down, lagging, good := &fakeEndpoint{block: 100, down: true}, &fakeEndpoint{block: 10}, &fakeEndpoint{block: 100}
+	messenger := newTestFailover(down, lagging, good)
+	for i := 0; i < 4; i++ {
+		if _, err := messenger.SendRPC(context.Background(), Method.GetBalance, []interface{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if good.handled != 4 || lagging.handled != 0 {
+		t.Errorf("expected every call on the healthy endpoint, got %d and %d", good.handled, lagging.handled)
+	}
+	for i, status := range messenger.Status() {
+		if status.Healthy != (i == 2) {
+			t.Errorf("unexpected health for %s: %v", status.Node, status.Healthy)
+		}
+	}
+}
+
+func TestFailoverRetriesOnNextEndpoint(t *testing.T) {
+	first, second := &fakeEndpoint{block: 100}, &fakeEndpoint{block: 100}
+	messenger := newTestFailover(first, second)
+	messenger.CheckHealth(context.Background())
+	first.down = true
+	for i := 0; i < 2; i++ {
+		if _, err := messenger.SendRPC(context.Background(), Method.GetBalance, []interface{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if second.handled != 2 {
+		t.Errorf("expected both calls on the second endpoint, got %d", second.handled)
+	}
+	if messenger.Status()[0].Healthy {
+		t.Error("expected the endpoint that went down to be marked unhealthy")
+	}
+}
+
+func TestFailoverReturnsNodeErrors(t *testing.T) {
+	first, second := &fakeEndpoint{block: 100, reject: true}, &fakeEndpoint{block: 100, reject: true}
+	messenger := newTestFailover(first, second)
+	if _, err := messenger.SendRPC(context.Background(), Method.SendRawTransaction, []interface{}{}); err == nil {
+		t.Fatal("expected the node error to be returned")
+	}
+	if first.handled+second.handled != 1 {
+		t.Errorf("expected a rejected call not to be retried, got %d attempts", first.handled+second.handled)
+	}
+}
+
+func TestFailoverPicksAmongSlowEndpoints(t *testing.T) {
+	first, second := &fakeEndpoint{block: 100}, &fakeEndpoint{block: 100}
+	messenger := newTestFailover(first, second)
+	messenger.Strategy = LatencyWeighted
+	messenger.CheckHealth(context.Background())
+	for i, e := range messenger.endpoints {
+		e.latency = time.Duration(i+2) * time.Second
+	}
+	if _, err := messenger.SendRPC(context.Background(), Method.GetBalance, []interface{}{}); err != nil {
+		t.Fatal(err)
+	}
+	if first.handled+second.handled != 1 {
+		t.Errorf("expected the call on one of the slow endpoints, got %d attempts", first.handled+second.handled)
+	}
+}
+
+func TestFailoverKeepsHealthOfCancelledCheck(t *testing.T) {
+	first, second := &fakeEndpoint{block: 100, down: true}, &fakeEndpoint{block: 100}
+	messenger := newTestFailover(first, second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	messenger.CheckHealth(ctx)
+	if !messenger.Status()[0].Healthy || !messenger.lastCheck.IsZero() {
+		t.Error("expected a check cut by its context to leave the endpoints as they were")
+	}
+	messenger.CheckHealth(context.Background())
+	if messenger.Status()[0].Healthy {
+		t.Error("expected the endpoint that is down to be marked unhealthy")
+	}
+}
@@ -1,20 +1,54 @@
 package rpc
 
+import (
+	"context"
+	"encoding/json"
+)
+
 type Reply map[string]interface{}
 
+// T is the transport used by the SDK to talk to a node, every call is bound to a context
+// so that callers can give it a deadline or cancel it.
 type T interface {
-	SendRPC(string, []interface{}) (Reply, error)
+	SendRPC(context.Context, string, []interface{}) (Reply, error)
+}
+
+// RawT is implemented by the messengers able to hand back the undecoded reply, which keeps
+// the precision of the big numbers that a Reply turns into float64
+type RawT interface {
+	SendRawRPC(context.Context, string, []interface{}) ([]byte, error)
 }
 
 type HTTPMessenger struct {
 	node string
+	// Client carries the calls, DefaultHTTPClient unless set by an option
+	Client *HTTPClient
+}
+
+func (M *HTTPMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	return M.Client.Request(ctx, meth, M.node, params)
 }
 
-func (M *HTTPMessenger) SendRPC(meth string, params []interface{}) (Reply, error) {
-	return Request(meth, M.node, params)
+// SendRawRPC sends the call and returns the reply as received, once checked for an error member
+func (M *HTTPMessenger) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	rawReply, err := M.Client.RawRequest(ctx, meth, M.node, params)
+	if err != nil {
+		return nil, err
+	}
+	rpcJSON := make(map[string]interface{})
+	if err := json.Unmarshal(rawReply, &rpcJSON); err == nil {
+		if err := replyError(rpcJSON); err != nil {
+			return nil, err
+		}
+	}
+	return rawReply, nil
 }
 
-func NewHTTPHandler(node string) *HTTPMessenger {
+func NewHTTPHandler(node string, options ...func(*HTTPMessenger)) *HTTPMessenger {
 	// TODO Sanity check the URL for HTTP
-	return &HTTPMessenger{node}
+	messenger := &HTTPMessenger{node: node, Client: DefaultHTTPClient}
+	for _, option := range options {
+		option(messenger)
+	}
+	return messenger
 }
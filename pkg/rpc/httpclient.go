@@ -0,0 +1,226 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	defaultMaxConnsPerHost     = 512
+	defaultMaxIdleConnDuration = 10 * time.Second
+	defaultMaxConnWaitTimeout  = 30 * time.Second
+)
+
+// HTTPClient posts JSON-RPC calls through its own pool of connections, one per host.
+// It is safe for concurrent use; its fields are read on the first call and must not
+// change afterwards.
+type HTTPClient struct {
+	// MaxConnsPerHost bounds the connections open to a single node
+	MaxConnsPerHost int
+	// MaxConnWaitTimeout bounds the wait for a free connection once MaxConnsPerHost are
+	// busy, the deadline of the call bounding it as well
+	MaxConnWaitTimeout time.Duration
+	// Timeout bounds the calls whose context carries no deadline, 0 for none
+	Timeout time.Duration
+	// ReadTimeout and WriteTimeout bound the I/O on a connection, 0 for none
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// MaxIdleConnDuration is how long an idle connection is kept alive for reuse
+	MaxIdleConnDuration time.Duration
+	// DisableKeepAlive closes the connection after every call
+	DisableKeepAlive bool
+	// Headers are added to every call, e.g. the API key of a private RPC provider
+	Headers map[string]string
+	// TLSConfig is used for https nodes, set Certificates in it for client authentication
+	TLSConfig *tls.Config
+
+	queryID uint64
+	once    sync.Once
+	client  *fasthttp.Client
+}
+
+// NewHTTPClient creates a client, caller can control its configuration via options
+func NewHTTPClient(options ...func(*HTTPClient)) *HTTPClient {
+	client := &HTTPClient{
+		MaxConnsPerHost:     defaultMaxConnsPerHost,
+		MaxConnWaitTimeout:  defaultMaxConnWaitTimeout,
+		MaxIdleConnDuration: defaultMaxIdleConnDuration,
+		Headers:             map[string]string{},
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// SetBearerToken authenticates every call with the given token
+func (C *HTTPClient) SetBearerToken(token string) {
+	C.setHeader("Authorization", "Bearer "+token)
+}
+
+// SetBasicAuth authenticates every call with the given credentials
+func (C *HTTPClient) SetBasicAuth(user, password string) {
+	credentials := base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+	C.setHeader("Authorization", "Basic "+credentials)
+}
+
+// LoadClientCertificate presents the PEM certificate and key found in the given files
+// to the https nodes asking for client authentication
+func (C *HTTPClient) LoadClientCertificate(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return errors.Wrap(err, "could not load the client certificate")
+	}
+	if C.TLSConfig == nil {
+		C.TLSConfig = &tls.Config{}
+	}
+	C.TLSConfig.Certificates = append(C.TLSConfig.Certificates, cert)
+	return nil
+}
+
+func (C *HTTPClient) setHeader(key, value string) {
+	if C.Headers == nil {
+		C.Headers = map[string]string{}
+	}
+	C.Headers[key] = value
+}
+
+func (C *HTTPClient) fasthttpClient() *fasthttp.Client {
+	C.once.Do(func() {
+		C.client = &fasthttp.Client{
+			MaxConnsPerHost:     C.MaxConnsPerHost,
+			MaxConnWaitTimeout:  C.MaxConnWaitTimeout,
+			MaxIdleConnDuration: C.MaxIdleConnDuration,
+			ReadTimeout:         C.ReadTimeout,
+			WriteTimeout:        C.WriteTimeout,
+			TLSConfig:           C.TLSConfig,
+		}
+	})
+	return C.client
+}
+
+// nextID hands out the ids of the calls, unique for the client
+func (C *HTTPClient) nextID() string {
+	return strconv.FormatUint(atomic.AddUint64(&C.queryID, 1)-1, 10)
+}
+
+// Request sends the call to node and lifts the error member of the reply, if any
+func (C *HTTPClient) Request(ctx context.Context, method string, node string, params interface{}) (Reply, error) {
+	rpcJSON := make(map[string]interface{})
+	rawReply, err := C.RawRequest(ctx, method, node, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rawReply, &rpcJSON); err != nil {
+		return nil, errors.Wrap(err, "could not decode rpc reply")
+	}
+	if err := replyError(rpcJSON); err != nil {
+		return nil, err
+	}
+	return rpcJSON, nil
+}
+
+// RawRequest sends the call to node and returns the reply as received
+func (C *HTTPClient) RawRequest(ctx context.Context, method string, node string, params interface{}) ([]byte, error) {
+	requestBody, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": common.JSONRPCVersion,
+		"id":      C.nextID(),
+		"method":  method,
+		"params":  params,
+	})
+	return C.postRequest(ctx, node, requestBody)
+}
+
+func (C *HTTPClient) postRequest(ctx context.Context, node string, requestBody []byte) ([]byte, error) {
+	const contentType = "application/json"
+	if _, ok := ctx.Deadline(); !ok && C.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, C.Timeout)
+		defer cancel()
+	}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	req.SetBody(requestBody)
+	req.Header.SetMethodBytes(post)
+	req.Header.SetContentType(contentType)
+	for key, value := range C.Headers {
+		req.Header.Set(key, value)
+	}
+	if C.DisableKeepAlive {
+		req.SetConnectionClose()
+	}
+	req.SetRequestURIBytes([]byte(node))
+	res := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(res)
+	if err := C.doRequest(ctx, req, res); err != nil {
+		return nil, err
+	}
+	body := res.Body()
+	result := make([]byte, len(body))
+	copy(result, body)
+	if c := res.StatusCode(); c != 200 {
+		return nil, &HTTPError{StatusCode: c, Body: result}
+	}
+	if common.DebugRPC {
+		printRPCDebug(node, requestBody, result)
+	}
+	return result, nil
+}
+
+// doRequest performs the round-trip on copies of req and res so that it can be abandoned
+// when ctx is done, the deadline of ctx (if any) is also enforced on the connection itself.
+func (C *HTTPClient) doRequest(ctx context.Context, req *fasthttp.Request, res *fasthttp.Response) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	client := C.fasthttpClient()
+	reqCopy := fasthttp.AcquireRequest()
+	req.CopyTo(reqCopy)
+	resCopy := fasthttp.AcquireResponse()
+	release := func() {
+		fasthttp.ReleaseRequest(reqCopy)
+		fasthttp.ReleaseResponse(resCopy)
+	}
+	done := make(chan error, 1)
+	go func() {
+		// Once every connection to the host is busy, fasthttp queues the call until one
+		// is released, for MaxConnWaitTimeout at most and never past the deadline
+		if deadline, ok := ctx.Deadline(); ok {
+			done <- client.DoDeadline(reqCopy, resCopy, deadline)
+		} else {
+			done <- client.Do(reqCopy, resCopy)
+		}
+	}()
+	select {
+	case err := <-done:
+		defer release()
+		if err == fasthttp.ErrTimeout {
+			// The connection deadline is the one of ctx
+			return context.DeadlineExceeded
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		resCopy.CopyTo(res)
+		return nil
+	case <-ctx.Done():
+		go func() {
+			<-done
+			release()
+		}()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,56 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestHTTPClientConcurrentIDs(t *testing.T) {
+	var mu sync.Mutex
+	ids := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		ids[req["id"].(string)] = true
+		mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x1"})
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(func(C *HTTPClient) {
+		C.MaxConnsPerHost = 4
+		C.Headers["X-Api-Key"] = "key"
+	})
+	client.SetBearerToken("secret")
+	messenger := NewHTTPHandler(server.URL, func(M *HTTPMessenger) { M.Client = client })
+
+	const calls = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, calls)
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := messenger.SendRPC(context.Background(), Method.BlockNumber, []interface{}{}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if len(ids) != calls {
+		t.Errorf("expected %d distinct ids, got %d", calls, len(ids))
+	}
+}
@@ -5,7 +5,6 @@ import (
 
 	rpcCommon "github.com/harmony-one/go-sdk/pkg/rpc/common"
 	rpcV1 "github.com/harmony-one/go-sdk/pkg/rpc/v1"
-	"github.com/pkg/errors"
 )
 
 var (
@@ -67,7 +66,9 @@ type RpcEnumList struct {
 	GetPendingCXReceipts                    rpcCommon.RpcMethod
 	GetCurrentUtilityMetrics                rpcCommon.RpcMethod
 	ResendCX                                rpcCommon.RpcMethod
+	GetCXReceiptByHash                      rpcCommon.RpcMethod
 	GetSuperCommmittees                     rpcCommon.RpcMethod
+	GetValidatorKeys                        rpcCommon.RpcMethod
 	GetCurrentBadBlocks                     rpcCommon.RpcMethod
 	GetShardID                              rpcCommon.RpcMethod
 	GetLastCrossLinks                       rpcCommon.RpcMethod
@@ -141,9 +142,9 @@ const (
 	catchAllError            = "Catch all RPC error"
 )
 
-// ErrorCodeToError lifts an untyped error code from RPC to Error value
+// ErrorCodeToError lifts an untyped error code from RPC to an RPCError
 func ErrorCodeToError(message string, code float64) error {
-	return errors.Wrap(errors.New(message), codeToMessage(code))
+	return &RPCError{Code: int(code), Message: message}
 }
 
 // TODO Use reflection here instead of typing out the cases or at least a map
@@ -0,0 +1,139 @@
+package rpc
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Handler is the function form of T.SendRPC, the unit the middlewares wrap
+type Handler func(ctx context.Context, meth string, params []interface{}) (Reply, error)
+
+// Middleware decorates a Handler, it may look at or change the call, its reply and its error
+type Middleware func(Handler) Handler
+
+type chain struct {
+	handler Handler
+}
+
+// Chain wraps messenger in the middlewares, the first one given being the outermost.
+// Batches sent through the result go call by call so that every middleware sees them.
+func Chain(messenger T, middlewares ...Middleware) T {
+	handler := Handler(messenger.SendRPC)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return &chain{handler}
+}
+
+func (c *chain) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	return c.handler(ctx, meth, params)
+}
+
+// RetryPolicy configures the Retry middleware
+type RetryPolicy struct {
+	// Attempts is the total number of tries, the first one included
+	Attempts int
+	// Backoff is the base delay, doubled after every failed attempt up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retryable tells which errors are worth another attempt, all of them when nil.
+	// The package level Retryable leaves out the errors a node would answer again.
+	Retryable func(error) bool
+}
+
+// Retry tries a failing call again after a randomized exponential backoff
+// (full jitter), giving up once the policy attempts are spent or ctx is done
+func Retry(policy RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+			backoff := policy.Backoff
+			for attempt := 1; ; attempt++ {
+				reply, err := next(ctx, meth, params)
+				if err == nil || attempt >= policy.Attempts || ctx.Err() != nil ||
+					(policy.Retryable != nil && !policy.Retryable(err)) {
+					return reply, err
+				}
+				delay := time.Duration(0)
+				if backoff > 0 {
+					delay = time.Duration(rand.Int63n(int64(backoff)))
+				}
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(delay):
+				}
+				if backoff *= 2; policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+			}
+		}
+	}
+}
+
+// RateLimit holds the calls to at most perSecond on average with bursts of up to
+// burst calls, through a token bucket shared by every call of the chain. A perSecond
+// of 0 or less sets no limit.
+func RateLimit(perSecond float64, burst int) Middleware {
+	if perSecond <= 0 {
+		return func(next Handler) Handler { return next }
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	bucket := &tokenBucket{rate: perSecond, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+	return func(next Handler) Handler {
+		return func(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+			if err := bucket.wait(ctx); err != nil {
+				return nil, err
+			}
+			return next(ctx, meth, params)
+		}
+	}
+}
+
+// Timing reports the method, duration and outcome of every call to hook once it returns
+func Timing(hook func(meth string, elapsed time.Duration, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+			start := time.Now()
+			reply, err := next(ctx, meth, params)
+			hook(meth, time.Since(start), err)
+			return reply, err
+		}
+	}
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// wait takes a token, sleeping until one is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+		if b.tokens >= 1 {
+			b.tokens--
+			b.mu.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
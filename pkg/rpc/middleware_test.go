@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type flakyMessenger struct {
+	failures int
+	calls    int
+}
+
+func (f *flakyMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("connection reset")
+	}
+	return Reply{"result": meth}, nil
+}
+
+func TestChainOrder(t *testing.T) {
+	order := []string{}
+	tag := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+				order = append(order, name)
+				return next(ctx, meth, params)
+			}
+		}
+	}
+	messenger := Chain(&flakyMessenger{}, tag("outer"), tag("inner"))
+	if _, err := messenger.SendRPC(context.Background(), Method.BlockNumber, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("unexpected middleware order %v", order)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	flaky := &flakyMessenger{failures: 2}
+	var timed []error
+	messenger := Chain(flaky,
+		Retry(RetryPolicy{Attempts: 3, Backoff: time.Millisecond}),
+		Timing(func(meth string, elapsed time.Duration, err error) { timed = append(timed, err) }),
+	)
+	reply, err := messenger.SendRPC(context.Background(), Method.BlockNumber, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply["result"] != Method.BlockNumber || flaky.calls != 3 || len(timed) != 3 {
+		t.Errorf("expected 3 timed attempts, got %d calls and %d timings", flaky.calls, len(timed))
+	}
+
+	flaky = &flakyMessenger{failures: 5}
+	messenger = Chain(flaky, Retry(RetryPolicy{
+		Attempts: 3, Retryable: func(error) bool { return false },
+	}))
+	if _, err := messenger.SendRPC(context.Background(), Method.BlockNumber, nil); err == nil || flaky.calls != 1 {
+		t.Errorf("expected a single attempt for a non retryable error, got %d", flaky.calls)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	messenger := Chain(&flakyMessenger{}, RateLimit(20, 2))
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := messenger.SendRPC(context.Background(), Method.BlockNumber, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst covers two calls, the two others wait for a token each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("expected the calls to be held, took %s", elapsed)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := messenger.SendRPC(ctx, Method.BlockNumber, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+
+	unlimited := Chain(&flakyMessenger{}, RateLimit(0, 1))
+	start = time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := unlimited.SendRPC(context.Background(), Method.BlockNumber, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("expected a rate of 0 to hold no call, took %s", elapsed)
+	}
+}
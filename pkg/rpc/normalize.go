@@ -0,0 +1,75 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+	"github.com/pkg/errors"
+)
+
+// V2Messenger lets the code written against the hmy namespace call the hmyv2 one: the
+// params of hmyv2 calls are converted and their results rewritten in the hmy shape,
+// numbers becoming hex strings. Calls of the other namespaces go through untouched.
+type V2Messenger struct {
+	messenger T
+}
+
+// NewV2Messenger wraps messenger, normalizing the hmyv2 calls sent through it
+func NewV2Messenger(messenger T) *V2Messenger {
+	return &V2Messenger{messenger}
+}
+
+// SendRPC sends the call, decoding the normalized reply
+func (M *V2Messenger) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	if !rpcV2.IsMethod(meth) {
+		return M.messenger.SendRPC(ctx, meth, params)
+	}
+	rawReply, err := M.SendRawRPC(ctx, meth, params)
+	if err != nil {
+		return nil, err
+	}
+	reply := Reply{}
+	if err := json.Unmarshal(rawReply, &reply); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s reply", meth)
+	}
+	return reply, nil
+}
+
+// SendRawRPC sends the call, returning the reply with its result normalized. The reply
+// is read undecoded when the wrapped messenger allows it, so that big numbers keep their precision.
+func (M *V2Messenger) SendRawRPC(ctx context.Context, meth string, params []interface{}) ([]byte, error) {
+	if rpcV2.IsMethod(meth) {
+		params = rpcV2.Params(meth, params)
+	}
+	var rawReply []byte
+	if messenger, ok := M.messenger.(RawT); ok {
+		var err error
+		if rawReply, err = messenger.SendRawRPC(ctx, meth, params); err != nil {
+			return nil, err
+		}
+	} else {
+		reply, err := M.messenger.SendRPC(ctx, meth, params)
+		if err != nil {
+			return nil, err
+		}
+		if rawReply, err = json.Marshal(reply); err != nil {
+			return nil, errors.Wrapf(err, "could not encode %s reply", meth)
+		}
+	}
+	if !rpcV2.IsMethod(meth) {
+		return rawReply, nil
+	}
+	reply := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rawReply, &reply); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s reply", meth)
+	}
+	if result, ok := reply["result"]; ok {
+		normalized, err := rpcV2.Normalize(meth, result)
+		if err != nil {
+			return nil, err
+		}
+		reply["result"] = normalized
+	}
+	return json.Marshal(reply)
+}
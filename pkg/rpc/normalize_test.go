@@ -0,0 +1,63 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+)
+
+func TestV2MessengerNormalizes(t *testing.T) {
+	var blockArgs interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []interface{}   `json:"params"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			t.Error(err)
+			return
+		}
+		result := `"0x1"`
+		switch call.Method {
+		case rpcV2.Method.GetBalance:
+			result = `1000000000000000000000001`
+		case rpcV2.Method.GetBlockByNumber:
+			blockArgs = call.Params[1]
+			result = `{"number":16,"nonce":0,"stateRoot":"0x00","transactions":[{"nonce":7,"value":12}]}`
+		}
+		w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(call.ID) + `,"result":` + result + `}`))
+	}))
+	defer server.Close()
+
+	messenger := NewV2Messenger(NewHTTPHandler(server.URL))
+	reply, err := messenger.SendRPC(context.Background(), rpcV2.Method.GetBalance, []interface{}{"one1", "latest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply["result"] != "0xd3c21bcecceda1000001" {
+		t.Errorf("expected the balance as hex without loss, got %v", reply["result"])
+	}
+
+	reply, err = messenger.SendRPC(context.Background(), rpcV2.Method.GetBlockByNumber, []interface{}{"0x10", true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args, ok := blockArgs.(map[string]interface{}); !ok || args["fullTx"] != true {
+		t.Errorf("expected the fullTx flag sent as block arguments, got %v", blockArgs)
+	}
+	block := reply["result"].(map[string]interface{})
+	tx := block["transactions"].([]interface{})[0].(map[string]interface{})
+	if block["number"] != "0x10" || block["nonce"] != float64(0) || tx["nonce"] != "0x7" || tx["value"] != "0xc" {
+		t.Errorf("expected the block in the v1 shape, got %v", block)
+	}
+
+	reply, err = messenger.SendRPC(context.Background(), Method.GetBalance, []interface{}{"one1", "latest"})
+	if err != nil || reply["result"] != "0x1" {
+		t.Errorf("expected hmy calls to go through untouched, got %v %v", reply, err)
+	}
+}
@@ -1,80 +1,41 @@
 package rpc
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"strconv"
 	"time"
 
-	"github.com/valyala/fasthttp"
-
 	"github.com/harmony-one/go-sdk/pkg/common"
 )
 
 var (
-	queryID = 0
-	post    = []byte("POST")
+	post = []byte("POST")
+	// DefaultHTTPClient serves the package level Request, RawRequest and BatchRequest
+	// as well as the messengers created without a client of their own
+	DefaultHTTPClient = NewHTTPClient()
 )
 
-func baseRequest(method string, node string, params interface{}) ([]byte, error) {
-	requestBody, _ := json.Marshal(map[string]interface{}{
-		"jsonrpc": common.JSONRPCVersion,
-		"id":      strconv.Itoa(queryID),
-		"method":  method,
-		"params":  params,
-	})
-	const contentType = "application/json"
-	req := fasthttp.AcquireRequest()
-	req.SetBody(requestBody)
-	req.Header.SetMethodBytes(post)
-	req.Header.SetContentType(contentType)
-	req.SetRequestURIBytes([]byte(node))
-	res := fasthttp.AcquireResponse()
-	if err := fasthttp.Do(req, res); err != nil {
-		return nil, err
-	}
-	c := res.StatusCode()
-	if c != 200 {
-		return nil, fmt.Errorf("http status code not 200, received: %d", c)
-	}
-	fasthttp.ReleaseRequest(req)
-	body := res.Body()
-	result := make([]byte, len(body))
-	copy(result, body)
-	fasthttp.ReleaseResponse(res)
-	if common.DebugRPC {
-		reqB := common.JSONPrettyFormat(string(requestBody))
-		respB := common.JSONPrettyFormat(string(body))
-		fmt.Printf("Response Timestamp: %s\n", time.Now().String())
-		fmt.Printf("URL: %s, Request Body: %s\n\n", node, reqB)
-		fmt.Printf("URL: %s, Response Body: %s\n\n", node, respB)
-	}
-	queryID++
-	return result, nil
+func printRPCDebug(node string, requestBody, responseBody []byte) {
+	reqB := common.JSONPrettyFormat(string(requestBody))
+	respB := common.JSONPrettyFormat(string(responseBody))
+	fmt.Printf("Response Timestamp: %s\n", time.Now().String())
+	fmt.Printf("URL: %s, Request Body: %s\n\n", node, reqB)
+	fmt.Printf("URL: %s, Response Body: %s\n\n", node, respB)
 }
 
 // TODO Check if Method known, return error when not known, good intern task
 
 // Request processes
-func Request(method string, node string, params interface{}) (Reply, error) {
-	rpcJSON := make(map[string]interface{})
-	rawReply, err := baseRequest(method, node, params)
-	if err != nil {
-		return nil, err
-	}
-	json.Unmarshal(rawReply, &rpcJSON)
-	if oops := rpcJSON["error"]; oops != nil {
-		errNo := oops.(map[string]interface{})["code"].(float64)
-		errMessage := ""
-		if oops.(map[string]interface{})["message"] != nil {
-			errMessage = oops.(map[string]interface{})["message"].(string)
-		}
-		return nil, ErrorCodeToError(errMessage, errNo)
-	}
-	return rpcJSON, nil
+func Request(ctx context.Context, method string, node string, params interface{}) (Reply, error) {
+	return DefaultHTTPClient.Request(ctx, method, node, params)
+}
+
+// replyError lifts the error member of a JSON-RPC reply, if any
+func replyError(rpcJSON map[string]interface{}) error {
+	return liftError(rpcJSON["error"])
 }
 
 // RawRequest is to sidestep the lifting done by Request
-func RawRequest(method string, node string, params interface{}) ([]byte, error) {
-	return baseRequest(method, node, params)
+func RawRequest(ctx context.Context, method string, node string, params interface{}) ([]byte, error) {
+	return DefaultHTTPClient.RawRequest(ctx, method, node, params)
 }
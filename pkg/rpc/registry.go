@@ -0,0 +1,295 @@
+package rpc
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	rpcCommon "github.com/harmony-one/go-sdk/pkg/rpc/common"
+	rpcEth "github.com/harmony-one/go-sdk/pkg/rpc/eth"
+	rpcV1 "github.com/harmony-one/go-sdk/pkg/rpc/v1"
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+	"github.com/pkg/errors"
+)
+
+// ParamKind is the shape a parameter of an RPC method must have
+type ParamKind int
+
+const (
+	// AddressParam is a one1 bech32 or 0x hex address
+	AddressParam ParamKind = iota
+	// BlockParam is a block number, as a number or hex string, or one of the tags
+	// latest, earliest and pending
+	BlockParam
+	// HashParam is a 32 bytes 0x hex string
+	HashParam
+	// QuantityParam is a non-negative integer, as a number or hex string
+	QuantityParam
+	// BoolParam is true or false
+	BoolParam
+	// DataParam is a 0x hex string of any length
+	DataParam
+	// StringParam is any string
+	StringParam
+	// ObjectParam is a JSON object, such as the arguments of a call or a log filter
+	ObjectParam
+	// ArrayParam is a JSON array
+	ArrayParam
+)
+
+var paramKindNames = map[ParamKind]string{
+	AddressParam:  "address",
+	BlockParam:    "block",
+	HashParam:     "hash",
+	QuantityParam: "quantity",
+	BoolParam:     "bool",
+	DataParam:     "data",
+	StringParam:   "string",
+	ObjectParam:   "object",
+	ArrayParam:    "array",
+}
+
+func (k ParamKind) String() string {
+	return paramKindNames[k]
+}
+
+// Param describes a positional parameter of an RPC method
+type Param struct {
+	Name     string
+	Kind     ParamKind
+	Optional bool
+}
+
+func (p Param) String() string {
+	if p.Optional {
+		return fmt.Sprintf("[%s:%s]", p.Name, p.Kind)
+	}
+	return fmt.Sprintf("<%s:%s>", p.Name, p.Kind)
+}
+
+var (
+	// ErrUnknownMethod is returned for a method absent from the RpcEnumList
+	ErrUnknownMethod = errors.New("unknown rpc method")
+	// ErrBadParams is returned for params not matching the registry
+	ErrBadParams = errors.New("invalid params")
+)
+
+var (
+	argAddress  = Param{"address", AddressParam, false}
+	argBlock    = Param{"block", BlockParam, false}
+	argOptBlock = Param{"block", BlockParam, true}
+	argHash     = Param{"hash", HashParam, false}
+	argIndex    = Param{"index", QuantityParam, false}
+	argFullTx   = Param{"full-tx", BoolParam, false}
+	argCallArgs = Param{"args", ObjectParam, false}
+	argPage     = Param{"page", QuantityParam, false}
+	argRawTx    = Param{"raw-tx", DataParam, false}
+)
+
+// Registry describes the parameters of each method of RpcEnumList, keyed by field name
+var Registry = map[string][]Param{
+	"GetShardingStructure":                    {},
+	"GetBlockByHash":                          {argHash, argFullTx},
+	"GetBlockByNumber":                        {argBlock, argFullTx},
+	"GetBlockTransactionCountByHash":          {argHash},
+	"GetBlockTransactionCountByNumber":        {argBlock},
+	"GetCode":                                 {argAddress, argBlock},
+	"GetTransactionByBlockHashAndIndex":       {argHash, argIndex},
+	"GetTransactionByBlockNumberAndIndex":     {argBlock, argIndex},
+	"GetTransactionByHash":                    {argHash},
+	"GetStakingTransactionByHash":             {argHash},
+	"GetTransactionReceipt":                   {argHash},
+	"Syncing":                                 {},
+	"PeerCount":                               {},
+	"GetBalance":                              {argAddress, argBlock},
+	"GetStorageAt":                            {argAddress, {"key", QuantityParam, false}, argBlock},
+	"GetTransactionCount":                     {argAddress, argBlock},
+	"SendTransaction":                         {argCallArgs},
+	"SendRawTransaction":                      {argRawTx},
+	"Subscribe":                               {{"kind", StringParam, false}, {"filter", ObjectParam, true}},
+	"GetPastLogs":                             {{"filter", ObjectParam, false}},
+	"GetWork":                                 {},
+	"GetProof":                                {argAddress, {"keys", ArrayParam, false}, argBlock},
+	"GetFilterChanges":                        {{"filter-id", QuantityParam, false}},
+	"NewPendingTransactionFilter":             {},
+	"NewBlockFilter":                          {},
+	"NewFilter":                               {{"filter", ObjectParam, false}},
+	"Call":                                    {argCallArgs, argBlock},
+	"EstimateGas":                             {argCallArgs, argOptBlock},
+	"GasPrice":                                {},
+	"BlockNumber":                             {},
+	"UnSubscribe":                             {{"subscription-id", StringParam, false}},
+	"NetVersion":                              {},
+	"ProtocolVersion":                         {},
+	"GetNodeMetadata":                         {},
+	"GetLatestBlockHeader":                    {},
+	"SendRawStakingTransaction":               {argRawTx},
+	"GetElectedValidatorAddresses":            {},
+	"GetAllValidatorAddresses":                {},
+	"GetValidatorInformation":                 {argAddress},
+	"GetAllValidatorInformation":              {argPage},
+	"GetValidatorInformationByBlockNumber":    {argAddress, argBlock},
+	"GetAllValidatorInformationByBlockNumber": {argPage, argBlock},
+	"GetDelegationsByDelegator":               {argAddress},
+	"GetDelegationsByValidator":               {argAddress},
+	"GetCurrentTransactionErrorSink":          {},
+	"GetMedianRawStakeSnapshot":               {},
+	"GetCurrentStakingErrorSink":              {},
+	"GetTransactionsHistory":                  {{"args", ObjectParam, false}},
+	"GetPendingTxnsInPool":                    {},
+	"GetPendingCrosslinks":                    {},
+	"GetPendingCXReceipts":                    {},
+	"GetCurrentUtilityMetrics":                {},
+	"ResendCX":                                {argHash},
+	"GetCXReceiptByHash":                      {argHash},
+	"GetSuperCommmittees":                     {},
+	"GetValidatorKeys":                        {{"epoch", QuantityParam, false}},
+	"GetCurrentBadBlocks":                     {},
+	"GetShardID":                              {},
+	"GetLastCrossLinks":                       {},
+	"GetLatestChainHeaders":                   {},
+}
+
+// MethodNames lists the field names of RpcEnumList, in declaration order
+func MethodNames() []string {
+	t := reflect.TypeOf(rpcCommon.RpcEnumList{})
+	names := make([]string, t.NumField())
+	for i := range names {
+		names[i] = t.Field(i).Name
+	}
+	return names
+}
+
+// methodValue is the wire name of the field in the given list
+func methodValue(list rpcCommon.RpcEnumList, field string) string {
+	return reflect.ValueOf(list).FieldByName(field).String()
+}
+
+// LookupMethod resolves name, given as a field of RpcEnumList (GetBalance), as a wire
+// name without its prefix (getBalance) or as a full wire name (hmy_getBalance), to the
+// field and the name to send. The current Method gives the prefix unless the name
+// carries one of its own.
+func LookupMethod(name string) (field string, method string, err error) {
+	for _, field := range MethodNames() {
+		current := methodValue(Method, field)
+		if strings.EqualFold(field, name) || current == name ||
+			strings.EqualFold(current[strings.Index(current, "_")+1:], name) {
+			return field, current, nil
+		}
+	}
+	for _, list := range []rpcCommon.RpcEnumList{rpcV1.Method, rpcV2.Method, rpcEth.Method} {
+		for _, field := range MethodNames() {
+			if methodValue(list, field) == name {
+				return field, name, nil
+			}
+		}
+	}
+	return "", "", errors.Wrapf(ErrUnknownMethod, "%s", name)
+}
+
+// ValidateParams checks params against the registry entry of the field
+func ValidateParams(field string, params []interface{}) error {
+	spec, ok := Registry[field]
+	if !ok {
+		return errors.Wrapf(ErrUnknownMethod, "%s", field)
+	}
+	required := 0
+	for _, p := range spec {
+		if !p.Optional {
+			required++
+		}
+	}
+	if len(params) < required || len(params) > len(spec) {
+		return errors.Wrapf(ErrBadParams, "%s takes %s", field, Usage(field))
+	}
+	for i, value := range params {
+		if err := checkParam(spec[i].Kind, value); err != nil {
+			return errors.Wrapf(ErrBadParams, "%s %s: %s", field, spec[i], err)
+		}
+	}
+	return nil
+}
+
+// Usage describes the params of the field, such as <address:address> <block:block>
+func Usage(field string) string {
+	parts := []string{}
+	for _, p := range Registry[field] {
+		parts = append(parts, p.String())
+	}
+	if len(parts) == 0 {
+		return "no params"
+	}
+	return strings.Join(parts, " ")
+}
+
+func checkParam(kind ParamKind, value interface{}) error {
+	switch kind {
+	case AddressParam:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("expected a string")
+		}
+		if strings.HasPrefix(s, "one1") {
+			_, err := address.Bech32ToAddress(s)
+			return err
+		}
+		if b, err := hexutil.Decode(s); err != nil || len(b) != 20 {
+			return errors.New("expected a one1 or 0x address")
+		}
+	case BlockParam:
+		if s, ok := value.(string); ok && (s == "latest" || s == "earliest" || s == "pending") {
+			return nil
+		}
+		return checkParam(QuantityParam, value)
+	case HashParam:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("expected a string")
+		}
+		if b, err := hexutil.Decode(s); err != nil || len(b) != 32 {
+			return errors.New("expected a 32 bytes 0x hex string")
+		}
+	case QuantityParam:
+		switch v := value.(type) {
+		case float64:
+			if v < 0 || v != float64(int64(v)) {
+				return errors.New("expected a non-negative integer")
+			}
+		case string:
+			n, ok := new(big.Int).SetString(strings.TrimPrefix(v, "0x"), 16)
+			if !strings.HasPrefix(v, "0x") || !ok || n.Sign() < 0 {
+				return errors.New("expected a number or 0x hex string")
+			}
+		default:
+			return errors.New("expected a number or 0x hex string")
+		}
+	case BoolParam:
+		if _, ok := value.(bool); !ok {
+			return errors.New("expected true or false")
+		}
+	case DataParam:
+		s, ok := value.(string)
+		if !ok {
+			return errors.New("expected a string")
+		}
+		if _, err := hexutil.Decode(s); err != nil {
+			return errors.New("expected a 0x hex string")
+		}
+	case StringParam:
+		if _, ok := value.(string); !ok {
+			return errors.New("expected a string")
+		}
+	case ObjectParam:
+		if _, ok := value.(map[string]interface{}); !ok {
+			return errors.New("expected a JSON object")
+		}
+	case ArrayParam:
+		if _, ok := value.([]interface{}); !ok {
+			return errors.New("expected a JSON array")
+		}
+	}
+	return nil
+}
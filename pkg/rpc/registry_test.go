@@ -0,0 +1,72 @@
+package rpc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	rpcEth "github.com/harmony-one/go-sdk/pkg/rpc/eth"
+	rpcV2 "github.com/harmony-one/go-sdk/pkg/rpc/v2"
+)
+
+func TestRegistryCoversEveryMethod(t *testing.T) {
+	for _, field := range MethodNames() {
+		if _, ok := Registry[field]; !ok {
+			t.Errorf("%s is missing from the registry", field)
+		}
+	}
+	if len(Registry) != len(MethodNames()) {
+		t.Errorf("registry has %d entries for %d methods", len(Registry), len(MethodNames()))
+	}
+}
+
+func TestLookupMethod(t *testing.T) {
+	for _, name := range []string{"GetBalance", "getBalance", "hmy_getBalance", "getbalance"} {
+		field, method, err := LookupMethod(name)
+		if err != nil || field != "GetBalance" || method != Method.GetBalance {
+			t.Errorf("%s: got %s %s %v", name, field, method, err)
+		}
+	}
+	field, method, err := LookupMethod(rpcEth.Method.GetBalance)
+	if err != nil || field != "GetBalance" || method != rpcEth.Method.GetBalance {
+		t.Errorf("expected an explicit eth prefix to be kept, got %s %s %v", field, method, err)
+	}
+	if field, method, _ := LookupMethod(rpcV2.Method.GetBalance); field != "GetBalance" || method != rpcV2.Method.GetBalance {
+		t.Errorf("expected an explicit hmyv2 prefix to be kept, got %s %s", field, method)
+	}
+	if field, _, _ := LookupMethod("latestHeader"); field != "GetLatestBlockHeader" {
+		t.Errorf("expected the wire name to resolve, got %s", field)
+	}
+	if _, _, err := LookupMethod("hmy_noSuchMethod"); !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("expected an unknown method, got %v", err)
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	valid := map[string][]interface{}{
+		"GetBalance":     {"one1pdv9lrdwl0rg5vglh4xtyrv3wjk3wsqket7zxy", "latest"},
+		"GetStorageAt":   {"0x0B585F8DaEfBC68a311FbD4cB20d9174aD174016", "0x0", float64(12)},
+		"GetBlockByHash": {"0x" + strings.Repeat("ab", 32), true},
+		"EstimateGas":    {map[string]interface{}{"to": "0x0B585F8DaEfBC68a311FbD4cB20d9174aD174016"}},
+		"GetProof":       {"0x0B585F8DaEfBC68a311FbD4cB20d9174aD174016", []interface{}{}, "0x10"},
+		"BlockNumber":    {},
+	}
+	for field, params := range valid {
+		if err := ValidateParams(field, params); err != nil {
+			t.Errorf("%s: %v", field, err)
+		}
+	}
+	invalid := map[string][]interface{}{
+		"GetBalance":                 {"one1notanaddress", "latest"},
+		"GetTransactionReceipt":      {"0x1234"},
+		"GetBlockByNumber":           {"newest", true},
+		"GetAllValidatorInformation": {float64(-1)},
+		"BlockNumber":                {"latest"},
+		"Call":                       {map[string]interface{}{}},
+	}
+	for field, params := range invalid {
+		if err := ValidateParams(field, params); !errors.Is(err, ErrBadParams) {
+			t.Errorf("%s %v: expected invalid params, got %v", field, params, err)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+)
+
+// quantityResults are the methods answering a bare quantity, a number in v2 and a hex string in v1
+var quantityResults = map[string]bool{
+	Method.GetBalance:                       true,
+	Method.GetTransactionCount:              true,
+	Method.BlockNumber:                      true,
+	Method.GasPrice:                         true,
+	Method.ProtocolVersion:                  true,
+	Method.GetBlockTransactionCountByHash:   true,
+	Method.GetBlockTransactionCountByNumber: true,
+}
+
+// objectResults are the methods answering blocks, transactions or receipts, whose
+// quantities are numbers in v2 and hex strings in v1
+var objectResults = map[string]bool{
+	Method.GetBlockByHash:                      true,
+	Method.GetBlockByNumber:                    true,
+	Method.GetTransactionByHash:                true,
+	Method.GetStakingTransactionByHash:         true,
+	Method.GetTransactionByBlockHashAndIndex:   true,
+	Method.GetTransactionByBlockNumberAndIndex: true,
+	Method.GetTransactionReceipt:               true,
+	Method.GetCXReceiptByHash:                  true,
+	Method.GetTransactionsHistory:              true,
+	Method.GetPendingTxnsInPool:                true,
+}
+
+// quantityKeys are the members of blocks, transactions and receipts that v1 encodes as hex
+var quantityKeys = map[string]bool{
+	"number":            true,
+	"viewID":            true,
+	"epoch":             true,
+	"size":              true,
+	"gasLimit":          true,
+	"gasUsed":           true,
+	"timestamp":         true,
+	"blockNumber":       true,
+	"gas":               true,
+	"gasPrice":          true,
+	"nonce":             true,
+	"transactionIndex":  true,
+	"value":             true,
+	"cumulativeGasUsed": true,
+	"status":            true,
+}
+
+// blockNumbers are the members a v1 block keeps as numbers, unlike the same keys of a transaction
+var blockNumbers = map[string]bool{
+	"nonce":      true,
+	"difficulty": true,
+}
+
+// IsMethod tells whether meth belongs to the hmyv2 namespace
+func IsMethod(meth string) bool {
+	return len(meth) > len(prefix) && meth[:len(prefix)+1] == prefix+"_"
+}
+
+// Params turns params written for a v1 method into those of its v2 counterpart: the
+// fullTx flag of GetBlockByNumber and GetBlockByHash becomes a block arguments object.
+// Block numbers are sent unchanged, v2 accepting them in hex as well as in decimal.
+func Params(meth string, params []interface{}) []interface{} {
+	if meth != Method.GetBlockByNumber && meth != Method.GetBlockByHash || len(params) < 2 {
+		return params
+	}
+	fullTx, ok := params[1].(bool)
+	if !ok {
+		return params
+	}
+	converted := append([]interface{}{}, params...)
+	converted[1] = map[string]interface{}{"fullTx": fullTx, "inclStaking": true, "withSigners": false}
+	return converted
+}
+
+// Normalize rewrites the result of a v2 method the way its v1 counterpart answers it,
+// so that the same decoding serves both. Quantities given as numbers become hex
+// strings, anything already in the v1 shape is kept as is.
+func Normalize(meth string, result json.RawMessage) (json.RawMessage, error) {
+	if !quantityResults[meth] && !objectResults[meth] {
+		return result, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(result))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s result", meth)
+	}
+	if quantityResults[meth] {
+		number, ok := value.(json.Number)
+		if !ok {
+			return result, nil
+		}
+		hex, err := toHex(number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s result", meth)
+		}
+		return json.Marshal(hex)
+	}
+	normalized, err := normalizeValue(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s result", meth)
+	}
+	return json.Marshal(normalized)
+}
+
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			normalized, err := normalizeValue(v[i])
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+	case map[string]interface{}:
+		_, isBlock := v["stateRoot"]
+		for key, member := range v {
+			if key == "msg" || isBlock && blockNumbers[key] {
+				// The directive of a staking transaction has the same shape in v1
+				continue
+			}
+			if number, ok := member.(json.Number); ok && quantityKeys[key] {
+				hex, err := toHex(number)
+				if err != nil {
+					return nil, errors.Wrapf(err, "member %s", key)
+				}
+				v[key] = hex
+				continue
+			}
+			normalized, err := normalizeValue(member)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = normalized
+		}
+	}
+	return value, nil
+}
+
+// toHex encodes a JSON number as a hex quantity, without losing the precision of big ones
+func toHex(number json.Number) (string, error) {
+	n, ok := new(big.Int).SetString(number.String(), 10)
+	if !ok {
+		// Numbers that went through a float64 may come back in exponent form
+		f, _, err := big.ParseFloat(number.String(), 10, 256, big.ToNearestEven)
+		if err != nil || !f.IsInt() {
+			return "", errors.Errorf("invalid quantity %s", number)
+		}
+		n, _ = f.Int(nil)
+	}
+	if n.Sign() < 0 {
+		return "", errors.Errorf("negative quantity %s", number)
+	}
+	return hexutil.EncodeBig(n), nil
+}
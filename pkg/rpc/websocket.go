@@ -0,0 +1,487 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/pkg/errors"
+)
+
+const (
+	subscriptionBuffer         = 128
+	defaultReconnectBackoff    = time.Second
+	defaultMaxReconnectBackoff = 30 * time.Second
+)
+
+var (
+	// ErrWSClosed is returned when using a WSMessenger after Close
+	ErrWSClosed = errors.New("websocket messenger is closed")
+	// ErrWSDisconnected is returned for requests in flight when the connection drops
+	ErrWSDisconnected = errors.New("websocket connection lost before a reply was received")
+	// ErrSubscriptionQueueOverflow ends a subscription whose consumer does not keep up
+	ErrSubscriptionQueueOverflow = errors.New("subscription queue overflow")
+)
+
+type wsMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  interface{}     `json:"error,omitempty"`
+}
+
+type wsNotification struct {
+	Subscription string          `json:"subscription"`
+	Result       json.RawMessage `json:"result"`
+}
+
+type wsPending struct {
+	reply chan []byte
+	// sub is registered by the read loop as soon as the subscribe reply arrives,
+	// so that no notification sent right after it is lost
+	sub *Subscription
+	// abandoned is a subscribe given up on before its reply, kept pending so that the
+	// read loop cancels the subscription the node makes anyway
+	abandoned bool
+}
+
+// WSMessenger is a T over a single websocket connection to a node. The connection is
+// dialed on first use and re-dialed when it drops; active subscriptions are then
+// re-established on the new connection and keep delivering on the same channels.
+type WSMessenger struct {
+	node                string
+	dialer              *websocket.Dialer
+	ReconnectBackoff    time.Duration
+	MaxReconnectBackoff time.Duration
+
+	queryID uint64
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	conn    *websocket.Conn
+	pending map[string]*wsPending
+	// subs indexes the subscriptions of the current connection by id, active holds
+	// every subscription not yet ended, including those waiting for a reconnect
+	subs         map[string]*Subscription
+	active       map[*Subscription]struct{}
+	reconnecting bool
+	closed       bool
+}
+
+// NewWSHandler creates a websocket messenger for the given ws:// or wss:// node
+func NewWSHandler(node string, options ...func(*WSMessenger)) *WSMessenger {
+	messenger := &WSMessenger{
+		node:                node,
+		dialer:              websocket.DefaultDialer,
+		ReconnectBackoff:    defaultReconnectBackoff,
+		MaxReconnectBackoff: defaultMaxReconnectBackoff,
+		pending:             make(map[string]*wsPending),
+		subs:                make(map[string]*Subscription),
+		active:              make(map[*Subscription]struct{}),
+	}
+	for _, option := range options {
+		option(messenger)
+	}
+	return messenger
+}
+
+// SendRPC sends a request over the websocket and waits for its reply
+func (M *WSMessenger) SendRPC(ctx context.Context, meth string, params []interface{}) (Reply, error) {
+	raw, err := M.call(ctx, meth, params, nil)
+	if err != nil {
+		return nil, err
+	}
+	reply := Reply{}
+	if err := json.Unmarshal(raw, &reply); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// Close terminates the connection and ends every subscription
+func (M *WSMessenger) Close() error {
+	M.mu.Lock()
+	if M.closed {
+		M.mu.Unlock()
+		return nil
+	}
+	M.closed = true
+	conn := M.conn
+	M.conn = nil
+	subs := make([]*Subscription, 0, len(M.active))
+	for sub := range M.active {
+		subs = append(subs, sub)
+	}
+	M.mu.Unlock()
+	for _, sub := range subs {
+		sub.end(ErrWSClosed)
+	}
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// SubscribeNewHeads delivers every new block header of the shard
+func (M *WSMessenger) SubscribeNewHeads(ctx context.Context) (*Subscription, error) {
+	return M.subscribe(ctx, []interface{}{"newHeads"})
+}
+
+// SubscribeLogs delivers the logs matching the given filter, which follows the
+// eth_getLogs criteria (address, topics)
+func (M *WSMessenger) SubscribeLogs(ctx context.Context, filter map[string]interface{}) (*Subscription, error) {
+	return M.subscribe(ctx, []interface{}{"logs", filter})
+}
+
+// SubscribePendingTransactions delivers the hash of every transaction entering the pool
+func (M *WSMessenger) SubscribePendingTransactions(ctx context.Context) (*Subscription, error) {
+	return M.subscribe(ctx, []interface{}{"newPendingTransactions"})
+}
+
+func (M *WSMessenger) subscribe(ctx context.Context, params []interface{}) (*Subscription, error) {
+	sub := &Subscription{
+		messenger: M,
+		params:    params,
+		events:    make(chan json.RawMessage, subscriptionBuffer),
+		err:       make(chan error, 1),
+	}
+	if _, err := M.call(ctx, Method.Subscribe, params, sub); err != nil {
+		M.abandon(sub, nil)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// abandon ends sub after its subscribe failed, cancelling it on the node should the
+// reply have registered it meanwhile
+func (M *WSMessenger) abandon(sub *Subscription, err error) {
+	sub.end(err)
+	if id := sub.ID(); id != "" {
+		go M.unsubscribe(id)
+	}
+}
+
+// unsubscribe cancels the subscription id on the node, its Subscription having ended
+func (M *WSMessenger) unsubscribe(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), M.MaxReconnectBackoff)
+	defer cancel()
+	M.call(ctx, Method.UnSubscribe, []interface{}{id}, nil)
+}
+
+func (M *WSMessenger) connect(ctx context.Context) (*websocket.Conn, error) {
+	M.mu.Lock()
+	defer M.mu.Unlock()
+	if M.closed {
+		return nil, ErrWSClosed
+	}
+	if M.conn != nil {
+		return M.conn, nil
+	}
+	conn, _, err := M.dialer.DialContext(ctx, M.node, nil)
+	if err != nil {
+		return nil, err
+	}
+	M.conn = conn
+	go M.readLoop(conn)
+	return conn, nil
+}
+
+func (M *WSMessenger) call(
+	ctx context.Context, meth string, params []interface{}, sub *Subscription,
+) ([]byte, error) {
+	conn, err := M.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := strconv.FormatUint(atomic.AddUint64(&M.queryID, 1), 10)
+	pending := &wsPending{reply: make(chan []byte, 1), sub: sub}
+	M.mu.Lock()
+	if M.conn != conn {
+		// The connection dropped before the call was pending, disconnected missed it
+		M.mu.Unlock()
+		return nil, ErrWSDisconnected
+	}
+	M.pending[id] = pending
+	M.mu.Unlock()
+	abandoned := false
+	defer func() {
+		M.mu.Lock()
+		if abandoned {
+			pending.abandoned = true
+		} else {
+			delete(M.pending, id)
+		}
+		M.mu.Unlock()
+	}()
+
+	requestBody, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": common.JSONRPCVersion,
+		"id":      id,
+		"method":  meth,
+		"params":  params,
+	})
+	M.writeMu.Lock()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	} else {
+		conn.SetWriteDeadline(time.Time{})
+	}
+	err = conn.WriteMessage(websocket.TextMessage, requestBody)
+	M.writeMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case raw := <-pending.reply:
+		if raw == nil {
+			return nil, ErrWSDisconnected
+		}
+		msg := wsMessage{}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return nil, err
+		}
+		if msg.Error != nil {
+			return nil, liftError(msg.Error)
+		}
+		if common.DebugRPC {
+			printRPCDebug(M.node, requestBody, raw)
+		}
+		return raw, nil
+	case <-ctx.Done():
+		abandoned = sub != nil
+		return nil, ctx.Err()
+	}
+}
+
+func (M *WSMessenger) readLoop(conn *websocket.Conn) {
+	for {
+		_, raw, err := conn.ReadMessage()
+		if err != nil {
+			M.disconnected(conn)
+			return
+		}
+		msg := wsMessage{}
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			continue
+		}
+		if strings.HasSuffix(msg.Method, "_subscription") {
+			notification := wsNotification{}
+			if err := json.Unmarshal(msg.Params, &notification); err != nil {
+				continue
+			}
+			M.mu.Lock()
+			sub := M.subs[notification.Subscription]
+			M.mu.Unlock()
+			if sub != nil {
+				sub.deliver(notification.Result)
+			}
+			continue
+		}
+		id := strings.Trim(string(msg.ID), `"`)
+		M.mu.Lock()
+		pending := M.pending[id]
+		if pending != nil && pending.abandoned {
+			delete(M.pending, id)
+		}
+		if pending != nil && pending.sub != nil && msg.Error == nil {
+			subID := ""
+			if json.Unmarshal(msg.Result, &subID) == nil {
+				if pending.sub.bind(subID) {
+					M.subs[subID] = pending.sub
+					M.active[pending.sub] = struct{}{}
+				} else {
+					// A late reply for a subscription already ended
+					go M.unsubscribe(subID)
+				}
+			}
+		}
+		M.mu.Unlock()
+		if pending != nil {
+			pending.reply <- raw
+		}
+	}
+}
+
+// disconnected fails the requests in flight and, if there are subscriptions to keep
+// alive, starts re-dialing the node in the background
+func (M *WSMessenger) disconnected(conn *websocket.Conn) {
+	M.mu.Lock()
+	if M.conn == conn {
+		M.conn = nil
+	}
+	for id, pending := range M.pending {
+		// The node drops the subscriptions of the connection, abandoned ones included
+		if pending.abandoned {
+			delete(M.pending, id)
+			continue
+		}
+		select {
+		case pending.reply <- nil:
+		default:
+		}
+	}
+	M.subs = make(map[string]*Subscription)
+	resubscribe := !M.closed && !M.reconnecting && len(M.active) > 0
+	if resubscribe {
+		M.reconnecting = true
+	}
+	M.mu.Unlock()
+	conn.Close()
+	if resubscribe {
+		go M.resubscribe()
+	}
+}
+
+// resubscribe re-dials the node with an exponential backoff until every active
+// subscription is registered again on the new connection
+func (M *WSMessenger) resubscribe() {
+	backoff := M.ReconnectBackoff
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), M.MaxReconnectBackoff)
+		_, err := M.connect(ctx)
+		cancel()
+		if err == nil && M.registerActive() || err == ErrWSClosed {
+			M.mu.Lock()
+			// The connection may have dropped again while subscribing, in which case
+			// disconnected relied on this goroutine to carry on
+			if M.closed || M.conn != nil && len(M.missingSubs()) == 0 {
+				M.reconnecting = false
+				M.mu.Unlock()
+				return
+			}
+			M.mu.Unlock()
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > M.MaxReconnectBackoff {
+			backoff = M.MaxReconnectBackoff
+		}
+	}
+}
+
+// registerActive subscribes again every active subscription missing from the current
+// connection, it reports false if the connection dropped in the meantime
+func (M *WSMessenger) registerActive() bool {
+	M.mu.Lock()
+	missing := M.missingSubs()
+	M.mu.Unlock()
+	for _, sub := range missing {
+		ctx, cancel := context.WithTimeout(context.Background(), M.MaxReconnectBackoff)
+		_, err := M.call(ctx, Method.Subscribe, sub.params, sub)
+		cancel()
+		switch err {
+		case nil:
+		case ErrWSDisconnected, ErrWSClosed:
+			return false
+		default:
+			M.abandon(sub, errors.Wrap(err, "could not re-establish subscription"))
+		}
+	}
+	return true
+}
+
+// missingSubs lists the active subscriptions not registered on the current connection,
+// M.mu must be held
+func (M *WSMessenger) missingSubs() []*Subscription {
+	missing := []*Subscription{}
+	for sub := range M.active {
+		if M.subs[sub.ID()] != sub {
+			missing = append(missing, sub)
+		}
+	}
+	return missing
+}
+
+// Subscription is a live stream of notifications from the node
+type Subscription struct {
+	messenger *WSMessenger
+	params    []interface{}
+	events    chan json.RawMessage
+	err       chan error
+
+	mu   sync.Mutex
+	id   string
+	done bool
+}
+
+// ID is the current subscription id given by the node, it changes after a reconnect
+func (S *Subscription) ID() string {
+	S.mu.Lock()
+	defer S.mu.Unlock()
+	return S.id
+}
+
+// Events delivers the raw result of each notification, it is closed when the subscription ends
+func (S *Subscription) Events() <-chan json.RawMessage {
+	return S.events
+}
+
+// Err delivers at most one error, when the subscription ends for any other reason than Unsubscribe
+func (S *Subscription) Err() <-chan error {
+	return S.err
+}
+
+// Unsubscribe cancels the subscription on the node and closes its channels
+func (S *Subscription) Unsubscribe(ctx context.Context) error {
+	id := S.ID()
+	S.end(nil)
+	_, err := S.messenger.SendRPC(ctx, Method.UnSubscribe, []interface{}{id})
+	return err
+}
+
+// bind gives the subscription the id the node registered it under, unless it ended
+func (S *Subscription) bind(id string) bool {
+	S.mu.Lock()
+	defer S.mu.Unlock()
+	if S.done {
+		return false
+	}
+	S.id = id
+	return true
+}
+
+func (S *Subscription) deliver(event json.RawMessage) {
+	S.mu.Lock()
+	if S.done {
+		S.mu.Unlock()
+		return
+	}
+	select {
+	case S.events <- event:
+		S.mu.Unlock()
+	default:
+		id := S.id
+		S.mu.Unlock()
+		S.end(ErrSubscriptionQueueOverflow)
+		// The node would keep pushing notifications to be dropped until the connection closes
+		go S.messenger.unsubscribe(id)
+	}
+}
+
+func (S *Subscription) end(err error) {
+	S.mu.Lock()
+	if S.done {
+		S.mu.Unlock()
+		return
+	}
+	S.done = true
+	if err != nil {
+		S.err <- err
+	}
+	close(S.events)
+	close(S.err)
+	id := S.id
+	S.mu.Unlock()
+	S.messenger.mu.Lock()
+	if S.messenger.subs[id] == S {
+		delete(S.messenger.subs, id)
+	}
+	delete(S.messenger.active, S)
+	S.messenger.mu.Unlock()
+}
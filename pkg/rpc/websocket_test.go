@@ -0,0 +1,225 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsTestServer answers subscribe calls with a new id, pushes one newHeads notification
+// and then drops the connection, so every client connection lives for one event only
+func wsTestServer(t *testing.T) (*httptest.Server, *int32) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		n := atomic.AddInt32(&connections, 1)
+		for {
+			req := map[string]interface{}{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req["method"] {
+			case Method.BlockNumber:
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x10"})
+			case Method.Subscribe:
+				subID := fmt.Sprintf("0x%d", n)
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": subID})
+				conn.WriteJSON(map[string]interface{}{
+					"jsonrpc": "2.0",
+					"method":  "hmy_subscription",
+					"params": map[string]interface{}{
+						"subscription": subID,
+						"result":       map[string]interface{}{"number": fmt.Sprintf("0x%d", n)},
+					},
+				})
+				return
+			default:
+				conn.WriteJSON(map[string]interface{}{
+					"jsonrpc": "2.0", "id": req["id"],
+					"error": map[string]interface{}{"code": -32601, "message": "not found"},
+				})
+			}
+		}
+	}))
+	return server, &connections
+}
+
+func TestWSMessengerSendRPC(t *testing.T) {
+	server, _ := wsTestServer(t)
+	defer server.Close()
+	messenger := NewWSHandler("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer messenger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := messenger.SendRPC(ctx, Method.BlockNumber, []interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply["result"] != "0x10" {
+		t.Errorf("unexpected result %v", reply["result"])
+	}
+	if _, err := messenger.SendRPC(ctx, Method.GetWork, []interface{}{}); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestWSMessengerResubscribes(t *testing.T) {
+	server, connections := wsTestServer(t)
+	defer server.Close()
+	messenger := NewWSHandler("ws"+strings.TrimPrefix(server.URL, "http"), func(M *WSMessenger) {
+		M.ReconnectBackoff = 10 * time.Millisecond
+	})
+	defer messenger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := messenger.SubscribeNewHeads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"0x1", "0x2", "0x3"} {
+		select {
+		case event := <-sub.Events():
+			header := struct {
+				Number string `json:"number"`
+			}{}
+			if err := json.Unmarshal(event, &header); err != nil {
+				t.Fatal(err)
+			}
+			if header.Number != expected {
+				t.Errorf("expected header %s, got %s", expected, header.Number)
+			}
+		case err := <-sub.Err():
+			t.Fatal(err)
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for notification %s", expected)
+		}
+	}
+	if atomic.LoadInt32(connections) < 3 {
+		t.Errorf("expected the messenger to reconnect, got %d connections", atomic.LoadInt32(connections))
+	}
+	messenger.Close()
+	if _, ok := <-sub.Err(); !ok {
+		t.Error("expected the subscription to end with an error on Close")
+	}
+}
+
+func TestWSMessengerLateSubscribeReply(t *testing.T) {
+	unsubscribed := make(chan interface{}, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for {
+			req := map[string]interface{}{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req["method"] {
+			case Method.Subscribe:
+				// Replied once the client gave up waiting
+				time.Sleep(100 * time.Millisecond)
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x1"})
+			case Method.UnSubscribe:
+				unsubscribed <- req["params"].([]interface{})[0]
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": true})
+			}
+		}
+	}))
+	defer server.Close()
+	messenger := NewWSHandler("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer messenger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := messenger.SubscribeNewHeads(ctx); err == nil {
+		t.Fatal("expected the subscribe to time out")
+	}
+	select {
+	case id := <-unsubscribed:
+		if id != "0x1" {
+			t.Errorf("expected the late subscription 0x1 to be cancelled, got %v", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the late subscription to be cancelled on the node")
+	}
+	messenger.mu.Lock()
+	defer messenger.mu.Unlock()
+	if len(messenger.subs) != 0 || len(messenger.active) != 0 {
+		t.Errorf("expected the ended subscription not to be registered, got %v %v", messenger.subs, messenger.active)
+	}
+}
+
+func TestWSMessengerUnsubscribesOnOverflow(t *testing.T) {
+	unsubscribed := make(chan interface{}, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		for {
+			req := map[string]interface{}{}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			switch req["method"] {
+			case Method.Subscribe:
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "0x1"})
+				// One notification more than the consumer, reading none, can queue
+				for i := 0; i <= subscriptionBuffer; i++ {
+					conn.WriteJSON(map[string]interface{}{
+						"jsonrpc": "2.0",
+						"method":  "hmy_subscription",
+						"params":  map[string]interface{}{"subscription": "0x1", "result": i},
+					})
+				}
+			case Method.UnSubscribe:
+				unsubscribed <- req["params"].([]interface{})[0]
+				conn.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": true})
+			}
+		}
+	}))
+	defer server.Close()
+	messenger := NewWSHandler("ws" + strings.TrimPrefix(server.URL, "http"))
+	defer messenger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sub, err := messenger.SubscribeNewHeads(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-unsubscribed:
+		if id != "0x1" {
+			t.Errorf("expected the overflowing subscription 0x1 to be cancelled, got %v", id)
+		}
+	case <-ctx.Done():
+		t.Fatal("expected the overflowing subscription to be cancelled on the node")
+	}
+	if err := <-sub.Err(); err != ErrSubscriptionQueueOverflow {
+		t.Errorf("expected the subscription to end on overflow, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package sharding
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -24,11 +25,11 @@ type RPCRoutes struct {
 }
 
 // Structure produces a slice of RPCRoutes for the network across shards
-func Structure(node string) ([]RPCRoutes, error) {
+func Structure(ctx context.Context, node string) ([]RPCRoutes, error) {
 	type r struct {
 		Result []RPCRoutes `json:"result"`
 	}
-	p, e := rpc.RawRequest(rpc.Method.GetShardingStructure, node, []interface{}{})
+	p, e := rpc.RawRequest(ctx, rpc.Method.GetShardingStructure, node, []interface{}{})
 	if e != nil {
 		return nil, e
 	}
@@ -39,16 +40,16 @@ func Structure(node string) ([]RPCRoutes, error) {
 	return result.Result, nil
 }
 
-func CheckAllShards(node, oneAddr string, noPretty bool) (string, error) {
+func CheckAllShards(ctx context.Context, node, oneAddr string, noPretty bool) (string, error) {
 	var out bytes.Buffer
 	out.WriteString("[")
 	params := []interface{}{oneAddr, "latest"}
-	s, err := Structure(node)
+	s, err := Structure(ctx, node)
 	if err != nil {
 		return "", err
 	}
 	for i, shard := range s {
-		balanceRPCReply, err := rpc.Request(rpc.Method.GetBalance, shard.HTTP, params)
+		balanceRPCReply, err := rpc.NewV2Messenger(rpc.NewHTTPHandler(shard.HTTP)).SendRPC(ctx, rpc.Method.GetBalance, params)
 		if err != nil {
 			if common.DebugRPC {
 				fmt.Printf("NOTE: Route %s failed.", shard.HTTP)
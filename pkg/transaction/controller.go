@@ -1,18 +1,18 @@
 package transaction
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/harmony-one/go-sdk/pkg/address"
 	"github.com/harmony-one/go-sdk/pkg/common"
-	"github.com/harmony-one/go-sdk/pkg/ledger"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
 	"github.com/harmony-one/harmony/accounts"
 	"github.com/harmony-one/harmony/accounts/keystore"
 	"github.com/harmony-one/harmony/common/denominations"
@@ -53,6 +53,7 @@ type Controller struct {
 	sender            sender
 	transactionForRPC transactionForRPC
 	chain             common.ChainID
+	cxStatus          *CXStatus
 	Behavior          behavior
 }
 
@@ -61,6 +62,16 @@ type behavior struct {
 	OfflineSign          bool
 	SigningImpl          SignerImpl
 	ConfirmationWaitTime uint32
+	// GasEstimator fills the gas limit and price left at zero, they are kept as is when nil
+	GasEstimator *GasEstimator
+	// Signer signs the transactions, SigningImpl picks one for the account of the controller when nil
+	Signer Signer
+	// Simulation picks the transactions run through Call before being sent, to abort the
+	// ones that would revert
+	Simulation SimulationPolicy
+	// CXTracker follows the confirmed cross shard transactions into their destination shard
+	// when set
+	CXTracker *CXTracker
 }
 
 // NewController initializes a Controller, caller can control behavior via options
@@ -84,11 +95,12 @@ func NewController(
 			receipt:         nil,
 		},
 		chain:    chain,
-		Behavior: behavior{false, false, Software, 0},
+		Behavior: behavior{false, false, Software, 0, NewGasEstimator(), nil, SimulateWithData, nil},
 	}
 	for _, option := range options {
 		option(ctrlr)
 	}
+	ctrlr.sender.fromSigner(ctrlr.Behavior)
 	return ctrlr
 }
 
@@ -103,6 +115,9 @@ func (C *Controller) TransactionToJSON(pretty bool) string {
 
 // RawTransaction dumps the signature as string
 func (C *Controller) RawTransaction() string {
+	if C.transactionForRPC.signature == nil {
+		return ""
+	}
 	return *C.transactionForRPC.signature
 }
 
@@ -120,6 +135,11 @@ func (C *Controller) Receipt() rpc.Reply {
 	return C.transactionForRPC.receipt
 }
 
+// CXStatus is how far Behavior.CXTracker followed a cross shard transaction, nil when it did not
+func (C *Controller) CXStatus() *CXStatus {
+	return C.cxStatus
+}
+
 // TransactionErrors - tx errors
 func (C *Controller) TransactionErrors() Errors {
 	return C.transactionErrors
@@ -158,7 +178,31 @@ func (C *Controller) setGasPrice(gasPrice numeric.Dec) {
 	C.transactionForRPC.params["gas-price"] = gasPrice.Mul(nanoAsDec)
 }
 
-func (C *Controller) setAmount(amount numeric.Dec) {
+// setEstimatedGas has Behavior.GasEstimator fill the gas limit and price left at zero
+func (C *Controller) setEstimatedGas(ctx context.Context, to *string, crossShard bool, amount numeric.Dec, data []byte) {
+	if C.executionError != nil || C.Behavior.GasEstimator == nil {
+		return
+	}
+	args := client.CallArgs{From: C.sender.account.Address.Hex(), Data: data}
+	if to != nil {
+		args.To = address.Parse(*to).Hex()
+	}
+	if amount.IsPositive() {
+		args.Value = (*hexutil.Big)(amount.Mul(oneAsDec).TruncateInt())
+	}
+	gasLimit, gasPrice, err := C.Behavior.GasEstimator.estimateGas(
+		ctx, C.messenger, C.Behavior.OfflineSign, crossShard, args,
+		C.transactionForRPC.params["gas-limit"].(uint64), C.transactionForRPC.params["gas-price"].(numeric.Dec),
+	)
+	if err != nil {
+		C.executionError = err
+		return
+	}
+	C.transactionForRPC.params["gas-limit"] = gasLimit
+	C.transactionForRPC.params["gas-price"] = gasPrice
+}
+
+func (C *Controller) setAmount(ctx context.Context, amount numeric.Dec) {
 	if C.executionError != nil {
 		return
 	}
@@ -181,7 +225,7 @@ func (C *Controller) setAmount(amount numeric.Dec) {
 
 	if !C.Behavior.OfflineSign {
 		balanceRPCReply, err := C.messenger.SendRPC(
-			rpc.Method.GetBalance,
+			ctx, rpc.Method.GetBalance,
 			p{address.ToBech32(C.sender.account.Address), "latest"},
 		)
 		if err != nil {
@@ -236,14 +280,14 @@ func (C *Controller) setNewTransactionWithDataAndGas(data []byte) {
 	)
 }
 
-func (C *Controller) signAndPrepareTxEncodedForSending() {
+func (C *Controller) signAndPrepareTxEncodedForSending(ctx context.Context) {
 	if C.executionError != nil {
 		return
 	}
-	signedTransaction, err :=
-		C.sender.ks.SignTx(*C.sender.account, C.transactionForRPC.transaction, C.chain.Value)
+	signedTransaction, err := C.Behavior.signer(C.sender).SignTx(ctx, C.transactionForRPC.transaction, C.chain.Value)
 	if err != nil {
 		C.executionError = err
+		C.transactionErrors = append(C.transactionErrors, signingErrors(err)...)
 		return
 	}
 	C.transactionForRPC.transaction = signedTransaction
@@ -257,33 +301,33 @@ func (C *Controller) signAndPrepareTxEncodedForSending() {
 	}
 }
 
-func (C *Controller) hardwareSignAndPrepareTxEncodedForSending() {
-	if C.executionError != nil {
+// simulate aborts the transaction when its simulation reverts, cross shard transfers
+// being left out as they run no contract
+func (C *Controller) simulate(ctx context.Context) {
+	tx := C.transactionForRPC.transaction
+	if C.executionError != nil || tx.ShardID() != tx.ToShardID() {
 		return
 	}
-	enc, signerAddr, err := ledger.SignTx(C.transactionForRPC.transaction, C.chain.Value)
+	simulates, err := C.Behavior.simulates(tx.Data())
 	if err != nil {
 		C.executionError = err
 		return
 	}
-	if strings.Compare(signerAddr, address.ToBech32(C.sender.account.Address)) != 0 {
-		C.executionError = ErrBadTransactionParam
-		errorMsg := "signature verification failed : sender address doesn't match with ledger hardware addresss"
-		C.transactionErrors = append(C.transactionErrors, &Error{
-			ErrMessage:           &errorMsg,
-			TimestampOfRejection: time.Now().Unix(),
-		})
+	if !simulates {
 		return
 	}
-	hexSignature := hexutil.Encode(enc)
-	C.transactionForRPC.signature = &hexSignature
+	args := simulationArgs(C.sender.account.Address, tx.To(), tx.Value(), tx.GasLimit(), tx.GasPrice(), tx.Data())
+	if err := Simulate(ctx, C.messenger, args); err != nil {
+		C.executionError = err
+		C.transactionErrors = append(C.transactionErrors, simulationError(err)...)
+	}
 }
 
-func (C *Controller) sendSignedTx() {
+func (C *Controller) sendSignedTx(ctx context.Context) {
 	if C.executionError != nil || C.Behavior.DryRun {
 		return
 	}
-	reply, err := C.messenger.SendRPC(rpc.Method.SendRawTransaction, p{C.transactionForRPC.signature})
+	reply, err := C.messenger.SendRPC(ctx, rpc.Method.SendRawTransaction, p{C.transactionForRPC.signature})
 	if err != nil {
 		C.executionError = err
 		return
@@ -292,7 +336,7 @@ func (C *Controller) sendSignedTx() {
 	C.transactionForRPC.transactionHash = &r
 }
 
-func (C *Controller) txConfirmation() {
+func (C *Controller) txConfirmation(ctx context.Context) {
 	if C.executionError != nil || C.Behavior.DryRun {
 		return
 	}
@@ -300,12 +344,12 @@ func (C *Controller) txConfirmation() {
 		txHash := *C.TransactionHash()
 		start := int(C.Behavior.ConfirmationWaitTime)
 		for {
-			r, _ := C.messenger.SendRPC(rpc.Method.GetTransactionReceipt, p{txHash})
+			r, _ := C.messenger.SendRPC(ctx, rpc.Method.GetTransactionReceipt, p{txHash})
 			if r["result"] != nil {
 				C.transactionForRPC.receipt = r
 				return
 			}
-			transactionErrors, err := GetError(txHash, C.messenger)
+			transactionErrors, err := GetError(ctx, txHash, C.messenger)
 			if err != nil {
 				errMsg := fmt.Sprintf(err.Error())
 				C.transactionErrors = append(C.transactionErrors, &Error{
@@ -323,16 +367,48 @@ func (C *Controller) txConfirmation() {
 				C.executionError = fmt.Errorf("could not confirm transaction after %d seconds", C.Behavior.ConfirmationWaitTime)
 				return
 			}
-			time.Sleep(time.Second)
+			select {
+			case <-ctx.Done():
+				C.executionError = ctx.Err()
+				return
+			case <-time.After(time.Second):
+			}
 			start--
 		}
 	}
 }
 
+// trackCX follows a confirmed cross shard transaction until its destination shard
+// credits it, the raw ones being tracked when they turn out to be cross shard
+func (C *Controller) trackCX(ctx context.Context) {
+	if C.executionError != nil || C.Behavior.CXTracker == nil || C.transactionForRPC.receipt == nil {
+		return
+	}
+	if tx := C.transactionForRPC.transaction; tx != nil && tx.ShardID() == tx.ToShardID() {
+		return
+	}
+	txHash := *C.TransactionHash()
+	status, err := C.Behavior.CXTracker.Track(ctx, C.messenger, txHash)
+	if errors.Is(err, ErrNotCrossShard) {
+		return
+	}
+	C.cxStatus = status
+	if err != nil {
+		errMsg := err.Error()
+		C.transactionErrors = append(C.transactionErrors, &Error{
+			TxHashID:             &txHash,
+			ErrMessage:           &errMsg,
+			TimestampOfRejection: time.Now().Unix(),
+		})
+		C.executionError = err
+	}
+}
+
 // ExecuteTransaction is the single entrypoint to execute a plain transaction.
 // Each step in transaction creation, execution probably includes a mutation
 // Each becomes a no-op if executionError occurred in any previous step
 func (C *Controller) ExecuteTransaction(
+	ctx context.Context,
 	nonce, gasLimit uint64,
 	to *string,
 	shardID, toShardID uint32,
@@ -343,22 +419,36 @@ func (C *Controller) ExecuteTransaction(
 	C.setShardIDs(shardID, toShardID)
 	C.setIntrinsicGas(gasLimit)
 	C.setGasPrice(gasPrice)
-	C.setAmount(amount)
+	C.setEstimatedGas(ctx, to, shardID != toShardID, amount, inputData)
+	C.setAmount(ctx, amount)
 	C.setReceiver(to)
 	C.transactionForRPC.params["nonce"] = nonce
 	C.setNewTransactionWithDataAndGas(inputData)
-	switch C.Behavior.SigningImpl {
-	case Software:
-		C.signAndPrepareTxEncodedForSending()
-	case Ledger:
-		C.hardwareSignAndPrepareTxEncodedForSending()
-	}
-	C.sendSignedTx()
-	C.txConfirmation()
+	C.signAndPrepareTxEncodedForSending(ctx)
+	C.simulate(ctx)
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
+	C.trackCX(ctx)
 	return C.executionError
 }
 
 func (C *Controller) SignTransaction(
+	ctx context.Context,
+	nonce, gasLimit uint64,
+	to *string,
+	shardID, toShardID uint32,
+	amount, gasPrice numeric.Dec,
+	inputData []byte,
+) error {
+	C.BuildTransaction(ctx, nonce, gasLimit, to, shardID, toShardID, amount, gasPrice, inputData)
+	C.signAndPrepareTxEncodedForSending(ctx)
+
+	return C.executionError
+}
+
+// BuildTransaction fills in the transaction without signing it, see Envelope
+func (C *Controller) BuildTransaction(
+	ctx context.Context,
 	nonce, gasLimit uint64,
 	to *string,
 	shardID, toShardID uint32,
@@ -369,26 +459,25 @@ func (C *Controller) SignTransaction(
 	C.setShardIDs(shardID, toShardID)
 	C.setIntrinsicGas(gasLimit)
 	C.setGasPrice(gasPrice)
-	C.setAmount(amount)
+	C.setEstimatedGas(ctx, to, shardID != toShardID, amount, inputData)
+	C.setAmount(ctx, amount)
 	C.setReceiver(to)
 	C.transactionForRPC.params["nonce"] = nonce
 	C.setNewTransactionWithDataAndGas(inputData)
-	switch C.Behavior.SigningImpl {
-	case Software:
-		C.signAndPrepareTxEncodedForSending()
-	case Ledger:
-		C.hardwareSignAndPrepareTxEncodedForSending()
-	}
-
 	return C.executionError
 }
 
-func (C *Controller) ExecuteRawTransaction(txn string) error {
+// Envelope wraps the transaction built for it to be signed elsewhere
+func (C *Controller) Envelope() (*Envelope, error) {
+	tx := C.transactionForRPC.transaction
+	return newEnvelope(EnvelopePlain, C.chain, C.sender.account.Address, tx.ShardID(), tx)
+}
+
+func (C *Controller) ExecuteRawTransaction(ctx context.Context, txn string) error {
 	C.transactionForRPC.signature = &txn
 
-	C.sendSignedTx()
-	C.txConfirmation()
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
+	C.trackCX(ctx)
 	return C.executionError
 }
-
-// TODO: add logic to create staking transactions in the SDK.
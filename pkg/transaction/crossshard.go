@@ -0,0 +1,218 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/pkg/errors"
+)
+
+// CXStage is how far a cross shard transaction went on its way to its destination shard
+type CXStage string
+
+const (
+	// CXPending is a transaction not yet in a block of its source shard
+	CXPending CXStage = "pending"
+	// CXSent is a transaction in a block of its source shard, its receipt not yet seen
+	// by the destination shard
+	CXSent CXStage = "sent"
+	// CXReceived is a receipt waiting to be included by the destination shard
+	CXReceived CXStage = "received"
+	// CXCredited is a receipt applied by the destination shard, crediting the receiver
+	CXCredited CXStage = "credited"
+)
+
+const (
+	defaultCXPollInterval = time.Second
+	defaultCXStallTime    = 30 * time.Second
+	defaultCXMaxResends   = 3
+)
+
+var (
+	// ErrNotCrossShard is returned when tracking a transaction to its own shard
+	ErrNotCrossShard = errors.New("transaction is not cross shard")
+	// ErrCXFailed is returned for a cross shard transaction that failed on its source shard
+	ErrCXFailed = errors.New("cross shard transaction failed on its source shard")
+	// ErrCXStalled is returned when a cross shard transaction makes no progress, even resent
+	ErrCXStalled = errors.New("cross shard transaction stalled")
+	// ErrNoShardEndpoint is returned for a shard missing from the sharding structure
+	ErrNoShardEndpoint = errors.New("no endpoint for the shard in the sharding structure")
+)
+
+// CXStatus is where a cross shard transaction stands, Receipt being set once credited
+type CXStatus struct {
+	TxHash    string            `json:"transaction-hash"`
+	ShardID   uint32            `json:"shard"`
+	ToShardID uint32            `json:"to-shard"`
+	Stage     CXStage           `json:"stage"`
+	Resends   int               `json:"resends"`
+	Receipt   *client.CXReceipt `json:"cx-receipt,omitempty"`
+}
+
+// CXTracker follows cross shard transactions into their destination shard, asking the
+// source shard to resend the receipts that stall on the way
+type CXTracker struct {
+	// Shard is the messenger of a destination shard, the sharding structure of the source
+	// shard being looked up when nil
+	Shard func(ctx context.Context, shardID uint32) (rpc.T, error)
+	// PollInterval is the time between two looks at the transaction
+	PollInterval time.Duration
+	// StallTime is the time without progress after which the receipt is resent
+	StallTime time.Duration
+	// MaxResends is the number of resends after which the transaction is given up
+	MaxResends int
+	// Progress is told of every change of stage and every resend when set
+	Progress func(CXStatus)
+}
+
+// NewCXTracker creates a CXTracker resending the receipts stalled for 30 seconds, at most 3 times
+func NewCXTracker(options ...func(*CXTracker)) *CXTracker {
+	tracker := &CXTracker{
+		PollInterval: defaultCXPollInterval,
+		StallTime:    defaultCXStallTime,
+		MaxResends:   defaultCXMaxResends,
+	}
+	for _, option := range options {
+		option(tracker)
+	}
+	return tracker
+}
+
+// Track follows the cross shard transaction hash, sent through messenger, until its
+// destination shard credits it. A transaction is given up when it stays out of the
+// blocks of its source shard for StallTime, or when its receipt makes no progress
+// MaxResends times in a row.
+func (T *CXTracker) Track(ctx context.Context, messenger rpc.T, hash string) (*CXStatus, error) {
+	source := client.NewClient(messenger)
+	status := &CXStatus{TxHash: hash, Stage: CXPending}
+	var destination *client.Client
+	progress := time.Now()
+	for {
+		stage := status.Stage
+		switch stage {
+		case CXPending:
+			tx, err := source.GetTransactionByHash(ctx, hash)
+			if err != nil {
+				return status, err
+			}
+			if tx == nil {
+				break
+			}
+			status.ShardID, status.ToShardID = tx.ShardID, tx.ToShardID
+			if tx.ShardID == tx.ToShardID {
+				return status, ErrNotCrossShard
+			}
+			receipt, err := source.GetTransactionReceipt(ctx, hash)
+			if err != nil {
+				return status, err
+			}
+			if receipt == nil {
+				// Known to the node but in no block of the source shard yet
+				break
+			}
+			if !receipt.Succeeded() {
+				return status, ErrCXFailed
+			}
+			shard, err := T.shard(ctx, messenger, tx.ToShardID)
+			if err != nil {
+				return status, err
+			}
+			destination, status.Stage = client.NewClient(shard), CXSent
+		default:
+			credited, err := destination.GetCXReceiptByHash(ctx, hash)
+			if err != nil {
+				return status, err
+			}
+			if credited != nil {
+				status.Stage, status.Receipt = CXCredited, credited
+				T.report(status)
+				return status, nil
+			}
+			received, err := pendingCX(ctx, destination, hash)
+			if err != nil {
+				return status, err
+			}
+			if received {
+				status.Stage = CXReceived
+			}
+		}
+
+		switch {
+		case status.Stage != stage:
+			progress = time.Now()
+			T.report(status)
+		case time.Since(progress) < T.StallTime:
+		case stage == CXPending:
+			return status, errors.Wrap(ErrCXStalled, "it is in no block of its source shard")
+		case status.Resends >= T.MaxResends:
+			return status, errors.Wrapf(ErrCXStalled, "its receipt is still %s after %d resends", stage, status.Resends)
+		default:
+			// The source shard refuses a receipt already waiting to be resent, which is
+			// counted all the same
+			if _, err := source.ResendCX(ctx, hash); err != nil {
+				return status, errors.Wrap(err, "could not resend the receipt")
+			}
+			status.Resends++
+			progress = time.Now()
+			T.report(status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-time.After(T.PollInterval):
+		}
+	}
+}
+
+func (T *CXTracker) report(status *CXStatus) {
+	if T.Progress != nil {
+		T.Progress(*status)
+	}
+}
+
+// shard is the messenger of shardID, from Shard or the sharding structure of the source
+func (T *CXTracker) shard(ctx context.Context, source rpc.T, shardID uint32) (rpc.T, error) {
+	if T.Shard != nil {
+		return T.Shard(ctx, shardID)
+	}
+	routes, err := client.NewClient(source).GetShardingStructure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range routes {
+		if uint32(route.ShardID) == shardID {
+			return rpc.NewHTTPHandler(route.HTTP), nil
+		}
+	}
+	return nil, errors.Wrapf(ErrNoShardEndpoint, "shard %d", shardID)
+}
+
+// pendingCX tells whether the receipt of hash waits in the pool of the destination shard
+func pendingCX(ctx context.Context, destination *client.Client, hash string) (bool, error) {
+	proofs, err := destination.GetPendingCXReceipts(ctx)
+	if err != nil {
+		return false, err
+	}
+	txHash := ethCommon.HexToHash(hash)
+	for _, raw := range proofs {
+		proof := struct {
+			Receipts []struct {
+				TxHash ethCommon.Hash
+			}
+		}{}
+		if json.Unmarshal(raw, &proof) != nil {
+			continue
+		}
+		for _, receipt := range proof.Receipts {
+			if receipt.TxHash == txHash {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,105 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/fakenode"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+func TestCXTracker(t *testing.T) {
+	ctx := context.Background()
+	// The receipt of the first transfer is lost along with its 2 resends, the one of the
+	// second transfer makes it once resent
+	network, messenger, ks, accts := newFundedNetwork(t, []int64{10, 0}, func(n *fakenode.Network) { n.LostCXReceipts = 4 })
+	from, to := accts[0], accts[1]
+	receiver := address.ToBech32(to.Address)
+
+	var stages []CXStage
+	tracker := NewCXTracker(func(T *CXTracker) {
+		T.PollInterval, T.StallTime, T.MaxResends = 10*time.Millisecond, 50*time.Millisecond, 2
+		T.Progress = func(status CXStatus) { stages = append(stages, status.Stage) }
+	})
+	send := func(nonce uint64, toShardID uint32) (*Controller, error) {
+		controller := NewController(messenger, ks, &from, common.Chain.TestNet, func(c *Controller) {
+			c.Behavior.ConfirmationWaitTime = 5
+			c.Behavior.CXTracker = tracker
+		})
+		err := controller.ExecuteTransaction(ctx, nonce, 21000, &receiver, 0, toShardID, numeric.NewDec(1), numeric.NewDec(1), nil)
+		return controller, err
+	}
+
+	stalled, err := send(0, 1)
+	if !errors.Is(err, ErrCXStalled) {
+		t.Errorf("expected the transfer to stall, got %v", err)
+	}
+	if status := stalled.CXStatus(); status == nil || status.Stage != CXSent || status.Resends != 2 {
+		t.Errorf("expected the lost receipt to be resent twice, got %+v", status)
+	}
+	if len(stalled.TransactionErrors()) != 1 || stalled.Receipt() == nil {
+		t.Errorf("expected the confirmed transfer to report its stalled receipt, got %v", stalled.TransactionErrors())
+	}
+
+	stages = nil
+	credited, err := send(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := credited.CXStatus()
+	if len(credited.TransactionErrors()) != 0 || status == nil || status.Stage != CXCredited || status.Resends != 1 {
+		t.Fatalf("expected the receipt to be credited once resent, got %+v %v", status, credited.TransactionErrors())
+	}
+	if status.Receipt.Value.ToInt().Cmp(oneAsDec.TruncateInt()) != 0 || status.Receipt.To != receiver {
+		t.Errorf("expected the receipt of 1 ONE to %s, got %+v", receiver, status.Receipt)
+	}
+	if len(stages) != 3 || stages[0] != CXSent || stages[1] != CXSent || stages[2] != CXCredited {
+		t.Errorf("expected the progress to be sent, resent and credited, got %v", stages)
+	}
+	if balance, _ := network.Balance(receiver, 1); balance.Cmp(oneAsDec.TruncateInt()) != 0 {
+		t.Errorf("expected a single ONE credited on shard 1, got %s", balance)
+	}
+
+	if local, err := send(2, 0); err != nil || local.CXStatus() != nil {
+		t.Errorf("expected a transfer within the shard not to be tracked")
+	}
+	if _, err := tracker.Track(ctx, messenger, *credited.TransactionHash()); err != nil {
+		t.Errorf("expected a credited transfer to be found at once, got %v", err)
+	}
+}
+
+// unminedNode knows a cross shard transaction still waiting in its pool, in no block
+type unminedNode struct {
+	resends int
+}
+
+func (n *unminedNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	switch meth {
+	case rpc.Method.GetTransactionByHash:
+		return rpc.Reply{"result": map[string]interface{}{"shardID": 0, "toShardID": 1, "blockNumber": nil}}, nil
+	case rpc.Method.ResendCX:
+		n.resends++
+		return rpc.Reply{"result": true}, nil
+	}
+	return rpc.Reply{"result": nil}, nil
+}
+
+func TestCXTrackerWaitsForTheSourceBlock(t *testing.T) {
+	node := &unminedNode{}
+	tracker := NewCXTracker(func(T *CXTracker) {
+		T.PollInterval, T.StallTime = 10*time.Millisecond, 50*time.Millisecond
+		T.Shard = func(context.Context, uint32) (rpc.T, error) { return node, nil }
+	})
+	status, err := tracker.Track(context.Background(), node, "0x01")
+	if !errors.Is(err, ErrCXStalled) || status.Stage != CXPending {
+		t.Errorf("expected the transaction to stall out of the source blocks, got %+v %v", status, err)
+	}
+	if node.resends != 0 {
+		t.Errorf("expected no resend of a transaction never mined, got %d", node.resends)
+	}
+}
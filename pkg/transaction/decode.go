@@ -0,0 +1,169 @@
+package transaction
+
+import (
+	"fmt"
+	"math/big"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/harmony/core/types"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// ErrUnknownTransaction is returned for raw bytes that are no signed transaction
+var ErrUnknownTransaction = errors.New("raw transaction is neither a plain, eth nor staking transaction")
+
+// Number of RLP fields of the signed transactions, telling them apart
+const (
+	plainFields   = 11
+	ethFields     = 9
+	stakingFields = 8
+)
+
+// DecodedTransaction is every field of a signed transaction, along with its signer and
+// what is wrong with it, Kind being one of the Envelope kinds
+type DecodedTransaction struct {
+	Kind      string       `json:"kind"`
+	Hash      string       `json:"transaction-hash"`
+	From      string       `json:"from,omitempty"`
+	FromHex   string       `json:"from-hex,omitempty"`
+	ChainID   *big.Int     `json:"chain-id,omitempty"`
+	Chain     string       `json:"chain,omitempty"`
+	Nonce     uint64       `json:"nonce"`
+	GasPrice  *big.Int     `json:"gas-price"`
+	GasLimit  uint64       `json:"gas-limit"`
+	ShardID   uint32       `json:"shard"`
+	ToShardID uint32       `json:"to-shard"`
+	To        string       `json:"to,omitempty"`
+	ToHex     string       `json:"to-hex,omitempty"`
+	Value     *big.Int     `json:"value,omitempty"`
+	Data      string       `json:"data,omitempty"`
+	Directive string       `json:"directive,omitempty"`
+	Message   interface{}  `json:"message,omitempty"`
+	V         *hexutil.Big `json:"v"`
+	R         *hexutil.Big `json:"r"`
+	S         *hexutil.Big `json:"s"`
+	Warnings  []string     `json:"warnings,omitempty"`
+}
+
+func (D *DecodedTransaction) warn(format string, a ...interface{}) {
+	D.Warnings = append(D.Warnings, fmt.Sprintf(format, a...))
+}
+
+// setSignature reports false for a transaction not signed, having no chain-id nor signer
+func (D *DecodedTransaction) setSignature(v, r, s *big.Int) bool {
+	D.V, D.R, D.S = (*hexutil.Big)(v), (*hexutil.Big)(r), (*hexutil.Big)(s)
+	if r.Sign() == 0 && s.Sign() == 0 {
+		D.ChainID = nil
+		D.warn("transaction is not signed")
+		return false
+	}
+	return true
+}
+
+func (D *DecodedTransaction) setSender(sender ethCommon.Address, err error) {
+	if err != nil {
+		D.warn("could not recover the signer: %s", err)
+		return
+	}
+	D.From, D.FromHex = address.ToBech32(sender), sender.Hex()
+}
+
+func (D *DecodedTransaction) setReceiver(to *ethCommon.Address) {
+	if to == nil {
+		D.To = "contract creation"
+		return
+	}
+	D.To, D.ToHex = address.ToBech32(*to), to.Hex()
+}
+
+// checkChain names the chain of the chain-id, returning the shard of an eth compatible one
+func (D *DecodedTransaction) checkChain(protected bool) *uint32 {
+	if !protected {
+		D.warn("signature is not replay protected, it is valid on every chain")
+		return nil
+	}
+	chain, shardID := common.KnownChain(D.ChainID)
+	if chain == nil {
+		D.warn("chain-id %s is of no known chain", D.ChainID)
+		return nil
+	}
+	D.Chain = chain.Name
+	return shardID
+}
+
+// DecodeRawTransaction decodes the hex RLP of a signed transaction, telling plain,
+// eth and staking transactions apart by their number of fields. Unsigned ones, such as
+// the ones of an Envelope, decode with a warning.
+func DecodeRawTransaction(raw string) (*DecodedTransaction, error) {
+	enc, err := hexutil.Decode(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "raw transaction is not hex")
+	}
+	content, _, err := rlp.SplitList(enc)
+	if err != nil {
+		return nil, errors.Wrap(ErrUnknownTransaction, err.Error())
+	}
+	fields, err := rlp.CountValues(content)
+	if err != nil {
+		return nil, errors.Wrap(ErrUnknownTransaction, err.Error())
+	}
+
+	decoded := new(DecodedTransaction)
+	switch fields {
+	case plainFields:
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(enc, tx); err != nil {
+			return nil, errors.Wrap(err, "could not decode the plain transaction")
+		}
+		decoded.Kind, decoded.Hash, decoded.ChainID = EnvelopePlain, tx.Hash().Hex(), tx.ChainID()
+		decoded.Nonce, decoded.GasPrice, decoded.GasLimit = tx.Nonce(), tx.GasPrice(), tx.GasLimit()
+		decoded.ShardID, decoded.ToShardID = tx.ShardID(), tx.ToShardID()
+		decoded.setReceiver(tx.To())
+		decoded.Value, decoded.Data = tx.Value(), hexutil.Encode(tx.Data())
+		if decoded.setSignature(tx.RawSignatureValues()) {
+			decoded.checkChain(tx.Protected())
+			decoded.setSender(types.Sender(types.NewEIP155Signer(tx.ChainID()), tx))
+		}
+	case ethFields:
+		tx := new(types.EthTransaction)
+		if err := rlp.DecodeBytes(enc, tx); err != nil {
+			return nil, errors.Wrap(err, "could not decode the eth transaction")
+		}
+		decoded.Kind, decoded.Hash, decoded.ChainID = EnvelopeEth, tx.Hash().Hex(), tx.ChainID()
+		decoded.Nonce, decoded.GasPrice, decoded.GasLimit = tx.Nonce(), tx.GasPrice(), tx.GasLimit()
+		decoded.setReceiver(tx.To())
+		decoded.Value, decoded.Data = tx.Value(), hexutil.Encode(tx.Data())
+		if decoded.setSignature(tx.RawSignatureValues()) {
+			if shardID := decoded.checkChain(tx.Protected()); shardID != nil {
+				decoded.ShardID, decoded.ToShardID = *shardID, *shardID
+			}
+			decoded.setSender(types.Sender(types.NewEIP155Signer(tx.ChainID()), tx))
+		}
+	case stakingFields:
+		tx := new(staking.StakingTransaction)
+		if err := rlp.DecodeBytes(enc, tx); err != nil {
+			return nil, errors.Wrap(err, "could not decode the staking transaction")
+		}
+		decoded.Kind, decoded.Hash, decoded.ChainID = EnvelopeStaking, tx.Hash().Hex(), tx.ChainID()
+		decoded.Nonce, decoded.GasPrice, decoded.GasLimit = tx.Nonce(), tx.GasPrice(), tx.GasLimit()
+		decoded.ShardID, decoded.ToShardID = tx.ShardID(), tx.ToShardID()
+		decoded.Directive = tx.StakingType().String()
+		msg, err := stakeMessage(tx)
+		if err != nil {
+			decoded.warn("could not decode the %s message: %s", decoded.Directive, err)
+		}
+		decoded.Message = msg
+		if decoded.setSignature(tx.RawSignatureValues()) {
+			decoded.checkChain(tx.Protected())
+			decoded.setSender(staking.Sender(staking.NewEIP155Signer(tx.ChainID()), tx))
+		}
+	default:
+		return nil, errors.Wrapf(ErrUnknownTransaction, "it has %d fields", fields)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,75 @@
+package transaction
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+func TestDecodeRawTransaction(t *testing.T) {
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	from, _ := ks.NewAccount("")
+	ks.Unlock(from, "")
+	to, _ := ks.NewAccount("")
+	raw := func(signed interface{}, err error) string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		enc, _ := rlp.EncodeToBytes(signed)
+		return hexutil.Encode(enc)
+	}
+	decode := func(raw string) *DecodedTransaction {
+		decoded, err := DecodeRawTransaction(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if decoded.From != address.ToBech32(from.Address) || decoded.FromHex != from.Address.Hex() {
+			t.Errorf("expected the %s transaction to be signed by %s, got %s", decoded.Kind, from.Address.Hex(), decoded.FromHex)
+		}
+		return decoded
+	}
+	receiver := address.ToBech32(to.Address)
+	amount, price := numeric.NewDec(3), numeric.NewDec(100)
+
+	plain := NewTransaction(7, 21000, &to.Address, 1, 2, amount, price, []byte{1, 2})
+	decoded := decode(raw(ks.SignTx(from, plain, big.NewInt(2))))
+	if decoded.Kind != EnvelopePlain || decoded.Chain != "testnet" || decoded.Nonce != 7 ||
+		decoded.ShardID != 1 || decoded.ToShardID != 2 || decoded.To != receiver || decoded.Data != "0x0102" {
+		t.Errorf("expected the plain transaction decoded, got %+v", decoded)
+	}
+
+	if unsigned, err := DecodeRawTransaction(raw(plain, nil)); err != nil || unsigned.From != "" || len(unsigned.Warnings) != 1 {
+		t.Errorf("expected the unsigned transaction decoded with a warning, got %+v %v", unsigned, err)
+	}
+
+	eth := NewEthTransaction(8, 21000, to.Address, amount, price, nil)
+	decoded = decode(raw(ks.SignEthTx(from, eth, big.NewInt(1666600001))))
+	if decoded.Kind != EnvelopeEth || decoded.Chain != "mainnet" || decoded.ShardID != 1 || decoded.ToHex != to.Address.Hex() {
+		t.Errorf("expected the eth transaction of shard 1 of mainnet decoded, got %+v", decoded)
+	}
+
+	delegate, _ := staking.NewStakingTransaction(9, 30000, big.NewInt(100), func() (staking.Directive, interface{}) {
+		return staking.DirectiveDelegate, staking.Delegate{
+			DelegatorAddress: from.Address, ValidatorAddress: to.Address, Amount: big.NewInt(5),
+		}
+	})
+	decoded = decode(raw(ks.SignStakingTx(from, delegate, big.NewInt(99))))
+	if message, ok := decoded.Message.(*staking.Delegate); !ok || message.ValidatorAddress != to.Address {
+		t.Errorf("expected the delegation message decoded, got %#v", decoded.Message)
+	}
+	if decoded.Directive != "Delegate" || decoded.Chain != "" || len(decoded.Warnings) != 1 {
+		t.Errorf("expected a delegation on an unknown chain, got %+v", decoded)
+	}
+
+	if _, err := DecodeRawTransaction(raw(types.NewReceipt(nil, false, 0), nil)); !errors.Is(err, ErrUnknownTransaction) {
+		t.Errorf("expected a receipt not to decode as a transaction, got %v", err)
+	}
+}
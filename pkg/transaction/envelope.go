@@ -0,0 +1,200 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
+// Kinds of transaction an Envelope carries
+const (
+	EnvelopePlain   = "plain"
+	EnvelopeEth     = "eth"
+	EnvelopeStaking = "staking"
+)
+
+var (
+	// ErrUnknownEnvelope is returned for an envelope of a kind of transaction not known
+	ErrUnknownEnvelope = errors.New("unknown kind of transaction envelope")
+	// ErrNotSigned is returned when broadcasting an envelope not signed yet
+	ErrNotSigned = errors.New("transaction envelope is not signed")
+)
+
+// Envelope carries a transaction from the machine building it, which fills in the
+// nonce, gas and shards from the network, to the machine signing it, which needs no
+// network access, and back to a machine broadcasting it
+type Envelope struct {
+	Kind    string   `json:"kind"`
+	ChainID *big.Int `json:"chain-id"`
+	From    string   `json:"from"`
+	// ShardID is the shard the transaction is broadcast to
+	ShardID uint32 `json:"shard"`
+	// Unsigned is the hex RLP of the transaction as built
+	Unsigned string `json:"unsigned-transaction"`
+	RawTxn   string `json:"raw-transaction,omitempty"`
+	TxHash   string `json:"transaction-hash,omitempty"`
+}
+
+func newEnvelope(kind string, chain common.ChainID, from ethCommon.Address, shardID uint32, tx interface{}) (*Envelope, error) {
+	enc, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		Kind:     kind,
+		ChainID:  chain.Value,
+		From:     address.ToBech32(from),
+		ShardID:  shardID,
+		Unsigned: hexutil.Encode(enc),
+	}, nil
+}
+
+// Transaction decodes the unsigned transaction, a *types.Transaction,
+// *types.EthTransaction or *staking.StakingTransaction depending on Kind
+func (E *Envelope) Transaction() (interface{}, error) {
+	var tx interface{}
+	switch E.Kind {
+	case EnvelopePlain:
+		tx = new(types.Transaction)
+	case EnvelopeEth:
+		tx = new(types.EthTransaction)
+	case EnvelopeStaking:
+		tx = new(staking.StakingTransaction)
+	default:
+		return nil, errors.Wrap(ErrUnknownEnvelope, E.Kind)
+	}
+	enc, err := hexutil.Decode(E.Unsigned)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode the unsigned transaction")
+	}
+	if err := rlp.DecodeBytes(enc, tx); err != nil {
+		return nil, errors.Wrap(err, "could not decode the unsigned transaction")
+	}
+	return tx, nil
+}
+
+// Sign has signer, which must hold the key of From, sign the transaction and fills in
+// RawTxn and TxHash
+func (E *Envelope) Sign(ctx context.Context, signer Signer) error {
+	if E.ChainID == nil {
+		return errors.New("transaction envelope has no chain id")
+	}
+	if from := address.ToBech32(signer.Address()); from != E.From {
+		return errors.Wrapf(ErrSignerMismatch, "envelope is from %s, signer is %s", E.From, from)
+	}
+	tx, err := E.Transaction()
+	if err != nil {
+		return err
+	}
+	var signed interface{}
+	var hash ethCommon.Hash
+	switch tx := tx.(type) {
+	case *types.Transaction:
+		var s *types.Transaction
+		if s, err = signer.SignTx(ctx, tx, E.ChainID); err == nil {
+			signed, hash = s, s.Hash()
+		}
+	case *types.EthTransaction:
+		var s *types.EthTransaction
+		if s, err = signer.SignEthTx(ctx, tx, E.ChainID); err == nil {
+			signed, hash = s, s.Hash()
+		}
+	case *staking.StakingTransaction:
+		var s *staking.StakingTransaction
+		if s, err = signer.SignStakingTx(ctx, tx, E.ChainID); err == nil {
+			signed, hash = s, s.Hash()
+		}
+	}
+	if err != nil {
+		return err
+	}
+	enc, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		return err
+	}
+	E.RawTxn, E.TxHash = hexutil.Encode(enc), hash.Hex()
+	return nil
+}
+
+// Preview describes the transaction for a person to check before signing it
+func (E *Envelope) Preview() (string, error) {
+	tx, err := E.Transaction()
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	line := func(name string, value interface{}) { fmt.Fprintf(&b, "  %-12s %v\n", name, value) }
+	var nonce, gasLimit uint64
+	var gasPrice *big.Int
+	switch tx := tx.(type) {
+	case *types.Transaction:
+		fmt.Fprintf(&b, "Transfer on chain %s\n", E.ChainID)
+		line("from", fmt.Sprintf("%s (shard %d)", E.From, tx.ShardID()))
+		line("to", fmt.Sprintf("%s (shard %d)", toBech32(tx.To()), tx.ToShardID()))
+		line("amount", formatAtto(tx.Value(), oneAsDec, "ONE"))
+		if len(tx.Data()) > 0 {
+			line("data", fmt.Sprintf("%d bytes", len(tx.Data())))
+		}
+		nonce, gasLimit, gasPrice = tx.Nonce(), tx.GasLimit(), tx.GasPrice()
+	case *types.EthTransaction:
+		fmt.Fprintf(&b, "Eth transfer on chain %s\n", E.ChainID)
+		line("from", E.From)
+		line("to", toBech32(tx.To()))
+		line("amount", formatAtto(tx.Value(), oneAsDec, "ONE"))
+		if len(tx.Data()) > 0 {
+			line("data", fmt.Sprintf("%d bytes", len(tx.Data())))
+		}
+		nonce, gasLimit, gasPrice = tx.Nonce(), tx.GasLimit(), tx.GasPrice()
+	case *staking.StakingTransaction:
+		msg, err := stakeMessage(tx)
+		if err != nil {
+			return "", errors.Wrap(err, "could not decode the staking message")
+		}
+		fmt.Fprintf(&b, "%s on chain %s\n", tx.StakingType(), E.ChainID)
+		line("from", E.From)
+		line("message", strings.ReplaceAll(common.ToJSONUnsafe(msg, true), "\n", "\n  "))
+		nonce, gasLimit, gasPrice = tx.Nonce(), tx.GasLimit(), tx.GasPrice()
+	}
+	line("nonce", nonce)
+	line("gas limit", gasLimit)
+	line("gas price", formatAtto(gasPrice, nanoAsDec, "Gwei"))
+	line("max fee", formatAtto(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit)), oneAsDec, "ONE"))
+	return b.String(), nil
+}
+
+func toBech32(addr *ethCommon.Address) string {
+	if addr == nil {
+		return "contract creation"
+	}
+	return address.ToBech32(*addr)
+}
+
+// stakeMessage is the message of tx typed by its directive. The message decodes as a raw
+// list along with the transaction, its directive types it.
+func stakeMessage(tx *staking.StakingTransaction) (interface{}, error) {
+	enc, err := tx.RLPEncodeStakeMsg()
+	if err != nil {
+		return nil, err
+	}
+	return staking.RLPDecodeStakeMsg(enc, tx.StakingType())
+}
+
+// formatAtto writes the atto amount in the unit of denomination, without trailing zeros
+func formatAtto(amount *big.Int, denomination numeric.Dec, unit string) string {
+	s := numeric.NewDecFromBigInt(amount).Quo(denomination).String()
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s + " " + unit
+}
@@ -0,0 +1,114 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+func TestEnvelope(t *testing.T) {
+	ctx := context.Background()
+	_, messenger, ks, accts := newFundedNetwork(t, []int64{100, 0})
+	from, to := accts[0], accts[1]
+	receiver := address.ToBech32(to.Address)
+	// The online machine knows the address of the sender only
+	watched := accounts.Account{Address: from.Address}
+
+	// signOffline signs the envelope as read back from its JSON
+	signOffline := func(envelope *Envelope) *Envelope {
+		asJSON, _ := json.Marshal(envelope)
+		read := new(Envelope)
+		if err := json.Unmarshal(asJSON, read); err != nil {
+			t.Fatal(err)
+		}
+		offline := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+		if err := read.Sign(ctx, NewKeystoreSigner(offline, &to)); !errors.Is(err, ErrSignerMismatch) {
+			t.Errorf("expected the envelope of another account not to be signed, got %v", err)
+		}
+		if err := read.Sign(ctx, NewKeystoreSigner(ks, &from)); err != nil {
+			t.Fatal(err)
+		}
+		return read
+	}
+
+	builder := NewController(messenger, nil, &watched, common.Chain.TestNet)
+	err := builder.BuildTransaction(ctx, 0, 0, &receiver, 0, 1, numeric.NewDecWithPrec(15, 1), numeric.ZeroDec(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := builder.Envelope()
+	if err != nil {
+		t.Fatal(err)
+	}
+	preview, err := envelope.Preview()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"Transfer on chain 2", receiver + " (shard 1)", "1.5 ONE", "100 Gwei"} {
+		if !strings.Contains(preview, expected) {
+			t.Errorf("expected %q in the preview\n%s", expected, preview)
+		}
+	}
+	signed := signOffline(envelope)
+	sender := NewController(messenger, nil, nil, common.Chain.TestNet, func(c *Controller) {
+		c.Behavior.ConfirmationWaitTime = 5
+	})
+	if err := sender.ExecuteRawTransaction(ctx, signed.RawTxn); err != nil {
+		t.Fatal(err)
+	}
+	if *sender.TransactionHash() != signed.TxHash || sender.Receipt()["result"] == nil {
+		t.Errorf("expected the transaction %s signed offline to be confirmed", signed.TxHash)
+	}
+
+	// An eth transaction goes to the shard its chain-id is of
+	ethChain := common.ChainID{Name: "testnet", Value: big.NewInt(1666700001)}
+	ethBuilder := NewEthController(messenger, nil, &watched, ethChain)
+	if err := ethBuilder.BuildEthTransaction(ctx, 1, 21000, to.Address.Hex(), numeric.NewDec(1), numeric.NewDec(1), nil); err != nil {
+		t.Fatal(err)
+	}
+	if envelope, err = ethBuilder.Envelope(); err != nil || envelope.ShardID != 1 {
+		t.Errorf("expected the eth envelope to go to shard 1, got %+v %v", envelope, err)
+	}
+
+	rate := numeric.NewDecWithPrec(1, 1)
+	ones := oneAsDec.Mul(numeric.NewDec(10)).TruncateInt()
+	stakingBuilder := NewStakingController(messenger, nil, &watched, common.Chain.TestNet)
+	err = stakingBuilder.BuildStakingTransaction(ctx, 1, 0, numeric.ZeroDec(), func() (staking.Directive, interface{}) {
+		return staking.DirectiveCreateValidator, staking.CreateValidator{
+			ValidatorAddress:   from.Address,
+			CommissionRates:    staking.CommissionRates{Rate: rate, MaxRate: rate, MaxChangeRate: rate},
+			MinSelfDelegation:  ones,
+			MaxTotalDelegation: ones,
+			Amount:             ones,
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope, err = stakingBuilder.Envelope(); err != nil {
+		t.Fatal(err)
+	}
+	if preview, _ := envelope.Preview(); !strings.HasPrefix(preview, "CreateValidator on chain 2") {
+		t.Errorf("expected a preview of the staking transaction, got\n%s", preview)
+	}
+	signed = signOffline(envelope)
+	stakingSender := NewStakingController(messenger, nil, nil, common.Chain.TestNet, func(c *StakingController) {
+		c.Behavior.ConfirmationWaitTime = 5
+	})
+	if err := stakingSender.ExecuteRawStakingTransaction(ctx, signed.RawTxn); err != nil {
+		t.Fatal(err)
+	}
+	if *stakingSender.TransactionHash() != signed.TxHash {
+		t.Errorf("expected the staking transaction %s signed offline to be sent", signed.TxHash)
+	}
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -40,9 +41,9 @@ func (e *Error) Error() error {
 // Errors ...
 type Errors []*Error
 
-func getTxErrorBySink(txHash, errorSinkRPC string, messenger rpc.T) (Errors, error) {
+func getTxErrorBySink(ctx context.Context, txHash, errorSinkRPC string, messenger rpc.T) (Errors, error) {
 	var txErrors Errors
-	response, err := messenger.SendRPC(errorSinkRPC, []interface{}{})
+	response, err := messenger.SendRPC(ctx, errorSinkRPC, []interface{}{})
 	if err != nil {
 		return nil, err
 	}
@@ -59,9 +60,9 @@ func getTxErrorBySink(txHash, errorSinkRPC string, messenger rpc.T) (Errors, err
 }
 
 // GetError returns all errors for a given (staking or plain) transaction hash.
-func GetError(txHash string, messenger rpc.T) (Errors, error) {
+func GetError(ctx context.Context, txHash string, messenger rpc.T) (Errors, error) {
 	for _, errorSinkRpc := range errorSinkRPCs {
-		txErrors, err := getTxErrorBySink(txHash, errorSinkRpc, messenger)
+		txErrors, err := getTxErrorBySink(ctx, txHash, errorSinkRpc, messenger)
 		if err != nil {
 			return Errors{}, err
 		}
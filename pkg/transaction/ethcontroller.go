@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/harmony-one/go-sdk/pkg/address"
 	"github.com/harmony-one/go-sdk/pkg/common"
 	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
 	"github.com/harmony-one/harmony/accounts"
 	"github.com/harmony-one/harmony/accounts/keystore"
 	"github.com/harmony-one/harmony/core/types"
@@ -57,11 +59,12 @@ func NewEthController(
 			receipt:         nil,
 		},
 		chain:    chain,
-		Behavior: behavior{false, false, Software, 0},
+		Behavior: behavior{false, false, Software, 0, NewGasEstimator(), nil, SimulateWithData, nil},
 	}
 	for _, option := range options {
 		option(ctrlr)
 	}
+	ctrlr.sender.fromSigner(ctrlr.Behavior)
 	return ctrlr
 }
 
@@ -76,6 +79,9 @@ func (C *EthController) EthTransactionToJSON(pretty bool) string {
 
 // RawTransaction dumps the signature as string
 func (C *EthController) RawTransaction() string {
+	if C.transactionForRPC.signature == nil {
+		return ""
+	}
 	return *C.transactionForRPC.signature
 }
 
@@ -119,7 +125,31 @@ func (C *EthController) setGasPrice(gasPrice numeric.Dec) {
 	C.transactionForRPC.params["gas-price"] = gasPrice.Mul(nanoAsDec)
 }
 
-func (C *EthController) setAmount(amount numeric.Dec) {
+// setEstimatedGas has Behavior.GasEstimator fill the gas limit and price left at zero
+func (C *EthController) setEstimatedGas(ctx context.Context, to string, amount numeric.Dec, data []byte) {
+	if C.executionError != nil || C.Behavior.GasEstimator == nil {
+		return
+	}
+	args := client.CallArgs{From: C.sender.account.Address.Hex(), Data: data}
+	if to != "" {
+		args.To = address.Parse(to).Hex()
+	}
+	if amount.IsPositive() {
+		args.Value = (*hexutil.Big)(amount.Mul(oneAsDec).TruncateInt())
+	}
+	gasLimit, gasPrice, err := C.Behavior.GasEstimator.estimateGas(
+		ctx, C.messenger, C.Behavior.OfflineSign, false, args,
+		C.transactionForRPC.params["gas-limit"].(uint64), C.transactionForRPC.params["gas-price"].(numeric.Dec),
+	)
+	if err != nil {
+		C.executionError = err
+		return
+	}
+	C.transactionForRPC.params["gas-limit"] = gasLimit
+	C.transactionForRPC.params["gas-price"] = gasPrice
+}
+
+func (C *EthController) setAmount(ctx context.Context, amount numeric.Dec) {
 	if C.executionError != nil {
 		return
 	}
@@ -142,7 +172,7 @@ func (C *EthController) setAmount(amount numeric.Dec) {
 
 	if !C.Behavior.OfflineSign {
 		balanceRPCReply, err := C.messenger.SendRPC(
-			rpc.Method.GetBalance,
+			ctx, rpc.Method.GetBalance,
 			p{address.ToBech32(C.sender.account.Address), "latest"},
 		)
 		if err != nil {
@@ -187,14 +217,14 @@ func (C *EthController) setNewTransactionWithDataAndGas(data []byte) {
 	)
 }
 
-func (C *EthController) signAndPrepareTxEncodedForSending() {
+func (C *EthController) signAndPrepareTxEncodedForSending(ctx context.Context) {
 	if C.executionError != nil {
 		return
 	}
-	signedTransaction, err :=
-		C.sender.ks.SignEthTx(*C.sender.account, C.transactionForRPC.transaction, C.chain.Value)
+	signedTransaction, err := C.Behavior.signer(C.sender).SignEthTx(ctx, C.transactionForRPC.transaction, C.chain.Value)
 	if err != nil {
 		C.executionError = err
+		C.transactionErrors = append(C.transactionErrors, signingErrors(err)...)
 		return
 	}
 	C.transactionForRPC.transaction = signedTransaction
@@ -208,33 +238,32 @@ func (C *EthController) signAndPrepareTxEncodedForSending() {
 	}
 }
 
-/*func (C *EthController) hardwareSignAndPrepareTxEncodedForSending() {
+// simulate aborts the transaction when its simulation reverts
+func (C *EthController) simulate(ctx context.Context) {
+	tx := C.transactionForRPC.transaction
 	if C.executionError != nil {
 		return
 	}
-	enc, signerAddr, err := ledger.SignEthTx(C.transactionForRPC.transaction, C.chain.Value)
+	simulates, err := C.Behavior.simulates(tx.Data())
 	if err != nil {
 		C.executionError = err
 		return
 	}
-	if strings.Compare(signerAddr, address.ToBech32(C.sender.account.Address)) != 0 {
-		C.executionError = ErrBadTransactionParam
-		errorMsg := "signature verification failed : sender address doesn't match with ledger hardware addresss"
-		C.transactionErrors = append(C.transactionErrors, &Error{
-			ErrMessage:           &errorMsg,
-			TimestampOfRejection: time.Now().Unix(),
-		})
+	if !simulates {
 		return
 	}
-	hexSignature := hexutil.Encode(enc)
-	C.transactionForRPC.signature = &hexSignature
-}*/
+	args := simulationArgs(C.sender.account.Address, tx.To(), tx.Value(), tx.GasLimit(), tx.GasPrice(), tx.Data())
+	if err := Simulate(ctx, C.messenger, args); err != nil {
+		C.executionError = err
+		C.transactionErrors = append(C.transactionErrors, simulationError(err)...)
+	}
+}
 
-func (C *EthController) sendSignedTx() {
+func (C *EthController) sendSignedTx(ctx context.Context) {
 	if C.executionError != nil || C.Behavior.DryRun {
 		return
 	}
-	reply, err := C.messenger.SendRPC(rpc.Method.SendRawTransaction, p{C.transactionForRPC.signature})
+	reply, err := C.messenger.SendRPC(ctx, rpc.Method.SendRawTransaction, p{C.transactionForRPC.signature})
 	if err != nil {
 		C.executionError = err
 		return
@@ -243,7 +272,7 @@ func (C *EthController) sendSignedTx() {
 	C.transactionForRPC.transactionHash = &r
 }
 
-func (C *EthController) txConfirmation() {
+func (C *EthController) txConfirmation(ctx context.Context) {
 	if C.executionError != nil || C.Behavior.DryRun {
 		return
 	}
@@ -251,12 +280,12 @@ func (C *EthController) txConfirmation() {
 		txHash := *C.TransactionHash()
 		start := int(C.Behavior.ConfirmationWaitTime)
 		for {
-			r, _ := C.messenger.SendRPC(rpc.Method.GetTransactionReceipt, p{txHash})
+			r, _ := C.messenger.SendRPC(ctx, rpc.Method.GetTransactionReceipt, p{txHash})
 			if r["result"] != nil {
 				C.transactionForRPC.receipt = r
 				return
 			}
-			transactionErrors, err := GetError(txHash, C.messenger)
+			transactionErrors, err := GetError(ctx, txHash, C.messenger)
 			if err != nil {
 				errMsg := fmt.Sprintf(err.Error())
 				C.transactionErrors = append(C.transactionErrors, &Error{
@@ -274,7 +303,12 @@ func (C *EthController) txConfirmation() {
 				C.executionError = fmt.Errorf("could not confirm transaction after %d seconds", C.Behavior.ConfirmationWaitTime)
 				return
 			}
-			time.Sleep(time.Second)
+			select {
+			case <-ctx.Done():
+				C.executionError = ctx.Err()
+				return
+			case <-time.After(time.Second):
+			}
 			start--
 		}
 	}
@@ -284,6 +318,7 @@ func (C *EthController) txConfirmation() {
 // Each step in transaction creation, execution probably includes a mutation
 // Each becomes a no-op if executionError occurred in any previous step
 func (C *EthController) ExecuteEthTransaction(
+	ctx context.Context,
 	nonce, gasLimit uint64,
 	to string,
 	amount, gasPrice numeric.Dec,
@@ -292,25 +327,51 @@ func (C *EthController) ExecuteEthTransaction(
 	// WARNING Order of execution matters
 	C.setIntrinsicGas(gasLimit)
 	C.setGasPrice(gasPrice)
-	C.setAmount(amount)
+	C.setEstimatedGas(ctx, to, amount, inputData)
+	C.setAmount(ctx, amount)
 	C.setReceiver(to)
 	C.transactionForRPC.params["nonce"] = nonce
 	C.setNewTransactionWithDataAndGas(inputData)
-	switch C.Behavior.SigningImpl {
-	case Software:
-		C.signAndPrepareTxEncodedForSending()
-		/*case Ledger:
-		C.hardwareSignAndPrepareTxEncodedForSending()*/
-	}
-	C.sendSignedTx()
-	C.txConfirmation()
+	C.signAndPrepareTxEncodedForSending(ctx)
+	C.simulate(ctx)
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
 	return C.executionError
 }
 
-func (C *EthController) ExecuteRawTransaction(txn string) error {
+// BuildEthTransaction fills in the transaction without signing it, see Envelope
+func (C *EthController) BuildEthTransaction(
+	ctx context.Context,
+	nonce, gasLimit uint64,
+	to string,
+	amount, gasPrice numeric.Dec,
+	inputData []byte,
+) error {
+	// WARNING Order of execution matters
+	C.setIntrinsicGas(gasLimit)
+	C.setGasPrice(gasPrice)
+	C.setEstimatedGas(ctx, to, amount, inputData)
+	C.setAmount(ctx, amount)
+	C.setReceiver(to)
+	C.transactionForRPC.params["nonce"] = nonce
+	C.setNewTransactionWithDataAndGas(inputData)
+	return C.executionError
+}
+
+// Envelope wraps the transaction built for it to be signed elsewhere, its shard being the
+// one the eth chain-id is of, shard 0 for a chain-id of no known shard
+func (C *EthController) Envelope() (*Envelope, error) {
+	var shardID uint32
+	if _, ethShard := common.KnownChain(C.chain.Value); ethShard != nil {
+		shardID = *ethShard
+	}
+	return newEnvelope(EnvelopeEth, C.chain, C.sender.account.Address, shardID, C.transactionForRPC.transaction)
+}
+
+func (C *EthController) ExecuteRawTransaction(ctx context.Context, txn string) error {
 	C.transactionForRPC.signature = &txn
 
-	C.sendSignedTx()
-	C.txConfirmation()
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
 	return C.executionError
 }
@@ -0,0 +1,179 @@
+package transaction
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/pkg/errors"
+)
+
+// GasPricePolicy tells where the estimated gas price comes from
+type GasPricePolicy string
+
+const (
+	// NodeGasPrice is the price the node suggests
+	NodeGasPrice GasPricePolicy = "node"
+	// MedianGasPrice is the median price of the transactions of the last PriceBlocks blocks
+	MedianGasPrice GasPricePolicy = "median"
+
+	defaultLimitMultiplier = 1.2
+	defaultPriceBlocks     = 20
+)
+
+var (
+	// DefaultMinGasPrice is the lowest price the network accepts, 100 Gwei
+	DefaultMinGasPrice = numeric.NewDec(100).Mul(nanoAsDec)
+	// ErrUnknownGasPricePolicy is returned for a policy other than node or median
+	ErrUnknownGasPricePolicy = errors.New("unknown gas price policy")
+)
+
+// GasEstimator picks the gas limit and price of the transactions which leave them at zero
+type GasEstimator struct {
+	// LimitMultiplier is the safety margin applied to the gas a call is estimated to use
+	LimitMultiplier float64
+	// PriceMultiplier scales the price of the policy, before MinGasPrice applies
+	PriceMultiplier float64
+	PricePolicy     GasPricePolicy
+	// PriceBlocks is the number of blocks MedianGasPrice looks at
+	PriceBlocks int
+	// MinGasPrice is the lowest price estimated, in atto
+	MinGasPrice numeric.Dec
+}
+
+// NewGasEstimator creates a GasEstimator using the price of the node with 20% of gas to spare
+func NewGasEstimator(options ...func(*GasEstimator)) *GasEstimator {
+	estimator := &GasEstimator{
+		LimitMultiplier: defaultLimitMultiplier,
+		PriceMultiplier: 1,
+		PricePolicy:     NodeGasPrice,
+		PriceBlocks:     defaultPriceBlocks,
+		MinGasPrice:     DefaultMinGasPrice,
+	}
+	for _, option := range options {
+		option(estimator)
+	}
+	return estimator
+}
+
+// IntrinsicGas is the gas a transaction carrying data uses before running any code
+func IntrinsicGas(data []byte, contractCreation bool) (uint64, error) {
+	return core.IntrinsicGas(data, contractCreation, true, true, false)
+}
+
+// GasLimit estimates the gas of the message with the node. The estimate is scaled by
+// LimitMultiplier when the message runs code, a plain transfer using an exact amount.
+func (E *GasEstimator) GasLimit(ctx context.Context, messenger rpc.T, args client.CallArgs) (uint64, error) {
+	estimate, err := client.NewClient(messenger).EstimateGas(ctx, args)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not estimate the gas limit")
+	}
+	intrinsic, err := IntrinsicGas(args.Data, args.To == "")
+	if err != nil {
+		return 0, err
+	}
+	if estimate <= intrinsic || E.LimitMultiplier <= 1 {
+		return estimate, nil
+	}
+	return uint64(float64(estimate) * E.LimitMultiplier), nil
+}
+
+// GasPrice returns the price of PricePolicy scaled by PriceMultiplier, in atto
+func (E *GasEstimator) GasPrice(ctx context.Context, messenger rpc.T) (numeric.Dec, error) {
+	var price *big.Int
+	var err error
+	switch E.PricePolicy {
+	case NodeGasPrice, "":
+		price, err = client.NewClient(messenger).GasPrice(ctx)
+	case MedianGasPrice:
+		price, err = E.medianGasPrice(ctx, client.NewClient(messenger))
+	default:
+		return numeric.Dec{}, errors.Wrapf(ErrUnknownGasPricePolicy, "%s", E.PricePolicy)
+	}
+	if err != nil {
+		return numeric.Dec{}, errors.Wrap(err, "could not estimate the gas price")
+	}
+	estimate := numeric.NewDecFromBigInt(price)
+	if E.PriceMultiplier > 0 {
+		multiplier, err := numeric.NewDecFromStr(big.NewFloat(E.PriceMultiplier).Text('f', 18))
+		if err != nil {
+			return numeric.Dec{}, err
+		}
+		estimate = estimate.Mul(multiplier)
+	}
+	if !E.MinGasPrice.IsNil() && estimate.LT(E.MinGasPrice) {
+		estimate = E.MinGasPrice
+	}
+	return numeric.NewDecFromBigInt(estimate.TruncateInt()), nil
+}
+
+// medianGasPrice is the median price of the transactions of the last blocks, the price
+// of the node when they hold none
+func (E *GasEstimator) medianGasPrice(ctx context.Context, c *client.Client) (*big.Int, error) {
+	head, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	prices := []*big.Int{}
+	add := func(price *hexutil.Big) {
+		if price != nil {
+			prices = append(prices, price.ToInt())
+		}
+	}
+	for i := 0; i < E.PriceBlocks && uint64(i) <= head; i++ {
+		block, err := c.GetBlockByNumber(ctx, client.BlockArg(head-uint64(i)), true)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions {
+			add(tx.GasPrice)
+		}
+		for _, tx := range block.StakingTransactions {
+			add(tx.GasPrice)
+		}
+	}
+	if len(prices) == 0 {
+		return c.GasPrice(ctx)
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	middle := len(prices) / 2
+	if len(prices)%2 == 1 {
+		return prices[middle], nil
+	}
+	sum := new(big.Int).Add(prices[middle-1], prices[middle])
+	return sum.Rsh(sum, 1), nil
+}
+
+// estimateGas returns the gas limit and price of a transaction, estimating those left
+// at zero. The limit of a transaction without data, of a cross shard one, which runs no
+// code on the sending shard, or of one signed offline is its intrinsic gas. Offline, the
+// price is MinGasPrice.
+func (E *GasEstimator) estimateGas(
+	ctx context.Context, messenger rpc.T, offline, crossShard bool, args client.CallArgs,
+	gasLimit uint64, gasPrice numeric.Dec,
+) (uint64, numeric.Dec, error) {
+	var err error
+	if gasLimit == 0 {
+		if offline || crossShard || len(args.Data) == 0 {
+			gasLimit, err = IntrinsicGas(args.Data, args.To == "")
+		} else {
+			gasLimit, err = E.GasLimit(ctx, messenger, args)
+		}
+		if err != nil {
+			return 0, gasPrice, err
+		}
+	}
+	if gasPrice.IsNil() || gasPrice.IsZero() {
+		if offline {
+			gasPrice = E.MinGasPrice
+		} else if gasPrice, err = E.GasPrice(ctx, messenger); err != nil {
+			return 0, gasPrice, err
+		}
+	}
+	return gasLimit, gasPrice, nil
+}
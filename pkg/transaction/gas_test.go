@@ -0,0 +1,105 @@
+package transaction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+// gasNode suggests a price of 1 atto, as harmony nodes do, and holds blocks 0 to 2 whose
+// transactions pay the given prices in Gwei
+type gasNode struct {
+	estimate uint64
+	prices   [][]int64
+}
+
+func (n *gasNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	var result interface{}
+	switch meth {
+	case rpc.Method.GasPrice:
+		result = "0x1"
+	case rpc.Method.EstimateGas:
+		result = hexutil.EncodeUint64(n.estimate)
+	case rpc.Method.BlockNumber:
+		result = hexutil.EncodeUint64(uint64(len(n.prices) - 1))
+	case rpc.Method.GetBlockByNumber:
+		number, _ := hexutil.DecodeUint64(params[0].(string))
+		txs := []map[string]interface{}{}
+		for _, price := range n.prices[number] {
+			txs = append(txs, map[string]interface{}{"gasPrice": hexutil.EncodeBig(numeric.NewDec(price).Mul(nanoAsDec).TruncateInt())})
+		}
+		result = map[string]interface{}{"number": hexutil.EncodeUint64(number), "transactions": txs}
+	default:
+		return nil, fmt.Errorf("unexpected method %s", meth)
+	}
+	return rpc.Reply{"result": result}, nil
+}
+
+func TestGasPrice(t *testing.T) {
+	ctx := context.Background()
+	node := &gasNode{prices: [][]int64{{900}, {}, {100, 300, 200, 400}}}
+	gwei := func(price numeric.Dec) string { return price.Quo(nanoAsDec).TruncateInt().String() }
+
+	price, err := NewGasEstimator().GasPrice(ctx, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gwei(price) != "100" {
+		t.Errorf("expected the price of the node to be raised to 100 Gwei, got %s", price)
+	}
+
+	median := NewGasEstimator(func(e *GasEstimator) {
+		e.PricePolicy = MedianGasPrice
+		e.PriceBlocks = 2
+		e.PriceMultiplier = 1.5
+	})
+	if price, err = median.GasPrice(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	if gwei(price) != "375" {
+		t.Errorf("expected 1.5 times the median of the last 2 blocks, 250 Gwei, got %s Gwei", gwei(price))
+	}
+	median.PriceBlocks = 3
+	if price, _ = median.GasPrice(ctx, node); gwei(price) != "450" {
+		t.Errorf("expected 1.5 times the median of all blocks, 300 Gwei, got %s Gwei", gwei(price))
+	}
+
+	median.PricePolicy = "highest"
+	if _, err := median.GasPrice(ctx, node); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestGasLimit(t *testing.T) {
+	ctx := context.Background()
+	node := &gasNode{estimate: 50000}
+	estimator := NewGasEstimator()
+	call := client.CallArgs{To: nonceSender, Data: []byte{0xa9, 0x05, 0x9c, 0xbb}}
+
+	limit, err := estimator.GasLimit(ctx, node, call)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit != 60000 {
+		t.Errorf("expected the estimate with 20%% to spare, got %d", limit)
+	}
+
+	node.estimate = 21000
+	if limit, _ = estimator.GasLimit(ctx, node, client.CallArgs{To: nonceSender}); limit != 21000 {
+		t.Errorf("expected a plain transfer to use its exact gas, got %d", limit)
+	}
+
+	// Offline, nothing is asked to the node
+	limit, price, err := estimator.estimateGas(ctx, nil, true, false, call, 0, numeric.ZeroDec())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if intrinsic, _ := IntrinsicGas(call.Data, false); limit != intrinsic || !price.Equal(DefaultMinGasPrice) {
+		t.Errorf("expected the intrinsic gas at the minimum price offline, got %d at %s", limit, price)
+	}
+}
@@ -0,0 +1,35 @@
+package transaction
+
+import (
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/fakenode"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+// newFundedNetwork starts a fake network, closed with the test, and creates an unlocked
+// account per amount of funds, credited with that many ONE on shard 0. The messenger
+// reaches shard 0.
+func newFundedNetwork(
+	t *testing.T, funds []int64, options ...func(*fakenode.Network),
+) (*fakenode.Network, rpc.T, *keystore.KeyStore, []accounts.Account) {
+	network := fakenode.NewNetwork(options...)
+	if err := network.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { network.Close() })
+	endpoint, _ := network.Endpoint(0)
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	accts := make([]accounts.Account, len(funds))
+	for i, ones := range funds {
+		accts[i], _ = ks.NewAccount("")
+		ks.Unlock(accts[i], "")
+		if ones > 0 {
+			network.Fund(accts[i].Address.Hex(), 0, oneAsDec.Mul(numeric.NewDec(ones)).TruncateInt())
+		}
+	}
+	return network, rpc.NewHTTPHandler(endpoint), ks, accts
+}
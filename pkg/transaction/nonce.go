@@ -0,0 +1,311 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultNonceDirName is the directory of the nonce state under the config directory
+	DefaultNonceDirName = "nonces"
+
+	defaultLockTimeout    = 10 * time.Second
+	defaultStaleLock      = 30 * time.Second
+	defaultReservationTTL = 2 * time.Minute
+	defaultSentTTL        = 10 * time.Minute
+	defaultBuiltTTL       = 24 * time.Hour
+	lockRetryInterval     = 20 * time.Millisecond
+)
+
+// ErrNonceLocked is returned when the state of an account stays locked by another process
+var ErrNonceLocked = errors.New("nonce state is locked")
+
+// reservation is a nonce handed out, Built once its transaction was output to be signed
+// elsewhere and Sent once the node accepted it, Time being when it was last marked
+type reservation struct {
+	Time  time.Time `json:"time"`
+	Built bool      `json:"built,omitempty"`
+	Sent  bool      `json:"sent"`
+}
+
+// nonceState is what is known of the nonces of an account on a shard: the next one to
+// hand out, those handed out above the chain pending nonce, and the holes left below Next
+type nonceState struct {
+	Next     uint64                 `json:"next"`
+	Reserved map[uint64]reservation `json:"reserved"`
+	Free     []uint64               `json:"free"`
+}
+
+// reconcile drops what the chain has caught up with, frees the reservations never sent
+// within their TTL and those sent but still above the pending nonce after SentTTL, as
+// their transaction fell out of the pool, and brings Next down to the last nonce still
+// held. Every nonce from the pending one up to Next that is not held is then a gap.
+func (s *nonceState) reconcile(pending uint64, now time.Time, m *NonceManager) {
+	if s.Reserved == nil {
+		s.Reserved = map[uint64]reservation{}
+	}
+	for nonce, r := range s.Reserved {
+		switch {
+		case nonce < pending:
+			delete(s.Reserved, nonce)
+		case !r.Sent && !r.Built && now.Sub(r.Time) > m.ReservationTTL:
+			delete(s.Reserved, nonce)
+		case !r.Sent && r.Built && now.Sub(r.Time) > m.BuiltTTL:
+			delete(s.Reserved, nonce)
+		case r.Sent && now.Sub(r.Time) > m.SentTTL:
+			delete(s.Reserved, nonce)
+		}
+	}
+	if s.Next < pending {
+		s.Next = pending
+	}
+	for s.Next > pending {
+		if _, held := s.Reserved[s.Next-1]; held {
+			break
+		}
+		s.Next--
+	}
+	s.Free = []uint64{}
+	for nonce := pending; nonce < s.Next; nonce++ {
+		if _, held := s.Reserved[nonce]; !held {
+			s.Free = append(s.Free, nonce)
+		}
+	}
+}
+
+// NonceManager hands out the nonces of accounts, reserving them locally so that
+// concurrent senders of an account never sign two transactions with the same nonce.
+// Processes sharing Dir cooperate through it, each account of each shard of each chain
+// having its state file there, guarded by a lock file.
+//
+// A reserved nonce is either marked Sent once the node accepts its transaction, or given
+// back with Release. A nonce never sent nor released, as when its sender died, is a gap:
+// the chain holds back every later transaction of the account until it is used, so it
+// is handed out again once ReservationTTL has passed. A nonce marked Built, its
+// transaction being signed elsewhere before it is sent, is held for BuiltTTL instead.
+// A sent nonce the chain has not caught up with after SentTTL had its transaction
+// dropped from the pool, and is a gap as well.
+type NonceManager struct {
+	// Dir holds the state shared with the other processes, it is kept in memory when empty
+	Dir string
+	// LockTimeout bounds the wait for the lock of an account held by another process
+	LockTimeout time.Duration
+	// StaleLock is the age past which a lock is deemed left by a dead process and broken
+	StaleLock time.Duration
+	// ReservationTTL is the time a reserved nonce may stay unsent before being a gap
+	ReservationTTL time.Duration
+	// SentTTL is the time a sent nonce may stay above the chain pending nonce before being a gap
+	SentTTL time.Duration
+	// BuiltTTL is the time a built nonce may stay unsent before being a gap
+	BuiltTTL time.Duration
+	mu       sync.Mutex
+	states   map[string]*nonceState
+}
+
+// NewNonceManager creates a NonceManager keeping its state in memory, options may set Dir
+func NewNonceManager(options ...func(*NonceManager)) *NonceManager {
+	manager := &NonceManager{
+		LockTimeout:    defaultLockTimeout,
+		StaleLock:      defaultStaleLock,
+		ReservationTTL: defaultReservationTTL,
+		SentTTL:        defaultSentTTL,
+		BuiltTTL:       defaultBuiltTTL,
+		states:         map[string]*nonceState{},
+	}
+	for _, option := range options {
+		option(manager)
+	}
+	return manager
+}
+
+// DefaultNonceDir is the nonce directory of the CLI config, ~/.hmy_cli/nonces
+func DefaultNonceDir() (string, error) {
+	uDir, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(uDir, common.DefaultConfigDirName, DefaultNonceDirName), nil
+}
+
+// Reserve hands out the next nonce of addr on the shard, the lowest gap first
+func (m *NonceManager) Reserve(ctx context.Context, chainID *big.Int, addr string, shardID uint32, messenger rpc.T) (uint64, error) {
+	pending, err := pendingNonce(ctx, addr, messenger)
+	if err != nil {
+		return 0, err
+	}
+	var nonce uint64
+	err = m.update(chainID, addr, shardID, func(s *nonceState, now time.Time) {
+		s.reconcile(pending, now, m)
+		if len(s.Free) > 0 {
+			nonce, s.Free = s.Free[0], s.Free[1:]
+		} else {
+			nonce = s.Next
+			s.Next++
+		}
+		s.Reserved[nonce] = reservation{Time: now}
+	})
+	return nonce, err
+}
+
+// Built records that the transaction of a reserved nonce was built to be signed
+// elsewhere, keeping the nonce for BuiltTTL
+func (m *NonceManager) Built(chainID *big.Int, addr string, shardID uint32, nonce uint64) error {
+	return m.update(chainID, addr, shardID, func(s *nonceState, now time.Time) {
+		if r, ok := s.Reserved[nonce]; ok && !r.Sent {
+			r.Built, r.Time = true, now
+			s.Reserved[nonce] = r
+		}
+	})
+}
+
+// Sent records that the node accepted the transaction of a reserved nonce
+func (m *NonceManager) Sent(chainID *big.Int, addr string, shardID uint32, nonce uint64) error {
+	return m.update(chainID, addr, shardID, func(s *nonceState, now time.Time) {
+		if r, ok := s.Reserved[nonce]; ok {
+			r.Sent, r.Time = true, now
+			s.Reserved[nonce] = r
+		}
+	})
+}
+
+// Release gives back a reserved nonce whose transaction was not sent or was rejected,
+// then resyncs the account from the chain, which may have used the nonce meanwhile
+func (m *NonceManager) Release(ctx context.Context, chainID *big.Int, addr string, shardID uint32, nonce uint64, messenger rpc.T) error {
+	err := m.update(chainID, addr, shardID, func(s *nonceState, now time.Time) {
+		if _, ok := s.Reserved[nonce]; ok {
+			delete(s.Reserved, nonce)
+			s.Free = append(s.Free, nonce)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return m.Resync(ctx, chainID, addr, shardID, messenger)
+}
+
+// Resync aligns the state of addr on the shard with the pending nonce of the chain
+func (m *NonceManager) Resync(ctx context.Context, chainID *big.Int, addr string, shardID uint32, messenger rpc.T) error {
+	_, err := m.Gaps(ctx, chainID, addr, shardID, messenger)
+	return err
+}
+
+// Gaps returns the nonces of addr on the shard from the chain pending nonce to the next
+// one handed out that no reservation holds, after resyncing with the chain
+func (m *NonceManager) Gaps(ctx context.Context, chainID *big.Int, addr string, shardID uint32, messenger rpc.T) ([]uint64, error) {
+	pending, err := pendingNonce(ctx, addr, messenger)
+	if err != nil {
+		return nil, err
+	}
+	var gaps []uint64
+	err = m.update(chainID, addr, shardID, func(s *nonceState, now time.Time) {
+		s.reconcile(pending, now, m)
+		gaps = append(gaps, s.Free...)
+	})
+	return gaps, err
+}
+
+// update runs fn on the state of the account, read and written back under its lock
+func (m *NonceManager) update(chainID *big.Int, addr string, shardID uint32, fn func(*nonceState, time.Time)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s-%s-%d", nonceChain(chainID), address.ToBech32(address.Parse(addr)), shardID)
+	if m.Dir == "" {
+		state, ok := m.states[key]
+		if !ok {
+			state = &nonceState{Reserved: map[uint64]reservation{}}
+			m.states[key] = state
+		}
+		fn(state, time.Now())
+		return nil
+	}
+	if err := os.MkdirAll(m.Dir, 0700); err != nil {
+		return err
+	}
+	file := filepath.Join(m.Dir, key+".json")
+	unlock, err := m.lock(file + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	state := &nonceState{}
+	if content, err := ioutil.ReadFile(file); err == nil {
+		if err := json.Unmarshal(content, state); err != nil {
+			return errors.Wrapf(err, "could not decode nonce state %s", file)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	if state.Reserved == nil {
+		state.Reserved = map[uint64]reservation{}
+	}
+	fn(state, time.Now())
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	// Written aside then renamed, a reader never sees half a state
+	if err := ioutil.WriteFile(file+".tmp", content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+// lock creates the lock file, waiting for another process to remove it
+func (m *NonceManager) lock(path string) (func(), error) {
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > m.StaleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Wrap(ErrNonceLocked, path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// nonceChain names the chain of chainID in the state files, the eth compatible chain-ids
+// of a known chain sharing its name as they share the nonces of its accounts
+func nonceChain(chainID *big.Int) string {
+	if chain, _ := common.KnownChain(chainID); chain != nil {
+		return chain.Name
+	}
+	return chainID.String()
+}
+
+// pendingNonce is the next nonce of addr counting the transactions of the pool
+func pendingNonce(ctx context.Context, addr string, messenger rpc.T) (uint64, error) {
+	reply, err := messenger.SendRPC(ctx, rpc.Method.GetTransactionCount, []interface{}{address.Parse(addr), "pending"})
+	if err != nil {
+		return 0, err
+	}
+	count, _ := reply["result"].(string)
+	nonce, err := hexutil.DecodeUint64(count)
+	if err != nil {
+		return 0, errors.Wrapf(err, "could not read the pending nonce of %s", addr)
+	}
+	return nonce, nil
+}
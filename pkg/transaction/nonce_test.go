@@ -0,0 +1,184 @@
+package transaction
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+)
+
+const nonceSender = "one1pdv9lrdwl0rg5vglh4xtyrv3wjk3wsqket7zxy"
+
+var nonceChainID = common.Chain.TestNet.Value
+
+// poolNode reports a fixed pending nonce
+type poolNode struct {
+	mu      sync.Mutex
+	pending uint64
+}
+
+func (n *poolNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return rpc.Reply{"result": hexutil.EncodeUint64(n.pending)}, nil
+}
+
+func TestNonceManagerSharedAcrossProcesses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nonces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	node := &poolNode{pending: 5}
+	// Two managers on one directory stand for two CLI processes
+	managers := []*NonceManager{
+		NewNonceManager(func(m *NonceManager) { m.Dir = dir }),
+		NewNonceManager(func(m *NonceManager) { m.Dir = dir }),
+	}
+	var mu sync.Mutex
+	seen := map[uint64]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(manager *NonceManager) {
+			defer wg.Done()
+			nonce, err := manager.Reserve(context.Background(), nonceChainID, nonceSender, 1, node)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[nonce] {
+				t.Errorf("nonce %d handed out twice", nonce)
+			}
+			seen[nonce] = true
+		}(managers[i%2])
+	}
+	wg.Wait()
+	for nonce := uint64(5); nonce < 25; nonce++ {
+		if !seen[nonce] {
+			t.Errorf("expected nonces 5 to 24, %d is missing from %v", nonce, seen)
+		}
+	}
+	// Another shard of the same account has its own nonces
+	if nonce, _ := managers[0].Reserve(context.Background(), nonceChainID, nonceSender, 0, node); nonce != 5 {
+		t.Errorf("expected shard 0 to start from the pending nonce, got %d", nonce)
+	}
+}
+
+func TestNonceManagerGaps(t *testing.T) {
+	ctx := context.Background()
+	node := &poolNode{pending: 5}
+	manager := NewNonceManager()
+	reserve := func() uint64 {
+		nonce, err := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return nonce
+	}
+	gaps := func() []uint64 {
+		gaps, err := manager.Gaps(ctx, nonceChainID, nonceSender, 0, node)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return gaps
+	}
+
+	reserve()
+	reserve()
+	last := reserve()
+	manager.Sent(nonceChainID, nonceSender, 0, last)
+	if err := manager.Release(ctx, nonceChainID, nonceSender, 0, 6, node); err != nil {
+		t.Fatal(err)
+	}
+	if got := gaps(); !reflect.DeepEqual(got, []uint64{6}) {
+		t.Errorf("expected the released nonce 6 to be a gap, got %v", got)
+	}
+	if nonce := reserve(); nonce != 6 {
+		t.Errorf("expected the gap to be filled first, got %d", nonce)
+	}
+
+	// Never sent, the reservation of 5 expires and becomes a gap, unlike the sent 7
+	manager.ReservationTTL = 0
+	if got := gaps(); !reflect.DeepEqual(got, []uint64{5, 6}) {
+		t.Errorf("expected the unsent nonces to expire into gaps, got %v", got)
+	}
+
+	node.pending = 8
+	if got := gaps(); len(got) != 0 {
+		t.Errorf("expected no gap once the chain caught up, got %v", got)
+	}
+	if nonce := reserve(); nonce != 8 {
+		t.Errorf("expected the pending nonce of the chain, got %d", nonce)
+	}
+}
+
+func TestNonceManagerDroppedSentNonce(t *testing.T) {
+	ctx := context.Background()
+	node := &poolNode{pending: 3}
+	manager := NewNonceManager()
+	dropped, _ := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node)
+	manager.Sent(nonceChainID, nonceSender, 0, dropped)
+	// 4 is being signed while the transaction of 3 falls out of the pool
+	if held, _ := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node); held != 4 {
+		t.Fatalf("expected 4 to be reserved, got %d", held)
+	}
+	if got, _ := manager.Gaps(ctx, nonceChainID, nonceSender, 0, node); len(got) != 0 {
+		t.Errorf("expected no gap while the sent nonce may still be pooled, got %v", got)
+	}
+	manager.SentTTL = 0
+	if got, _ := manager.Gaps(ctx, nonceChainID, nonceSender, 0, node); !reflect.DeepEqual(got, []uint64{3}) {
+		t.Errorf("expected the sent nonce the chain never caught up with to be a gap, got %v", got)
+	}
+	if nonce, _ := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node); nonce != 3 {
+		t.Errorf("expected the dropped nonce to be handed out again, got %d", nonce)
+	}
+}
+
+func TestNonceManagerPerChain(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "nonces")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	manager := NewNonceManager(func(m *NonceManager) { m.Dir = dir })
+	mainnet, testnet := &poolNode{pending: 40}, &poolNode{pending: 3}
+	if nonce, _ := manager.Reserve(ctx, common.Chain.MainNet.Value, nonceSender, 0, mainnet); nonce != 40 {
+		t.Errorf("expected the pending nonce of mainnet, got %d", nonce)
+	}
+	if nonce, _ := manager.Reserve(ctx, common.Chain.TestNet.Value, nonceSender, 0, testnet); nonce != 3 {
+		t.Errorf("expected testnet not to follow the nonces of mainnet, got %d", nonce)
+	}
+	// The eth compatible chain-id of testnet shard 0 shares the nonces of testnet
+	if nonce, _ := manager.Reserve(ctx, big.NewInt(1666700000), nonceSender, 0, testnet); nonce != 4 {
+		t.Errorf("expected the eth chain-id to share the nonces of testnet, got %d", nonce)
+	}
+}
+
+func TestNonceManagerBuiltNonce(t *testing.T) {
+	ctx := context.Background()
+	node := &poolNode{pending: 3}
+	manager := NewNonceManager(func(m *NonceManager) { m.ReservationTTL = 0 })
+	built, _ := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node)
+	manager.Built(nonceChainID, nonceSender, 0, built)
+	// Being signed elsewhere, the built nonce outlives the unsent reservations
+	later, _ := manager.Reserve(ctx, nonceChainID, nonceSender, 0, node)
+	if later != 4 {
+		t.Errorf("expected the built nonce to stay held, got %d", later)
+	}
+	manager.Sent(nonceChainID, nonceSender, 0, later)
+	manager.BuiltTTL = 0
+	if got, _ := manager.Gaps(ctx, nonceChainID, nonceSender, 0, node); !reflect.DeepEqual(got, []uint64{3}) {
+		t.Errorf("expected the built nonce never sent to be a gap after BuiltTTL, got %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package transaction
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/harmony-one/harmony/numeric"
+	"github.com/pkg/errors"
+)
+
+// DefaultPriceBump is the percentage the gas price of a replacement must exceed the
+// price of the pending transaction by, the default of the harmony pool
+const DefaultPriceBump = 10
+
+var (
+	// ErrTransactionNotFound is returned for a hash neither in the pool nor in the chain
+	ErrTransactionNotFound = errors.New("transaction not found")
+	// ErrNotPending is returned when the transaction to replace is already in a block
+	ErrNotPending = errors.New("transaction is no longer pending")
+)
+
+// PendingTransaction returns the plain transaction hash waiting in the pool. A mined
+// transaction is looked up in the chain to report ErrNotPending.
+func PendingTransaction(ctx context.Context, messenger rpc.T, hash string) (*client.Transaction, error) {
+	c := client.NewClient(messenger)
+	pool, err := c.GetPendingTxnsInPool(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the pool")
+	}
+	for i := range pool {
+		if strings.EqualFold(pool[i].Hash.Hex(), hash) {
+			return &pool[i], nil
+		}
+	}
+	tx, err := c.GetTransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, errors.Wrap(ErrTransactionNotFound, hash)
+	}
+	if tx.BlockNumber != nil {
+		return tx, errors.Wrapf(ErrNotPending, "%s is in block %s", hash, tx.BlockNumber.ToInt())
+	}
+	return tx, nil
+}
+
+// BumpGasPrice raises price by bump percent, rounding up
+func BumpGasPrice(price *big.Int, bump uint64) *big.Int {
+	bumped := new(big.Int).Mul(price, new(big.Int).SetUint64(100+bump))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Quo(bumped, big.NewInt(100))
+}
+
+// Replacement is a transaction taking the nonce of a pending one, either the same
+// transaction at a higher gas price or, to cancel it, an empty transfer to its sender
+type Replacement struct {
+	Original *client.Transaction
+	Cancel   bool
+	// GasPrice is the price of the replacement, in atto
+	GasPrice *big.Int
+}
+
+// NewReplacement prepares the replacement of the pending transaction hash. Its gas
+// price is the one of the original raised by bump percent, or the price estimator
+// gives when higher.
+func NewReplacement(
+	ctx context.Context, messenger rpc.T, hash string, cancel bool, bump uint64, estimator *GasEstimator,
+) (*Replacement, error) {
+	original, err := PendingTransaction(ctx, messenger, hash)
+	if err != nil {
+		return nil, err
+	}
+	if original.GasPrice == nil {
+		return nil, errors.Errorf("transaction %s has no gas price", hash)
+	}
+	price := BumpGasPrice(original.GasPrice.ToInt(), bump)
+	if estimator != nil {
+		estimate, err := estimator.GasPrice(ctx, messenger)
+		if err != nil {
+			return nil, err
+		}
+		if estimate.TruncateInt().Cmp(price) > 0 {
+			price = estimate.TruncateInt()
+		}
+	}
+	return &Replacement{Original: original, Cancel: cancel, GasPrice: price}, nil
+}
+
+// Execute signs and sends the replacement with C, which must hold the key of the sender
+func (R *Replacement) Execute(ctx context.Context, C *Controller) error {
+	tx := R.Original
+	to, toShardID, amount, data := &tx.To, tx.ToShardID, numeric.ZeroDec(), []byte(tx.Input)
+	gasLimit := uint64(tx.Gas)
+	if R.Cancel {
+		from := address.ToBech32(address.Parse(tx.From))
+		to, toShardID, data = &from, tx.ShardID, nil
+		gasLimit, _ = IntrinsicGas(nil, false)
+	} else {
+		if tx.To == "" {
+			to = nil
+		}
+		if tx.Value != nil {
+			amount = numeric.NewDecFromBigInt(tx.Value.ToInt()).Quo(oneAsDec)
+		}
+	}
+	price := numeric.NewDecFromBigInt(R.GasPrice).Quo(nanoAsDec)
+	return C.ExecuteTransaction(
+		ctx, uint64(tx.Nonce), gasLimit, to, tx.ShardID, toShardID, amount, price, data,
+	)
+}
+
+// WaitMined polls the receipts of the transactions sharing a nonce until one of them is
+// in a block, returning its hash and receipt. It gives up after timeout.
+func WaitMined(
+	ctx context.Context, messenger rpc.T, hashes []string, timeout time.Duration,
+) (string, *client.Receipt, error) {
+	c := client.NewClient(messenger)
+	deadline := time.Now().Add(timeout)
+	for {
+		for _, hash := range hashes {
+			receipt, err := c.GetTransactionReceipt(ctx, hash)
+			if err != nil {
+				return "", nil, err
+			}
+			if receipt != nil {
+				return hash, receipt, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return "", nil, errors.Errorf("none of %s was mined after %s", strings.Join(hashes, ", "), timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
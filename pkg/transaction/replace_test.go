@@ -0,0 +1,80 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/fakenode"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+func TestBumpGasPrice(t *testing.T) {
+	for price, expected := range map[int64]int64{1000: 1100, 1001: 1102, 0: 0} {
+		if bumped := BumpGasPrice(big.NewInt(price), DefaultPriceBump); bumped.Int64() != expected {
+			t.Errorf("expected %d bumped by 10%% to be %d, got %s", price, expected, bumped)
+		}
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	ctx := context.Background()
+	network, messenger, ks, accts := newFundedNetwork(t, []int64{10, 0}, func(n *fakenode.Network) { n.AutoMine = false })
+	from, to := accts[0], accts[1]
+	sender, receiver := address.ToBech32(from.Address), address.ToBech32(to.Address)
+
+	send := func(nonce uint64) string {
+		controller := NewController(messenger, ks, &from, common.Chain.TestNet)
+		err := controller.ExecuteTransaction(ctx, nonce, 21000, &receiver, 0, 0, numeric.NewDec(1), numeric.NewDec(1), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *controller.TransactionHash()
+	}
+	replace := func(hash string, cancel bool, estimator *GasEstimator) (*Replacement, string) {
+		replacement, err := NewReplacement(ctx, messenger, hash, cancel, DefaultPriceBump, estimator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		controller := NewController(messenger, ks, &from, common.Chain.TestNet)
+		if err := replacement.Execute(ctx, controller); err != nil {
+			t.Fatal(err)
+		}
+		return replacement, *controller.TransactionHash()
+	}
+
+	original := send(0)
+	spedUp, hash := replace(original, false, nil)
+	if spedUp.GasPrice.Cmp(big.NewInt(1100000000)) != 0 {
+		t.Errorf("expected the price of 1 Gwei bumped by 10%%, got %s", spedUp.GasPrice)
+	}
+	network.Mine()
+	landed, receipt, err := WaitMined(ctx, messenger, []string{original, hash}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if landed != hash || receipt.To != receiver {
+		t.Errorf("expected the speed-up %s to land, got %s to %s", hash, landed, receipt.To)
+	}
+	if _, err := NewReplacement(ctx, messenger, hash, false, DefaultPriceBump, nil); !errors.Is(err, ErrNotPending) {
+		t.Errorf("expected a mined transaction not to be replaced, got %v", err)
+	}
+
+	original = send(1)
+	cancelled, hash := replace(original, true, NewGasEstimator())
+	if cancelled.GasPrice.Cmp(DefaultMinGasPrice.TruncateInt()) != 0 {
+		t.Errorf("expected the estimated price to win over the bumped one, got %s", cancelled.GasPrice)
+	}
+	network.Mine()
+	landed, receipt, err = WaitMined(ctx, messenger, []string{original, hash}, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if landed != hash || receipt.To != sender {
+		t.Errorf("expected the cancellation %s to land as a transfer to the sender, got %s to %s", hash, landed, receipt.To)
+	}
+}
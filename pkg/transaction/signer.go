@@ -1,8 +1,328 @@
 package transaction
 
+import (
+	"context"
+	"math/big"
+	"strings"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/ledger"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/core/types"
+	staking "github.com/harmony-one/harmony/staking/types"
+	"github.com/pkg/errors"
+)
+
 type SignerImpl int
 
 const (
 	Software SignerImpl = iota
 	Ledger
 )
+
+var (
+	// ErrSignerUnsupported is returned by a Signer for a kind of signature it cannot make
+	ErrSignerUnsupported = errors.New("signer does not support this signature")
+	// ErrSignerMismatch is returned when a signature is not made by the account of the signer,
+	// or not over the transaction it was asked for
+	ErrSignerMismatch = errors.New("signature is not made by the account of the signer")
+)
+
+// Signer signs the transactions of a single account, wherever its key is kept
+type Signer interface {
+	// Address is the account the signatures are made by
+	Address() ethCommon.Address
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	SignEthTx(ctx context.Context, tx *types.EthTransaction, chainID *big.Int) (*types.EthTransaction, error)
+	SignStakingTx(ctx context.Context, tx *staking.StakingTransaction, chainID *big.Int) (*staking.StakingTransaction, error)
+	// SignHash returns the [R || S || V] signature of hash, V being 0 or 1
+	SignHash(ctx context.Context, hash []byte) ([]byte, error)
+}
+
+// signer is Behavior.Signer, or the signer SigningImpl names for the account of s
+func (b behavior) signer(s sender) Signer {
+	if b.Signer != nil {
+		return b.Signer
+	}
+	if b.SigningImpl == Ledger {
+		return NewLedgerSigner(s.account.Address)
+	}
+	return NewKeystoreSigner(s.ks, s.account)
+}
+
+// fromSigner makes the account of Behavior.Signer the sender when none was given
+func (s *sender) fromSigner(b behavior) {
+	if s.account == nil && b.Signer != nil {
+		s.account = &accounts.Account{Address: b.Signer.Address()}
+	}
+}
+
+// signingErrors reports a signature made by another account as a transaction error
+func signingErrors(err error) Errors {
+	if errors.Cause(err) != ErrSignerMismatch {
+		return nil
+	}
+	errorMsg := "signature verification failed : " + err.Error()
+	return Errors{&Error{
+		ErrMessage:           &errorMsg,
+		TimestampOfRejection: time.Now().Unix(),
+	}}
+}
+
+// KeystoreSigner signs with an unlocked account of a keystore
+type KeystoreSigner struct {
+	ks      *keystore.KeyStore
+	account *accounts.Account
+}
+
+// NewKeystoreSigner creates a KeystoreSigner, account must be unlocked in ks
+func NewKeystoreSigner(ks *keystore.KeyStore, account *accounts.Account) *KeystoreSigner {
+	return &KeystoreSigner{ks, account}
+}
+
+// Address is the address of the account
+func (s *KeystoreSigner) Address() ethCommon.Address {
+	return s.account.Address
+}
+
+func (s *KeystoreSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if s.ks == nil {
+		return nil, errors.New("no keystore to sign with")
+	}
+	return s.ks.SignTx(*s.account, tx, chainID)
+}
+
+func (s *KeystoreSigner) SignEthTx(ctx context.Context, tx *types.EthTransaction, chainID *big.Int) (*types.EthTransaction, error) {
+	if s.ks == nil {
+		return nil, errors.New("no keystore to sign with")
+	}
+	return s.ks.SignEthTx(*s.account, tx, chainID)
+}
+
+func (s *KeystoreSigner) SignStakingTx(
+	ctx context.Context, tx *staking.StakingTransaction, chainID *big.Int,
+) (*staking.StakingTransaction, error) {
+	if s.ks == nil {
+		return nil, errors.New("no keystore to sign with")
+	}
+	return s.ks.SignStakingTx(*s.account, tx, chainID)
+}
+
+func (s *KeystoreSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	if s.ks == nil {
+		return nil, errors.New("no keystore to sign with")
+	}
+	return s.ks.SignHash(*s.account, hash)
+}
+
+// LedgerSigner signs with the account of the Ledger hardware wallet plugged in, which
+// only signs plain and staking transactions
+type LedgerSigner struct {
+	address ethCommon.Address
+}
+
+// NewLedgerSigner creates a LedgerSigner, addr being the account of the wallet
+func NewLedgerSigner(addr ethCommon.Address) *LedgerSigner {
+	return &LedgerSigner{addr}
+}
+
+// Address is the account of the wallet
+func (s *LedgerSigner) Address() ethCommon.Address {
+	return s.address
+}
+
+// checkSigner makes sure the wallet signed with the expected account
+func (s *LedgerSigner) checkSigner(signerAddr string) error {
+	if strings.Compare(signerAddr, address.ToBech32(s.address)) != 0 {
+		return errors.Wrap(ErrSignerMismatch, "sender address doesn't match with ledger hardware addresss")
+	}
+	return nil
+}
+
+func (s *LedgerSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	enc, signerAddr, err := ledger.SignTx(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSigner(signerAddr); err != nil {
+		return nil, err
+	}
+	signed := new(types.Transaction)
+	if err := rlp.DecodeBytes(enc, signed); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *LedgerSigner) SignEthTx(ctx context.Context, tx *types.EthTransaction, chainID *big.Int) (*types.EthTransaction, error) {
+	return nil, errors.Wrap(ErrSignerUnsupported, "ledger can not sign eth transactions")
+}
+
+func (s *LedgerSigner) SignStakingTx(
+	ctx context.Context, tx *staking.StakingTransaction, chainID *big.Int,
+) (*staking.StakingTransaction, error) {
+	signed, signerAddr, err := ledger.SignStakingTx(tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSigner(signerAddr); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *LedgerSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	return nil, errors.Wrap(ErrSignerUnsupported, "ledger can not sign hashes")
+}
+
+// Methods of the remote signer protocol
+const (
+	RemoteSignTransaction        = "signer_signTransaction"
+	RemoteSignEthTransaction     = "signer_signEthTransaction"
+	RemoteSignStakingTransaction = "signer_signStakingTransaction"
+	RemoteSignHash               = "signer_signHash"
+)
+
+// RemoteSigner asks a signing service to sign, over JSON-RPC. The transaction methods
+// take the hex address of the account, the hex RLP of the unsigned transaction and the
+// hex chain id, and answer the hex RLP of the signed transaction; signer_signHash takes
+// the address and the hex hash and answers the hex signature. Every signature is
+// checked to be made by the account, over the transaction sent, before being used.
+type RemoteSigner struct {
+	// Messenger carries the calls to the service, the middlewares of rpc adding its credentials
+	Messenger rpc.T
+	address   ethCommon.Address
+}
+
+// NewRemoteSigner creates a RemoteSigner of addr for the service at endpoint
+func NewRemoteSigner(endpoint string, addr ethCommon.Address, options ...func(*RemoteSigner)) *RemoteSigner {
+	signer := &RemoteSigner{Messenger: rpc.NewHTTPHandler(endpoint), address: addr}
+	for _, option := range options {
+		option(signer)
+	}
+	return signer
+}
+
+// Address is the account the service signs for
+func (s *RemoteSigner) Address() ethCommon.Address {
+	return s.address
+}
+
+// call sends meth and decodes the hex bytes it answers
+func (s *RemoteSigner) call(ctx context.Context, meth string, params ...interface{}) ([]byte, error) {
+	reply, err := s.Messenger.SendRPC(ctx, meth, append([]interface{}{s.address.Hex()}, params...))
+	if err != nil {
+		return nil, errors.Wrapf(err, "remote signer %s", meth)
+	}
+	result, _ := reply["result"].(string)
+	decoded, err := hexutil.Decode(result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode the answer of remote signer %s", meth)
+	}
+	return decoded, nil
+}
+
+// signRLP has the service sign the transaction tx and decodes it into signed
+func (s *RemoteSigner) signRLP(ctx context.Context, meth string, tx, signed interface{}, chainID *big.Int) error {
+	unsigned, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return err
+	}
+	enc, err := s.call(ctx, meth, hexutil.Encode(unsigned), (*hexutil.Big)(chainID))
+	if err != nil {
+		return err
+	}
+	return rlp.DecodeBytes(enc, signed)
+}
+
+// checkSender makes sure the recovered sender of a signed transaction is the account
+func (s *RemoteSigner) checkSender(sender ethCommon.Address, err error) error {
+	if err != nil {
+		return errors.Wrap(ErrSignerMismatch, err.Error())
+	}
+	if sender != s.address {
+		return errors.Wrapf(ErrSignerMismatch, "signed by %s", sender.Hex())
+	}
+	return nil
+}
+
+// checkSigned makes sure the service signed the transaction it was sent, and not
+// another one of the account
+func checkSigned(sent, signed ethCommon.Hash) error {
+	if sent != signed {
+		return errors.Wrap(ErrSignerMismatch, "the signed transaction is not the one sent")
+	}
+	return nil
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signed := new(types.Transaction)
+	if err := s.signRLP(ctx, RemoteSignTransaction, tx, signed, chainID); err != nil {
+		return nil, err
+	}
+	signer := types.NewEIP155Signer(chainID)
+	if err := checkSigned(signer.Hash(tx), signer.Hash(signed)); err != nil {
+		return nil, err
+	}
+	if err := s.checkSender(types.Sender(signer, signed)); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *RemoteSigner) SignEthTx(ctx context.Context, tx *types.EthTransaction, chainID *big.Int) (*types.EthTransaction, error) {
+	signed := new(types.EthTransaction)
+	if err := s.signRLP(ctx, RemoteSignEthTransaction, tx, signed, chainID); err != nil {
+		return nil, err
+	}
+	signer := types.NewEIP155Signer(chainID)
+	if err := checkSigned(signer.Hash(tx), signer.Hash(signed)); err != nil {
+		return nil, err
+	}
+	if err := s.checkSender(types.Sender(signer, signed)); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *RemoteSigner) SignStakingTx(
+	ctx context.Context, tx *staking.StakingTransaction, chainID *big.Int,
+) (*staking.StakingTransaction, error) {
+	signed := new(staking.StakingTransaction)
+	if err := s.signRLP(ctx, RemoteSignStakingTransaction, tx, signed, chainID); err != nil {
+		return nil, err
+	}
+	signer := staking.NewEIP155Signer(chainID)
+	if err := checkSigned(signer.Hash(tx), signer.Hash(signed)); err != nil {
+		return nil, err
+	}
+	if err := s.checkSender(staking.Sender(signer, signed)); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *RemoteSigner) SignHash(ctx context.Context, hash []byte) ([]byte, error) {
+	signature, err := s.call(ctx, RemoteSignHash, hexutil.Encode(hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) != 65 {
+		return nil, errors.Errorf("remote signer answered a signature of %d bytes", len(signature))
+	}
+	pub, err := crypto.SigToPub(hash, signature)
+	if err != nil {
+		return nil, s.checkSender(ethCommon.Address{}, err)
+	}
+	if err := s.checkSender(crypto.PubkeyToAddress(*pub), nil); err != nil {
+		return nil, err
+	}
+	return signature, nil
+}
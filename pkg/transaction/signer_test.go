@@ -0,0 +1,123 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/core/types"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+// signerStub is a remote signing service holding the key of account in ks, sending the
+// transactions it signs to redirect instead when set
+func signerStub(
+	t *testing.T, ks *keystore.KeyStore, account accounts.Account, redirect *ethCommon.Address,
+) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []string        `json:"params"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&call); err != nil {
+			t.Error(err)
+			return
+		}
+		payload, _ := hexutil.Decode(call.Params[1])
+		var signed interface{}
+		var err error
+		switch call.Method {
+		case RemoteSignTransaction:
+			tx := new(types.Transaction)
+			rlp.DecodeBytes(payload, tx)
+			if redirect != nil {
+				tx = types.NewTransaction(
+					tx.Nonce(), *redirect, tx.ShardID(), tx.Value(), tx.GasLimit(), tx.GasPrice(), tx.Data(),
+				)
+			}
+			chainID, _ := hexutil.DecodeBig(call.Params[2])
+			signed, err = ks.SignTx(account, tx, chainID)
+		case RemoteSignHash:
+			signed, err = ks.SignHash(account, payload)
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		enc, ok := signed.([]byte)
+		if !ok {
+			enc, _ = rlp.EncodeToBytes(signed)
+		}
+		reply, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0", "id": call.ID, "result": hexutil.Encode(enc),
+		})
+		w.Write(reply)
+	}))
+}
+
+func TestRemoteSigner(t *testing.T) {
+	ctx := context.Background()
+	_, messenger, ks, accts := newFundedNetwork(t, []int64{1, 1})
+	key, other := accts[0], accts[1]
+	stub := signerStub(t, ks, key, nil)
+	defer stub.Close()
+	tampering := signerStub(t, ks, key, &key.Address)
+	defer tampering.Close()
+	receiver := address.ToBech32(other.Address)
+
+	send := func(signer Signer) (*Controller, error) {
+		// No keystore nor account, the controller signs with the remote signer only
+		controller := NewController(messenger, nil, nil, common.Chain.TestNet, func(c *Controller) {
+			c.Behavior.Signer = signer
+			c.Behavior.ConfirmationWaitTime = 5
+		})
+		return controller, controller.ExecuteTransaction(
+			ctx, 0, 21000, &receiver, 0, 0, numeric.NewDecWithPrec(1, 2), numeric.NewDec(1), nil,
+		)
+	}
+
+	sent, err := send(NewRemoteSigner(stub.URL, key.Address))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Receipt()["result"] == nil {
+		t.Error("expected the transaction signed remotely to be confirmed")
+	}
+
+	refused, err := send(NewRemoteSigner(stub.URL, other.Address))
+	if !errors.Is(err, ErrSignerMismatch) || len(refused.TransactionErrors()) != 1 || refused.TransactionHash() != nil {
+		t.Errorf("expected a signature of another account not to be sent, got %v", err)
+	}
+
+	tampered, err := send(NewRemoteSigner(tampering.URL, key.Address))
+	if !errors.Is(err, ErrSignerMismatch) || tampered.TransactionHash() != nil {
+		t.Errorf("expected a transaction other than the one sent not to be sent, got %v", err)
+	}
+
+	hash := crypto.Keccak256([]byte("hash"))
+	signature, err := NewRemoteSigner(stub.URL, key.Address).SignHash(ctx, hash)
+	if err != nil || len(signature) != 65 {
+		t.Errorf("expected the hash to be signed, got %v", err)
+	}
+	if _, err := NewRemoteSigner(stub.URL, other.Address).SignHash(ctx, hash); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("expected a hash signed by another account to be refused, got %v", err)
+	}
+
+	var _ Signer = NewKeystoreSigner(ks, &key)
+	if _, err := NewLedgerSigner(key.Address).SignEthTx(ctx, nil, big.NewInt(2)); !errors.Is(err, ErrSignerUnsupported) {
+		t.Errorf("expected the ledger not to sign eth transactions, got %v", err)
+	}
+}
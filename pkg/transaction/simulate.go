@@ -0,0 +1,164 @@
+package transaction
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/pkg/errors"
+)
+
+// SimulationPolicy tells which transactions are run through Call before being sent
+type SimulationPolicy string
+
+const (
+	// SimulateWithData runs the transactions carrying data, the contract calls
+	SimulateWithData SimulationPolicy = "data"
+	// SimulateAlways runs every transaction
+	SimulateAlways SimulationPolicy = "always"
+	// SimulateNever sends the transactions as they are
+	SimulateNever SimulationPolicy = "never"
+)
+
+var (
+	// ErrReverted is matched by the RevertError of a transaction whose simulation reverted
+	ErrReverted = errors.New("transaction would revert")
+	// ErrUnknownSimulationPolicy is returned for a policy other than data, always or never
+	ErrUnknownSimulationPolicy = errors.New("unknown simulation policy")
+)
+
+var (
+	// selectors of the Error(string) and Panic(uint256) revert payloads of solidity
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// RevertError is the outcome of a simulation that reverted, Reason being decoded from
+// the data the contract reverted with when it is an Error or a Panic
+type RevertError struct {
+	Reason string
+	Data   []byte
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason == "" {
+		return ErrReverted.Error()
+	}
+	return fmt.Sprintf("%s: %s", ErrReverted, e.Reason)
+}
+
+// Is makes errors.Is match ErrReverted
+func (e *RevertError) Is(target error) bool {
+	return target == ErrReverted
+}
+
+// DecodeRevertReason decodes the reason of the Error(string) or Panic(uint256) data a
+// contract reverted with, false when data is neither
+func DecodeRevertReason(data []byte) (string, bool) {
+	if len(data) < 4+32 {
+		return "", false
+	}
+	selector, payload := data[:4], data[4:]
+	switch {
+	case bytes.Equal(selector, panicSelector):
+		return fmt.Sprintf("panic code 0x%x", new(big.Int).SetBytes(payload[:32])), true
+	case bytes.Equal(selector, errorSelector) && len(payload) >= 64:
+		offset := new(big.Int).SetBytes(payload[:32])
+		if !offset.IsUint64() || offset.Uint64() > uint64(len(payload)-32) {
+			return "", false
+		}
+		start := offset.Uint64() + 32
+		length := new(big.Int).SetBytes(payload[offset.Uint64():start])
+		if !length.IsUint64() || length.Uint64() > uint64(len(payload))-start {
+			return "", false
+		}
+		return string(payload[start : start+length.Uint64()]), true
+	}
+	return "", false
+}
+
+// revertData is the payload of the error of a node reverting the call, as eth nodes
+// answer, nil for any other error
+func revertData(err error) ([]byte, bool) {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return nil, false
+	}
+	if data, ok := rpcErr.Data.(string); ok {
+		if decoded, decodeErr := hexutil.Decode(data); decodeErr == nil {
+			return decoded, true
+		}
+	}
+	return nil, strings.Contains(rpcErr.Message, "revert")
+}
+
+// Simulate runs the message through Call against the pending block, returning a
+// RevertError when it reverts. Harmony nodes answer the data a call reverted with as
+// its result, which is told apart from a genuine result by its Error or Panic selector.
+func Simulate(ctx context.Context, messenger rpc.T, args client.CallArgs) error {
+	result, err := client.NewClient(messenger).Call(ctx, args, "pending")
+	if err != nil {
+		if data, reverted := revertData(err); reverted {
+			reason, _ := DecodeRevertReason(data)
+			return &RevertError{Reason: reason, Data: data}
+		}
+		return errors.Wrap(err, "could not simulate the transaction")
+	}
+	if reason, reverted := DecodeRevertReason(result); reverted {
+		return &RevertError{Reason: reason, Data: result}
+	}
+	return nil
+}
+
+// simulates tells whether the transaction carrying data is run through Simulate
+func (b behavior) simulates(data []byte) (bool, error) {
+	if b.OfflineSign {
+		return false, nil
+	}
+	switch b.Simulation {
+	case SimulateWithData:
+		return len(data) > 0, nil
+	case SimulateAlways:
+		return true, nil
+	case SimulateNever:
+		return false, nil
+	}
+	return false, errors.Wrap(ErrUnknownSimulationPolicy, string(b.Simulation))
+}
+
+// simulationArgs is the message of a transaction, for Simulate
+func simulationArgs(
+	from ethCommon.Address, to *ethCommon.Address, value *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte,
+) client.CallArgs {
+	gas := hexutil.Uint64(gasLimit)
+	args := client.CallArgs{
+		From:     from.Hex(),
+		Gas:      &gas,
+		GasPrice: (*hexutil.Big)(gasPrice),
+		Value:    (*hexutil.Big)(value),
+		Data:     data,
+	}
+	if to != nil {
+		args.To = to.Hex()
+	}
+	return args
+}
+
+// simulationError records a reverted simulation as a transaction error
+func simulationError(err error) Errors {
+	if !errors.Is(err, ErrReverted) {
+		return nil
+	}
+	errorMsg := "simulation failed : " + err.Error()
+	return Errors{&Error{
+		ErrMessage:           &errorMsg,
+		TimestampOfRejection: time.Now().Unix(),
+	}}
+}
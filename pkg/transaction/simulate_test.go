@@ -0,0 +1,92 @@
+package transaction
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	ethCommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/harmony-one/go-sdk/pkg/address"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/go-sdk/pkg/rpc/client"
+	"github.com/harmony-one/harmony/numeric"
+)
+
+// revertPayload is the data of a contract reverting with Error(reason)
+func revertPayload(reason string) []byte {
+	word := func(n int) []byte { return ethCommon.LeftPadBytes(big.NewInt(int64(n)).Bytes(), 32) }
+	data := append(append(append([]byte{}, errorSelector...), word(32)...), word(len(reason))...)
+	return append(data, ethCommon.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+}
+
+// revertingNode answers the calls to contract with the data of a revert, as harmony
+// nodes do, passing everything else to the node
+type revertingNode struct {
+	rpc.T
+	contract string
+	calls    int
+}
+
+func (n *revertingNode) SendRPC(ctx context.Context, meth string, params []interface{}) (rpc.Reply, error) {
+	if meth != rpc.Method.Call {
+		return n.T.SendRPC(ctx, meth, params)
+	}
+	n.calls++
+	if params[0].(client.CallArgs).To == n.contract {
+		return rpc.Reply{"result": hexutil.Encode(revertPayload("not the owner"))}, nil
+	}
+	return rpc.Reply{"result": "0x"}, nil
+}
+
+func TestDecodeRevertReason(t *testing.T) {
+	if reason, ok := DecodeRevertReason(revertPayload("not the owner")); !ok || reason != "not the owner" {
+		t.Errorf("expected the reason of the revert decoded, got %q", reason)
+	}
+	panicked := append(append([]byte{}, panicSelector...), ethCommon.LeftPadBytes([]byte{0x11}, 32)...)
+	if reason, ok := DecodeRevertReason(panicked); !ok || reason != "panic code 0x11" {
+		t.Errorf("expected the code of the panic decoded, got %q", reason)
+	}
+	for _, data := range [][]byte{nil, ethCommon.LeftPadBytes([]byte{1}, 32), revertPayload("cut")[:40]} {
+		if _, ok := DecodeRevertReason(data); ok {
+			t.Errorf("expected %x not to decode as a revert", data)
+		}
+	}
+}
+
+func TestSimulation(t *testing.T) {
+	ctx := context.Background()
+	_, messenger, ks, accts := newFundedNetwork(t, []int64{10, 0})
+	from, contract := accts[0], accts[1]
+	node := &revertingNode{T: messenger, contract: contract.Address.Hex()}
+	receiver := address.ToBech32(contract.Address)
+
+	send := func(nonce uint64, data []byte, policy SimulationPolicy) (*Controller, error) {
+		controller := NewController(node, ks, &from, common.Chain.TestNet, func(c *Controller) {
+			c.Behavior.Simulation = policy
+		})
+		err := controller.ExecuteTransaction(ctx, nonce, 50000, &receiver, 0, 0, numeric.NewDec(1), numeric.NewDec(1), data)
+		return controller, err
+	}
+
+	controller, err := send(0, []byte{1, 2, 3, 4}, SimulateWithData)
+	if !errors.Is(err, ErrReverted) || !strings.Contains(err.Error(), "not the owner") {
+		t.Errorf("expected the contract call to be aborted with its revert reason, got %v", err)
+	}
+	if controller.TransactionHash() != nil || len(controller.TransactionErrors()) != 1 || node.calls != 1 {
+		t.Errorf("expected the reverting transaction not to be sent")
+	}
+
+	if _, err := send(0, nil, SimulateWithData); err != nil || node.calls != 1 {
+		t.Errorf("expected the transfer to be sent without a simulation, got %v after %d calls", err, node.calls)
+	}
+	if _, err := send(1, []byte{1, 2, 3, 4}, SimulateNever); err != nil || node.calls != 1 {
+		t.Errorf("expected the contract call to be sent without a simulation, got %v after %d calls", err, node.calls)
+	}
+	if _, err := send(2, nil, "sometimes"); !errors.Is(err, ErrUnknownSimulationPolicy) {
+		t.Errorf("expected an unknown policy to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package transaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/go-sdk/pkg/rpc"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/accounts/keystore"
+	"github.com/harmony-one/harmony/core"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+type stakingTransactionForRPC struct {
+	transaction *staking.StakingTransaction
+	// Hex encoded
+	signature       *string
+	transactionHash *string
+	receipt         rpc.Reply
+}
+
+// StakingController drives the signing and sending of the staking transactions, the
+// counterpart of Controller for the five staking directives
+type StakingController struct {
+	executionError           error
+	transactionErrors        Errors
+	messenger                rpc.T
+	sender                   sender
+	stakingTransactionForRPC stakingTransactionForRPC
+	chain                    common.ChainID
+	Behavior                 behavior
+}
+
+// NewStakingController initializes a StakingController, caller can control behavior via options
+func NewStakingController(
+	handler rpc.T, senderKs *keystore.KeyStore,
+	senderAcct *accounts.Account, chain common.ChainID,
+	options ...func(*StakingController),
+) *StakingController {
+	ctrlr := &StakingController{
+		messenger: handler,
+		sender: sender{
+			ks:      senderKs,
+			account: senderAcct,
+		},
+		chain:    chain,
+		Behavior: behavior{false, false, Software, 0, NewGasEstimator(), nil, SimulateWithData, nil},
+	}
+	for _, option := range options {
+		option(ctrlr)
+	}
+	ctrlr.sender.fromSigner(ctrlr.Behavior)
+	return ctrlr
+}
+
+// TransactionToJSON dumps JSON representation, the message being decoded
+func (C *StakingController) TransactionToJSON(pretty bool) string {
+	tx := C.stakingTransactionForRPC.transaction
+	r, _ := json.Marshal(map[string]interface{}{
+		"type":     tx.StakingType().String(),
+		"nonce":    tx.Nonce(),
+		"gasLimit": tx.GasLimit(),
+		"gasPrice": tx.GasPrice(),
+		"msg":      tx.StakingMessage(),
+		"hash":     tx.Hash().Hex(),
+	})
+	if pretty {
+		return common.JSONPrettyFormat(string(r))
+	}
+	return string(r)
+}
+
+// RawTransaction dumps the signature as string
+func (C *StakingController) RawTransaction() string {
+	if C.stakingTransactionForRPC.signature == nil {
+		return ""
+	}
+	return *C.stakingTransactionForRPC.signature
+}
+
+// TransactionInfo is the staking transaction, signed once executed
+func (C *StakingController) TransactionInfo() *staking.StakingTransaction {
+	return C.stakingTransactionForRPC.transaction.Copy()
+}
+
+// TransactionHash - the tx hash
+func (C *StakingController) TransactionHash() *string {
+	return C.stakingTransactionForRPC.transactionHash
+}
+
+// Receipt - the tx receipt
+func (C *StakingController) Receipt() rpc.Reply {
+	return C.stakingTransactionForRPC.receipt
+}
+
+// TransactionErrors - tx errors
+func (C *StakingController) TransactionErrors() Errors {
+	return C.transactionErrors
+}
+
+// setNewStakingTransaction builds the transaction of the directive f makes. A gas limit
+// left at zero is the intrinsic gas of the message, a gas price left at zero the price
+// of Behavior.GasEstimator, the given price being in nano.
+func (C *StakingController) setNewStakingTransaction(
+	ctx context.Context, nonce, gasLimit uint64, gasPrice numeric.Dec, f staking.StakeMsgFulfiller,
+) {
+	if C.executionError != nil {
+		return
+	}
+	if gasPrice.IsNil() || gasPrice.Sign() == -1 {
+		C.executionError = ErrBadTransactionParam
+		errorMsg := fmt.Sprintf("can't set negative gas price: %d", gasPrice)
+		C.transactionErrors = append(C.transactionErrors, &Error{
+			ErrMessage:           &errorMsg,
+			TimestampOfRejection: time.Now().Unix(),
+		})
+		return
+	}
+	directive, payload := f()
+	if gasLimit == 0 {
+		data, err := rlp.EncodeToBytes(payload)
+		if err != nil {
+			C.executionError = err
+			return
+		}
+		isCreateValidator := directive == staking.DirectiveCreateValidator
+		if gasLimit, C.executionError = core.IntrinsicGas(data, false, true, true, isCreateValidator); C.executionError != nil {
+			return
+		}
+	}
+	price := gasPrice.Mul(nanoAsDec)
+	if price.IsZero() && C.Behavior.GasEstimator != nil {
+		if C.Behavior.OfflineSign {
+			price = C.Behavior.GasEstimator.MinGasPrice
+		} else if price, C.executionError = C.Behavior.GasEstimator.GasPrice(ctx, C.messenger); C.executionError != nil {
+			return
+		}
+	}
+	C.stakingTransactionForRPC.transaction, C.executionError = staking.NewStakingTransaction(
+		nonce, gasLimit, price.TruncateInt(), f,
+	)
+}
+
+func (C *StakingController) signAndPrepareTxEncodedForSending(ctx context.Context) {
+	if C.executionError != nil {
+		return
+	}
+	signed, err := C.Behavior.signer(C.sender).SignStakingTx(
+		ctx, C.stakingTransactionForRPC.transaction, C.chain.Value,
+	)
+	if err != nil {
+		C.executionError = err
+		C.transactionErrors = append(C.transactionErrors, signingErrors(err)...)
+		return
+	}
+	C.setSigned(signed)
+}
+
+func (C *StakingController) setSigned(signed *staking.StakingTransaction) {
+	enc, err := rlp.EncodeToBytes(signed)
+	if err != nil {
+		C.executionError = err
+		return
+	}
+	hexSignature := hexutil.Encode(enc)
+	C.stakingTransactionForRPC.transaction = signed
+	C.stakingTransactionForRPC.signature = &hexSignature
+}
+
+func (C *StakingController) sendSignedTx(ctx context.Context) {
+	if C.executionError != nil || C.Behavior.DryRun {
+		return
+	}
+	reply, err := C.messenger.SendRPC(
+		ctx, rpc.Method.SendRawStakingTransaction, p{C.stakingTransactionForRPC.signature},
+	)
+	if err != nil {
+		C.executionError = err
+		return
+	}
+	r, _ := reply["result"].(string)
+	C.stakingTransactionForRPC.transactionHash = &r
+}
+
+func (C *StakingController) txConfirmation(ctx context.Context) {
+	if C.executionError != nil || C.Behavior.DryRun {
+		return
+	}
+	if C.Behavior.ConfirmationWaitTime > 0 {
+		txHash := *C.TransactionHash()
+		start := int(C.Behavior.ConfirmationWaitTime)
+		for {
+			r, _ := C.messenger.SendRPC(ctx, rpc.Method.GetTransactionReceipt, p{txHash})
+			if r["result"] != nil {
+				C.stakingTransactionForRPC.receipt = r
+				return
+			}
+			transactionErrors, err := GetError(ctx, txHash, C.messenger)
+			if err != nil {
+				errMsg := err.Error()
+				C.transactionErrors = append(C.transactionErrors, &Error{
+					TxHashID:             &txHash,
+					ErrMessage:           &errMsg,
+					TimestampOfRejection: time.Now().Unix(),
+				})
+			}
+			C.transactionErrors = append(C.transactionErrors, transactionErrors...)
+			if len(transactionErrors) > 0 {
+				C.executionError = fmt.Errorf("error found for staking transaction hash: %s", txHash)
+				return
+			}
+			if start < 0 {
+				C.executionError = fmt.Errorf("could not confirm staking transaction after %d seconds", C.Behavior.ConfirmationWaitTime)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				C.executionError = ctx.Err()
+				return
+			case <-time.After(time.Second):
+			}
+			start--
+		}
+	}
+}
+
+// ExecuteStakingTransaction is the single entrypoint to execute a staking transaction,
+// the directive and its message being made by f. Each step becomes a no-op if
+// executionError occurred in any previous step.
+func (C *StakingController) ExecuteStakingTransaction(
+	ctx context.Context,
+	nonce, gasLimit uint64,
+	gasPrice numeric.Dec,
+	f staking.StakeMsgFulfiller,
+) error {
+	// WARNING Order of execution matters
+	C.setNewStakingTransaction(ctx, nonce, gasLimit, gasPrice, f)
+	C.signAndPrepareTxEncodedForSending(ctx)
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
+	return C.executionError
+}
+
+// SignStakingTransaction builds and signs a staking transaction without sending it
+func (C *StakingController) SignStakingTransaction(
+	ctx context.Context,
+	nonce, gasLimit uint64,
+	gasPrice numeric.Dec,
+	f staking.StakeMsgFulfiller,
+) error {
+	C.setNewStakingTransaction(ctx, nonce, gasLimit, gasPrice, f)
+	C.signAndPrepareTxEncodedForSending(ctx)
+	return C.executionError
+}
+
+// BuildStakingTransaction builds the staking transaction without signing it, see Envelope
+func (C *StakingController) BuildStakingTransaction(
+	ctx context.Context,
+	nonce, gasLimit uint64,
+	gasPrice numeric.Dec,
+	f staking.StakeMsgFulfiller,
+) error {
+	C.setNewStakingTransaction(ctx, nonce, gasLimit, gasPrice, f)
+	return C.executionError
+}
+
+// Envelope wraps the staking transaction built for it to be signed elsewhere, staking
+// transactions going to the beacon shard
+func (C *StakingController) Envelope() (*Envelope, error) {
+	tx := C.stakingTransactionForRPC.transaction
+	return newEnvelope(EnvelopeStaking, C.chain, C.sender.account.Address, tx.ShardID(), tx)
+}
+
+// ExecuteRawStakingTransaction sends a staking transaction signed beforehand
+func (C *StakingController) ExecuteRawStakingTransaction(ctx context.Context, txn string) error {
+	C.stakingTransactionForRPC.signature = &txn
+
+	C.sendSignedTx(ctx)
+	C.txConfirmation(ctx)
+	return C.executionError
+}
@@ -0,0 +1,93 @@
+package transaction
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/harmony-one/go-sdk/pkg/common"
+	"github.com/harmony-one/harmony/accounts"
+	"github.com/harmony-one/harmony/numeric"
+	staking "github.com/harmony-one/harmony/staking/types"
+)
+
+func TestStakingController(t *testing.T) {
+	ctx := context.Background()
+	_, messenger, ks, accts := newFundedNetwork(t, []int64{100, 100})
+	validator, delegator := accts[0], accts[1]
+	ones := func(n int64) *big.Int { return oneAsDec.Mul(numeric.NewDec(n)).TruncateInt() }
+	execute := func(acct accounts.Account, nonce uint64, f staking.StakeMsgFulfiller, options ...func(*StakingController)) (*StakingController, error) {
+		options = append(options, func(c *StakingController) { c.Behavior.ConfirmationWaitTime = 5 })
+		controller := NewStakingController(messenger, ks, &acct, common.Chain.TestNet, options...)
+		return controller, controller.ExecuteStakingTransaction(ctx, nonce, 0, numeric.ZeroDec(), f)
+	}
+
+	rate := numeric.NewDecWithPrec(1, 1)
+	_, err := execute(validator, 0, func() (staking.Directive, interface{}) {
+		return staking.DirectiveCreateValidator, staking.CreateValidator{
+			ValidatorAddress:   validator.Address,
+			CommissionRates:    staking.CommissionRates{Rate: rate, MaxRate: rate, MaxChangeRate: rate},
+			MinSelfDelegation:  ones(10),
+			MaxTotalDelegation: ones(1000),
+			Amount:             ones(10),
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	delegate := func() (staking.Directive, interface{}) {
+		return staking.DirectiveDelegate, staking.Delegate{
+			DelegatorAddress: delegator.Address, ValidatorAddress: validator.Address, Amount: ones(20),
+		}
+	}
+
+	dry, err := execute(delegator, 0, delegate, func(c *StakingController) { c.Behavior.DryRun = true })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dry.TransactionHash() != nil || dry.RawTransaction() == "" {
+		t.Error("expected a dry run to sign without sending")
+	}
+	if price := dry.TransactionInfo().GasPrice(); price.Cmp(DefaultMinGasPrice.TruncateInt()) != 0 {
+		t.Errorf("expected the estimated gas price, got %s", price)
+	}
+
+	sent, err := execute(delegator, 0, delegate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent.Receipt()["result"] == nil || *sent.TransactionHash() != dry.TransactionInfo().Hash().Hex() {
+		t.Errorf("expected the delegation signed in the dry run to be confirmed, got %v", sent.Receipt())
+	}
+
+	failed, err := execute(delegator, 1, func() (staking.Directive, interface{}) {
+		return staking.DirectiveUndelegate, staking.Undelegate{
+			DelegatorAddress: delegator.Address, ValidatorAddress: validator.Address, Amount: ones(30),
+		}
+	})
+	if err == nil || len(failed.TransactionErrors()) != 1 {
+		t.Errorf("expected the undelegation of more than delegated to fail, got %v %v", err, failed.TransactionErrors())
+	}
+
+	// Signed offline with no node, the transaction pays the minimum gas price
+	offline := NewStakingController(nil, ks, &validator, common.Chain.TestNet, func(c *StakingController) {
+		c.Behavior.OfflineSign, c.Behavior.DryRun = true, true
+	})
+	selfDelegate := func() (staking.Directive, interface{}) {
+		return staking.DirectiveDelegate, staking.Delegate{
+			DelegatorAddress: validator.Address, ValidatorAddress: validator.Address, Amount: ones(5),
+		}
+	}
+	if err := offline.ExecuteStakingTransaction(ctx, 1, 0, numeric.ZeroDec(), selfDelegate); err != nil {
+		t.Fatal(err)
+	}
+	if price := offline.TransactionInfo().GasPrice(); price.Cmp(DefaultMinGasPrice.TruncateInt()) != 0 {
+		t.Errorf("expected the minimum gas price offline, got %s", price)
+	}
+	online := NewStakingController(messenger, nil, nil, common.Chain.TestNet, func(c *StakingController) {
+		c.Behavior.ConfirmationWaitTime = 5
+	})
+	if err := online.ExecuteRawStakingTransaction(ctx, offline.RawTransaction()); err != nil || online.Receipt()["result"] == nil {
+		t.Errorf("expected the staking transaction signed offline to be confirmed, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package transaction
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/harmony-one/go-sdk/pkg/address"
@@ -29,9 +30,9 @@ func NewEthTransaction(
 }
 
 // GetNextNonce returns the nonce on-chain (finalized transactions)
-func GetNextNonce(addr string, messenger rpc.T) uint64 {
+func GetNextNonce(ctx context.Context, addr string, messenger rpc.T) uint64 {
 	transactionCountRPCReply, err :=
-		messenger.SendRPC(rpc.Method.GetTransactionCount, []interface{}{address.Parse(addr), "latest"})
+		messenger.SendRPC(ctx, rpc.Method.GetTransactionCount, []interface{}{address.Parse(addr), "latest"})
 
 	if err != nil {
 		return 0
@@ -43,9 +44,9 @@ func GetNextNonce(addr string, messenger rpc.T) uint64 {
 }
 
 // GetNextPendingNonce returns the nonce from the tx-pool (un-finalized transactions)
-func GetNextPendingNonce(addr string, messenger rpc.T) uint64 {
+func GetNextPendingNonce(ctx context.Context, addr string, messenger rpc.T) uint64 {
 	transactionCountRPCReply, err :=
-		messenger.SendRPC(rpc.Method.GetTransactionCount, []interface{}{address.Parse(addr), "pending"})
+		messenger.SendRPC(ctx, rpc.Method.GetTransactionCount, []interface{}{address.Parse(addr), "pending"})
 
 	if err != nil {
 		return 0
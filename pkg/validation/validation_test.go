@@ -1,6 +1,7 @@
 package validation
 
 import (
+	"context"
 	"testing"
 
 	"github.com/harmony-one/go-sdk/pkg/sharding"
@@ -37,7 +38,7 @@ func TestIsValidShard(t *testing.T) {
 	if err := ValidateNodeConnection("http://localhost:9500"); err != nil {
 		t.Skip()
 	}
-	s, _ := sharding.Structure("http://localhost:9500")
+	s, _ := sharding.Structure(context.Background(), "http://localhost:9500")
 
 	tests := []struct {
 		shardID uint32